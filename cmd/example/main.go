@@ -2,16 +2,29 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
 	"log"
 
-	admin "github.com/abserari/shower/pkgs/userAuth/controller"
 	permission "github.com/abserari/shower/pkgs/permission/controller/gin"
+	permissionmysql "github.com/abserari/shower/pkgs/permission/model/mysql"
 	pet "github.com/abserari/shower/pkgs/pet/controller/gin"
+	petmysql "github.com/abserari/shower/pkgs/pet/model/mysql"
 	smservice "github.com/abserari/shower/pkgs/smservice/controller/gin"
+	smservicemysql "github.com/abserari/shower/pkgs/smservice/model/mysql"
 	service "github.com/abserari/shower/pkgs/smservice/service"
 	upload "github.com/abserari/shower/pkgs/upload/controller/gin"
+	uploadmysql "github.com/abserari/shower/pkgs/upload/model/mysql"
+	admin "github.com/abserari/shower/pkgs/userAuth/controller"
+	adminmysql "github.com/abserari/shower/pkgs/userAuth/model/mysql"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
 	"github.com/abserari/shower/utils/fileserver"
+	"github.com/abserari/shower/utils/mail"
+	"github.com/abserari/shower/utils/migrate"
+	"github.com/abserari/shower/utils/oidc"
+	"github.com/abserari/shower/utils/salt"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
@@ -22,46 +35,103 @@ type funcv struct{}
 func (v funcv) OnVerifySucceed(targetID, mobile string) {}
 func (v funcv) OnVerifyFailed(targetID, mobile string)  {}
 
+type migrator interface {
+	Name() string
+	Migrations() []migrate.Migration
+}
+
+func up(mi *migrate.Migrator, m migrator) {
+	if err := mi.Up(m.Name(), m.Migrations()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+var loader = config.NewLoader(flag.CommandLine)
+
 func main() {
 	var v funcv
 
+	flag.Parse()
+
+	conf, err := loader.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("[Config] :", conf)
+
 	router := gin.Default()
+	router.Use(errs.Handler())
+	router.NoRoute(errs.NoRoute)
 
-	dbConn, err := sql.Open("mysql", "root:123456@tcp(localhost:3306)/project?parseTime=true")
+	dbConn, err := sql.Open(conf.Database.Driver, conf.Database.DSN)
 	if err != nil {
 		panic(err)
 	}
 
+	mi := migrate.New(dbConn)
+
 	con := &service.Config{
-		Host:           "https://fesms.market.alicloudapi.com/sms/",
-		Appcode:        "6f37345cad574f408bff3ede627f7014",
-		Digits:         6,
-		ResendInterval: 60,
+		Host:           conf.SMS.Host,
+		Appcode:        conf.SMS.Appcode,
+		Digits:         conf.SMS.Digits,
+		ResendInterval: conf.SMS.ResendInterval,
+		Expire:         conf.SMS.Expire,
 		OnCheck:        v,
 	}
-	smserviceCon := smservice.New(dbConn, con)
+	smserviceCon := smservice.New(smservicemysql.NewRepository(dbConn), con)
+	up(mi, smserviceCon)
 	smserviceCon.RegisterRouter(router.Group("/api/v1/message"))
 
-	adminCon := admin.New(dbConn)
+	hasher, err := salt.New(conf.Password)
+	if err != nil {
+		log.Fatal(err)
+	}
+	adminCon := admin.New(adminmysql.NewRepository(dbConn, hasher), conf.JWT, conf.Login, conf.Password)
+	mailer, err := mail.New(conf.Mail)
+	if err != nil {
+		log.Fatal(err)
+	}
+	adminCon.UseMailer(mailer, conf.Mail)
+	adminCon.UseAPIKeys(conf.APIKey)
+	adminCon.UseBootstrap(conf.Bootstrap)
+	if conf.OIDC.Issuer != "" {
+		adminCon.UseOIDC(oidc.New(conf.OIDC), conf.OIDC)
+	}
+	up(mi, adminCon)
+	if err = adminCon.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 	// login and refresh token.
-	router.POST("/api/v1/userAuth/login", adminCon.JWT.LoginHandler)
-	router.GET("/api/v1/userAuth/refresh_token", adminCon.JWT.RefreshHandler)
+	adminCon.RegisterPublicRouter(router.Group("/api/v1/userAuth"))
 	// start to add token on every API after userAuth.RegisterRouter
 	router.Use(adminCon.JWT.MiddlewareFunc())
 	// start to check the userAuth active every time.
 	router.Use(adminCon.CheckActive())
 	adminCon.RegisterRouter(router.Group("/api/v1/userAuth"))
 
-	permissionCon := permission.New(dbConn, adminCon.GetID)
+	permissionCon := permission.New(permissionmysql.NewRepository(dbConn), adminCon.GetID)
+	up(mi, permissionCon)
+	// the roles are granted in the token, checked without the database.
+	adminCon.UseRoles(permissionCon)
+	if err = permissionCon.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 	router.Use(permissionCon.CheckPermission())
 	permissionCon.RegisterRouter(router.Group("/api/v1/permission"))
 
-	petCon := pet.New(dbConn, "pet")
+	petCon := pet.New(petmysql.NewRepository(dbConn, "pet"))
+	up(mi, petCon)
 	petCon.RegisterRouter(router.Group("/api/v1/pet"))
 
-	uploadCon := upload.New(dbConn, "0.0.0.0:9573", adminCon.GetID)
+	uploadCon := upload.New(uploadmysql.NewRepository(dbConn), conf.FileServer.Address, adminCon.GetID)
+	up(mi, uploadCon)
+	if err = uploadCon.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 	uploadCon.RegisterRouter(router.Group("/api/v1/userAuth"))
 
-	go fileserver.StartFileServer("0.0.0.0:9573", "")
-	log.Fatal(router.Run(":8000"))
+	go func() {
+		log.Fatal(fileserver.StartFileServer(conf.FileServer.Address, conf.FileServer.Path))
+	}()
+	log.Fatal(router.Run(conf.Server.Address))
 }
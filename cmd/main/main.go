@@ -2,66 +2,107 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"log"
+	"net/http"
+	"os"
 
-	admin "github.com/abserari/shower/pkgs/userAuth/controller"
-	permission "github.com/abserari/shower/pkgs/permission/controller/gin"
-	pet "github.com/abserari/shower/pkgs/pet/controller/gin"
-	smservice "github.com/abserari/shower/pkgs/smservice/controller/gin"
-	service "github.com/abserari/shower/pkgs/smservice/service"
-	upload "github.com/abserari/shower/pkgs/upload/controller/gin"
+	"github.com/abserari/shower/pkgs/module"
+	_ "github.com/abserari/shower/pkgs/module/all"
+	"github.com/abserari/shower/utils/config"
 	"github.com/abserari/shower/utils/fileserver"
+	"github.com/abserari/shower/utils/migrate"
+	"github.com/abserari/shower/utils/server"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
 )
 
-type funcv struct{}
+var (
+	errMigrateMemory = errors.New("migrate: nothing to migrate, the database driver is memory")
 
-func (v funcv) OnVerifySucceed(targetID, mobile string) {}
-func (v funcv) OnVerifyFailed(targetID, mobile string)  {}
+	loader      = config.NewLoader(flag.CommandLine)
+	autoMigrate = flag.Bool("migrate", true, "apply the pending migrations before serving")
+)
 
 func main() {
-	var v funcv
+	flag.Parse()
 
-	router := gin.Default()
+	conf, err := loader.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("[Config] :", conf)
+
+	// the modules keep everything in memory without a database.
+	var dbConn *sql.DB
+	if conf.Database.Driver != config.MemoryDriver {
+		dbConn, err = sql.Open(conf.Database.Driver, conf.Database.DSN)
+		if err != nil {
+			panic(err)
+		}
+	}
+	closeDB := func() error {
+		if dbConn == nil {
+			return nil
+		}
+		return dbConn.Close()
+	}
 
-	dbConn, err := sql.Open("mysql", "root:123456@tcp(localhost:3306)/project?parseTime=true")
+	modules, err := module.NewRegistry(conf, dbConn)
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
+	}
+
+	mi := migrate.New(dbConn)
+	if flag.Arg(0) == "migrate" {
+		if dbConn == nil {
+			log.Fatal(errMigrateMemory)
+		}
+		if err = modules.MigrateCommand(mi, flag.Args()[1:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *autoMigrate && dbConn != nil {
+		if err = modules.Migrate(mi); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	router := gin.Default()
+	if err = modules.Setup(router); err != nil {
+		log.Fatal(err)
+	}
+
+	rt := server.New()
+	rt.Serve("API Server", &http.Server{
+		Addr:    conf.Server.Address,
+		Handler: router,
+	})
+	if conf.FileServer.Address != "" {
+		rt.Serve("File Server", fileserver.New(conf.FileServer.Address, conf.FileServer.Path))
+	}
+	// stop the background jobs before closing the database they use.
+	rt.OnShutdown("Modules", modules.Shutdown)
+	rt.OnShutdown("Database", func(ctx context.Context) error {
+		return closeDB()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err = modules.Start(ctx); err != nil {
+		modules.Shutdown(ctx)
+		closeDB()
+		log.Fatal(err)
 	}
 
-	con := &service.Config{
-		Host:           "https://fesms.market.alicloudapi.com/sms/",
-		Appcode:        "6f37345cad574f408bff3ede627f7014",
-		Digits:         6,
-		ResendInterval: 60,
-		OnCheck:        v,
+	if err = rt.Run(ctx); err != nil {
+		log.Fatal(err)
 	}
-	smserviceCon := smservice.New(dbConn, con)
-	smserviceCon.RegisterRouter(router.Group("/api/v1/message"))
-
-	adminCon := admin.New(dbConn)
-	// login and refresh token.
-	router.POST("/api/v1/userAuth/login", adminCon.JWT.LoginHandler)
-	router.GET("/api/v1/userAuth/refresh_token", adminCon.JWT.RefreshHandler)
-	// start to add token on every API after userAuth.RegisterRouter
-	router.Use(adminCon.JWT.MiddlewareFunc())
-	// start to check the userAuth active every time.
-	router.Use(adminCon.CheckActive())
-	adminCon.RegisterRouter(router.Group("/api/v1/userAuth"))
-
-	permissionCon := permission.New(dbConn, adminCon.GetID)
-	router.Use(permissionCon.CheckPermission())
-	permissionCon.RegisterRouter(router.Group("/api/v1/permission"))
-
-	petCon := pet.New(dbConn, "pet")
-	petCon.RegisterRouter(router.Group("/api/v1/pet"))
-
-	uploadCon := upload.New(dbConn, "0.0.0.0:9573", adminCon.GetID)
-	uploadCon.RegisterRouter(router.Group("/api/v1/userAuth"))
-
-	go fileserver.StartFileServer("0.0.0.0:9573", "")
-	log.Fatal(router.Run(":8000"))
 }
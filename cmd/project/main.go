@@ -2,13 +2,23 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
 	"log"
 
 	permission "github.com/abserari/shower/pkgs/permission/controller/gin"
+	permissionmysql "github.com/abserari/shower/pkgs/permission/model/mysql"
 	upload "github.com/abserari/shower/pkgs/upload/controller/gin"
+	uploadmysql "github.com/abserari/shower/pkgs/upload/model/mysql"
 	admin "github.com/abserari/shower/pkgs/userAuth/controller"
+	adminmysql "github.com/abserari/shower/pkgs/userAuth/model/mysql"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
 	"github.com/abserari/shower/utils/fileserver"
+	"github.com/abserari/shower/utils/mail"
+	"github.com/abserari/shower/utils/migrate"
+	"github.com/abserari/shower/utils/salt"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
@@ -16,35 +26,75 @@ import (
 
 // config
 const (
-	uploadAddressBase        = "0.0.0.0:9573"
-	serverAddressBase        = ":8000"
-	mysqlConnAddress         = "root:123456@tcp(localhost:3306)/project?parseTime=true"
-
  	userAuthRouterGroup = "/api/v1/userAuth"
-	userAuthRouterGroupLogin = userAuthRouterGroup + "/login"
-	userAuthRouterRefreshToken = userAuthRouterGroup +"/refresh_token"
  	permissionRouterGroup = "/api/v1/permission"
  	uploadRouterGroup = "/api/v1/upload"
 )
 
+var loader = config.NewLoader(flag.CommandLine)
+
+type migrator interface {
+	Name() string
+	Migrations() []migrate.Migration
+}
+
 func main() {
+	flag.Parse()
+
+	conf, err := loader.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("[Config] :", conf)
+
 	router := gin.Default()
+	router.Use(errs.Handler())
+	router.NoRoute(errs.NoRoute)
 
-	dbConn, err := sql.Open("mysql", mysqlConnAddress)
+	dbConn, err := sql.Open(conf.Database.Driver, conf.Database.DSN)
 	if err != nil {
 		panic(err)
 	}
 
 	// init controller with db conn
-	adminCon := admin.New(dbConn)
-	permissionCon := permission.New(dbConn, adminCon.GetID)
-	uploadCon := upload.New(dbConn, uploadAddressBase, adminCon.GetID)
+	hasher, err := salt.New(conf.Password)
+	if err != nil {
+		log.Fatal(err)
+	}
+	adminCon := admin.New(adminmysql.NewRepository(dbConn, hasher), conf.JWT, conf.Login, conf.Password)
+	mailer, err := mail.New(conf.Mail)
+	if err != nil {
+		log.Fatal(err)
+	}
+	adminCon.UseMailer(mailer, conf.Mail)
+	adminCon.UseBootstrap(conf.Bootstrap)
+	permissionCon := permission.New(permissionmysql.NewRepository(dbConn), adminCon.GetID)
+	// the roles are granted in the token, checked without the database.
+	adminCon.UseRoles(permissionCon)
+	uploadCon := upload.New(uploadmysql.NewRepository(dbConn), conf.FileServer.Address, adminCon.GetID)
+
+	// create tables and files directories
+	mi := migrate.New(dbConn)
+	for _, m := range []migrator{adminCon, permissionCon, uploadCon} {
+		if err = mi.Up(m.Name(), m.Migrations()); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// seed the admin after the tables created.
+	if err = adminCon.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+	if err = permissionCon.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+	if err = uploadCon.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 
 	// register router and MiddlewareFunc
 
-	// login and refresh token.
-	router.POST(userAuthRouterGroupLogin, adminCon.JWT.LoginHandler)
-	router.GET(userAuthRouterRefreshToken, adminCon.JWT.RefreshHandler)
+	// login, refresh token and the password changed at the first login.
+	adminCon.RegisterPublicRouter(router.Group(userAuthRouterGroup))
 
 	// start to add token on every API after userAuth.RegisterRouter
 	router.Use(adminCon.JWT.MiddlewareFunc())
@@ -58,6 +108,8 @@ func main() {
 	uploadCon.RegisterRouter(router.Group(uploadRouterGroup))
 
 	// start the fileServer services
-	go fileserver.StartFileServer(uploadAddressBase, "")
-	log.Fatal(router.Run(serverAddressBase))
+	go func() {
+		log.Fatal(fileserver.StartFileServer(conf.FileServer.Address, conf.FileServer.Path))
+	}()
+	log.Fatal(router.Run(conf.Server.Address))
 }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/abserari/shower/utils/salt"
+)
+
+// the longest password the login accepts.
+const maxPassword = 30
+
+var (
+	errNameRequired   = errors.New("showerctl: -name is required")
+	errPasswordLength = fmt.Errorf("showerctl: the password should be at most %d characters", maxPassword)
+)
+
+// admin creates, activates or deactivates an admin, or resets the password.
+func (c *ctl) admin(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	fs := flag.NewFlagSet("admin "+args[0], flag.ContinueOnError)
+	name := fs.String("name", "", "name of the admin")
+	password := fs.String("password", "", "password of the admin, generated if empty")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return errNameRequired
+	}
+
+	switch args[0] {
+	case "create":
+		pwd, err := c.passwordOrRandom(*password)
+		if err != nil {
+			return err
+		}
+
+		if err = c.admins.CreateAdmin(name, &pwd); err != nil {
+			return err
+		}
+
+		a, err := c.admins.AdminByName(*name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("admin %s created, id %d\n", a.Name, a.ID)
+		return c.generated(a.ID, *password, pwd)
+	case "activate", "deactivate":
+		a, err := c.admins.AdminByName(*name)
+		if err != nil {
+			return err
+		}
+
+		if err = c.admins.ModifyAdminActive(a.ID, args[0] == "activate"); err != nil {
+			return err
+		}
+		fmt.Printf("admin %s %sd\n", a.Name, args[0])
+		return nil
+	case "password":
+		pwd, err := c.passwordOrRandom(*password)
+		if err != nil {
+			return err
+		}
+
+		a, err := c.admins.AdminByName(*name)
+		if err != nil {
+			return err
+		}
+
+		if err = c.admins.ResetPassword(a.ID, &pwd); err != nil {
+			return err
+		}
+		fmt.Printf("password of admin %s reset\n", a.Name)
+		return c.generated(a.ID, *password, pwd)
+	}
+
+	return errUsage
+}
+
+// generated prints pwd if it's generated instead of password, and flags the
+// admin to change it at the first login, since it's shown in the terminal.
+func (c *ctl) generated(id uint32, password, pwd string) error {
+	if password != "" {
+		return nil
+	}
+
+	if err := c.admins.SetMustChangePassword(id, true); err != nil {
+		return err
+	}
+	fmt.Printf("password: %s\n", pwd)
+	fmt.Println("it must be changed at the first login")
+	return nil
+}
+
+// passwordOrRandom checks password by the policy of the configuration, or
+// generates one if it's empty.
+func (c *ctl) passwordOrRandom(password string) (string, error) {
+	policy, err := salt.NewPolicy(c.conf.Password)
+	if err != nil {
+		return "", err
+	}
+
+	if password == "" {
+		return policy.Generate()
+	}
+
+	if len(password) > maxPassword {
+		return "", errPasswordLength
+	}
+	if err = policy.Check(password); err != nil {
+		return "", err
+	}
+	return password, nil
+}
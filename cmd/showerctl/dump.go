@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	permission "github.com/abserari/shower/pkgs/permission/model"
+	admin "github.com/abserari/shower/pkgs/userAuth/model"
+)
+
+// state is everything about who could access what.
+type state struct {
+	Admins      []*admin.Admin             `json:"admins"`
+	Roles       []*permission.Role         `json:"roles"`
+	Permissions []*permission.Permission   `json:"permissions"`
+	Relations   []*permission.RelationData `json:"relations"`
+}
+
+// dump prints the admins, roles, URL permissions and the roles of the admins.
+func (c *ctl) dump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print in JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, err := c.state()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ADMIN\tNAME\tMOBILE\tEMAIL\tACTIVE\tCREATED")
+	for _, a := range s.Admins {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\t%s\n", a.ID, a.Name, a.Mobile, a.Email, a.Active, a.CreatedAt)
+	}
+
+	fmt.Fprintln(w, "\nROLE\tNAME\tINTRO\tACTIVE\tCREATED")
+	for _, r := range s.Roles {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\n", r.RoleID, r.Name, r.Intro, r.Active, r.CreateAt)
+	}
+
+	fmt.Fprintln(w, "\nROLE\tURL\tCREATED")
+	for _, p := range s.Permissions {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", p.RoleID, p.URL, p.CreatedAt)
+	}
+
+	fmt.Fprintln(w, "\nADMIN\tROLE")
+	for _, r := range s.Relations {
+		fmt.Fprintf(w, "%d\t%d\n", r.AdminID, r.RoleID)
+	}
+
+	return w.Flush()
+}
+
+func (c *ctl) state() (*state, error) {
+	var (
+		s   state
+		err error
+	)
+
+	if s.Admins, err = c.admins.Admins(); err != nil {
+		return nil, err
+	}
+	if s.Roles, err = c.permission.RoleList(); err != nil {
+		return nil, err
+	}
+
+	permissions, err := c.permission.Permissions()
+	if err != nil {
+		return nil, err
+	}
+	if permissions != nil {
+		s.Permissions = *permissions
+	}
+
+	for _, a := range s.Admins {
+		relations, err := c.permission.AssociatedRoleList(a.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.Relations = append(s.Relations, relations...)
+	}
+
+	return &s, nil
+}
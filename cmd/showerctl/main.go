@@ -0,0 +1,116 @@
+// showerctl manages the admins, roles and permissions in the database
+// directly, to bootstrap or recover a deployment without the API.
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/abserari/shower/pkgs/module"
+	_ "github.com/abserari/shower/pkgs/module/all"
+	permission "github.com/abserari/shower/pkgs/permission/model/mysql"
+	admin "github.com/abserari/shower/pkgs/userAuth/model/mysql"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/migrate"
+	"github.com/abserari/shower/utils/salt"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+const usage = `usage: showerctl [-config file] command [arguments]
+
+commands:
+  admin create -name name [-password password]
+  admin activate|deactivate -name name
+  admin password -name name [-password password]
+  role create -name name [-intro intro]
+  role activate|deactivate -role id
+  role grant|revoke -role id -url url
+  role assign|unassign -role id -admin name
+  migrate up|down|status [-module name] [-steps n] [-dry-run]
+  dump [-json]
+
+A random password is generated and printed if -password is empty.
+`
+
+var (
+	errUsage  = errors.New(usage)
+	errMemory = errors.New("showerctl: the database driver is memory, there's nothing to manage")
+
+	loader = config.NewLoader(flag.CommandLine)
+)
+
+// ctl holds the repositories the commands work on.
+type ctl struct {
+	db         *sql.DB
+	conf       *config.Config
+	admins     *admin.Repository
+	permission *permission.Repository
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage, "\nflags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	conf, err := loader.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if conf.Database.Driver == config.MemoryDriver {
+		log.Fatal(errMemory)
+	}
+
+	db, err := sql.Open(conf.Database.Driver, conf.Database.DSN)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	hasher, err := salt.New(conf.Password)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	c := &ctl{
+		db:         db,
+		conf:       conf,
+		admins:     admin.NewRepository(db, hasher),
+		permission: permission.NewRepository(db),
+	}
+
+	if err = c.run(flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func (c *ctl) run(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "admin":
+		return c.admin(args[1:])
+	case "role":
+		return c.role(args[1:])
+	case "migrate":
+		modules, err := module.NewRegistry(c.conf, c.db)
+		if err != nil {
+			return err
+		}
+		return modules.MigrateCommand(migrate.New(c.db), args[1:], os.Stdout)
+	case "dump":
+		return c.dump(args[1:])
+	}
+
+	return errUsage
+}
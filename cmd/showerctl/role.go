@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+var (
+	errRoleRequired  = errors.New("showerctl: -role is required")
+	errURLRequired   = errors.New("showerctl: -url is required")
+	errAdminRequired = errors.New("showerctl: -admin is required")
+)
+
+// role creates a role, activates or deactivates it, grants or revokes an
+// URL, and assigns it to or removes it from an admin.
+func (c *ctl) role(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	fs := flag.NewFlagSet("role "+args[0], flag.ContinueOnError)
+	name := fs.String("name", "", "name of the new role")
+	intro := fs.String("intro", "", "introduction of the new role")
+	rid := fs.Uint("role", 0, "ID of the role")
+	url := fs.String("url", "", "URL the role could access, like /api/v1/permission/addrole")
+	adminName := fs.String("admin", "", "name of the admin")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if args[0] == "create" {
+		if *name == "" {
+			return errNameRequired
+		}
+		if err := c.permission.CreateRole(name, intro); err != nil {
+			return err
+		}
+
+		// CreateRole doesn't return the ID, the latest role of the name is the new one.
+		roles, err := c.permission.RoleList()
+		if err != nil {
+			return err
+		}
+		var id uint32
+		for _, r := range roles {
+			if r.Name == *name && r.RoleID > id {
+				id = r.RoleID
+			}
+		}
+		fmt.Printf("role %s created, id %d\n", *name, id)
+		return nil
+	}
+
+	if *rid == 0 {
+		return errRoleRequired
+	}
+	id := uint32(*rid)
+
+	switch args[0] {
+	case "activate", "deactivate":
+		if err := c.permission.ModifyRoleActive(id, args[0] == "activate"); err != nil {
+			return err
+		}
+		fmt.Printf("role %d %sd\n", id, args[0])
+		return nil
+	case "grant", "revoke":
+		if *url == "" {
+			return errURLRequired
+		}
+
+		var err error
+		if args[0] == "grant" {
+			err = c.permission.AddURLPermission(id, *url)
+		} else {
+			err = c.permission.RemoveURLPermission(id, *url)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %s for role %d\n", args[0], *url, id)
+		return nil
+	case "assign", "unassign":
+		if *adminName == "" {
+			return errAdminRequired
+		}
+		a, err := c.admins.AdminByName(*adminName)
+		if err != nil {
+			return err
+		}
+
+		if args[0] == "assign" {
+			err = c.permission.AddRelation(a.ID, id)
+		} else {
+			err = c.permission.RemoveRelation(a.ID, id)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s role %d for admin %s\n", args[0], id, a.Name)
+		return nil
+	}
+
+	return errUsage
+}
@@ -3,9 +3,11 @@ module github.com/abserari/shower
 go 1.14
 
 require (
-	github.com/appleboy/gin-jwt/v2 v2.6.4
+	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/gin-gonic/gin v1.6.3
+	github.com/go-playground/validator/v10 v10.2.0
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/sfreiberg/gotwilio v0.0.0-20200916182813-169c4cd5c691
 	golang.org/x/crypto v0.0.0-20190701094942-4def268fd1a4
+	gopkg.in/yaml.v2 v2.2.8
 )
@@ -6,140 +6,146 @@
 package controller
 
 import (
-	"database/sql"
 	"log"
 	"net/http"
 	"time"
 
-	mysql "github.com/abserari/shower/pkgs/banner/model/mysql"
+	"github.com/abserari/shower/pkgs/banner/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 	"github.com/gin-gonic/gin"
 )
 
 // BannerController -
 type BannerController struct {
-	db        *sql.DB
-	tableName string
+	repo model.Repository
 }
 
-// New -
-func New(db *sql.DB, tableName string) *BannerController {
+// New creates a controller of repo.
+func New(repo model.Repository) *BannerController {
 	return &BannerController{
-		db:        db,
-		tableName: tableName,
+		repo: repo,
 	}
 }
 
+// Migrations returns the migrations of the repository.
+func (b *BannerController) Migrations() []migrate.Migration {
+	return migrate.Of(b.repo)
+}
+
 // RegisterRouter -
 func (b *BannerController) RegisterRouter(r gin.IRouter) {
 	if r == nil {
 		log.Fatal("[InitRouter]: server is nil")
 	}
 
-	err := mysql.CreateTable(b.db, b.tableName)
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	r.POST("/create", b.create)
 	r.POST("/delete", b.deleteByID)
 	r.POST("/info/id", b.infoByID)
 	r.POST("/list/date", b.lisitValidBannerByUnixDate)
 }
 
+type createRequest struct {
+	Name      string    `json:"name"      binding:"required"`
+	ImagePath string    `json:"imageurl"  binding:"required"`
+	EventPath string    `json:"eventurl"  binding:"required"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type createResponse struct {
+	Status int `json:"status"`
+	ID     int `json:"ID"`
+}
+
 func (b *BannerController) create(c *gin.Context) {
-	var (
-		req struct {
-			Name      string    `json:"name"      binding:"required"`
-			ImagePath string    `json:"imageurl"  binding:"required"`
-			EventPath string    `json:"eventurl"  binding:"required"`
-			StartDate time.Time `json:"start_date"`
-			EndDate   time.Time `json:"end_date"`
-		}
-	)
+	var req createRequest
 
 	err := c.ShouldBind(&req)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	id, err := mysql.InsertBanner(b.db, b.tableName, req.Name, req.ImagePath, req.EventPath, req.StartDate, req.EndDate)
+	id, err := b.repo.Insert(req.Name, req.ImagePath, req.EventPath, req.StartDate, req.EndDate)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "ID": id})
+	c.JSON(http.StatusOK, createResponse{Status: http.StatusOK, ID: id})
+}
+
+type lisitValidBannerByUnixDateRequest struct {
+	Unixtime int64 `json:"unixtime"    binding:"required"`
+}
+
+type lisitValidBannerByUnixDateResponse struct {
+	Status  int             `json:"status"`
+	Banners []*model.Banner `json:"banners"`
 }
 
 func (b *BannerController) lisitValidBannerByUnixDate(c *gin.Context) {
-	var (
-		req struct {
-			Unixtime int64 `json:"unixtime"    binding:"required"`
-		}
-	)
+	var req lisitValidBannerByUnixDateRequest
 
 	err := c.ShouldBind(&req)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	banners, err := mysql.LisitValidBannerByUnixDate(b.db, b.tableName, req.Unixtime)
+	banners, err := b.repo.LisitValidBannerByUnixDate(req.Unixtime)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "banners": banners})
+	c.JSON(http.StatusOK, lisitValidBannerByUnixDateResponse{Status: http.StatusOK, Banners: banners})
+}
+
+type infoByIDRequest struct {
+	ID int `json:"id"     binding:"required"`
+}
+
+type infoByIDResponse struct {
+	Status int           `json:"status"`
+	Ban    *model.Banner `json:"ban"`
 }
 
 func (b *BannerController) infoByID(c *gin.Context) {
-	var (
-		req struct {
-			ID int `json:"id"     binding:"required"`
-		}
-	)
+	var req infoByIDRequest
 
 	err := c.ShouldBind(&req)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	ban, err := mysql.InfoByID(b.db, b.tableName, req.ID)
+	ban, err := b.repo.InfoByID(req.ID)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "ban": ban})
+	c.JSON(http.StatusOK, infoByIDResponse{Status: http.StatusOK, Ban: ban})
+}
+
+type deleteByIDRequest struct {
+	ID int `json:"id"    binding:"required"`
 }
 
 func (b *BannerController) deleteByID(c *gin.Context) {
-	var (
-		req struct {
-			ID int `json:"id"    binding:"required"`
-		}
-	)
+	var req deleteByIDRequest
 
 	err := c.ShouldBind(&req)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.DeleteByID(b.db, b.tableName, req.ID)
+	err = b.repo.DeleteByID(req.ID)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
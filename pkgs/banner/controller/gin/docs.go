@@ -0,0 +1,17 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/abserari/shower/utils/openapi"
+)
+
+// Operations describes the routes of the module in the OpenAPI document.
+func (b *BannerController) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/create", Summary: "Create a banner", Request: createRequest{}, Response: createResponse{}},
+		{Method: http.MethodPost, Path: "/delete", Summary: "Delete a banner", Request: deleteByIDRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/info/id", Summary: "Get a banner", Request: infoByIDRequest{}, Response: infoByIDResponse{}},
+		{Method: http.MethodPost, Path: "/list/date", Summary: "List the banners valid at the date", Request: lisitValidBannerByUnixDateRequest{}, Response: lisitValidBannerByUnixDateResponse{}},
+	}
+}
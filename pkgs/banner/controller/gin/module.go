@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/abserari/shower/pkgs/banner/model/memory"
+	mysql "github.com/abserari/shower/pkgs/banner/model/mysql"
+	"github.com/abserari/shower/pkgs/module"
+)
+
+// ModuleName is the name of banner in config.
+const ModuleName = "banner"
+
+func init() {
+	module.Register(ModuleName, func(env *module.Env) (module.Module, error) {
+		if env.Memory() {
+			return New(memory.NewRepository()), nil
+		}
+		return New(mysql.NewRepository(env.DB, env.Module.Table)), nil
+	})
+}
+
+// Name returns the name of the module.
+func (b *BannerController) Name() string { return ModuleName }
+
+// Dependencies is empty, the module works alone.
+func (b *BannerController) Dependencies() []string { return nil }
+
+// Start does nothing, there is no background work.
+func (b *BannerController) Start(ctx context.Context) error { return nil }
+
+// Shutdown has nothing to stop.
+func (b *BannerController) Shutdown(ctx context.Context) error { return nil }
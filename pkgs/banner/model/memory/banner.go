@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abserari/shower/pkgs/banner/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+var (
+	errDuplicateName = errs.Conflict("banner already exists")
+)
+
+type banner struct {
+	id        int
+	name      string
+	imagePath string
+	eventPath string
+	startDate time.Time
+	endDate   time.Time
+}
+
+func (b *banner) model() *model.Banner {
+	return &model.Banner{
+		BannerID:  b.id,
+		Name:      b.name,
+		ImagePath: b.imagePath,
+		EventPath: b.eventPath,
+		StartDate: b.startDate.Format(time.RFC3339Nano),
+		EndDate:   b.endDate.Format(time.RFC3339Nano),
+	}
+}
+
+// Repository stores the banners in memory, it's used in development and tests.
+type Repository struct {
+	mu      sync.RWMutex
+	nextID  int
+	banners map[int]*banner
+}
+
+// NewRepository creates an empty Repository.
+func NewRepository() *Repository {
+	return &Repository{
+		nextID:  1000000,
+		banners: make(map[int]*banner),
+	}
+}
+
+// Insert return id
+func (r *Repository) Insert(name string, imagePath string, eventPath string, startDate time.Time, endDate time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, b := range r.banners {
+		if b.name == name {
+			return 0, errDuplicateName
+		}
+	}
+
+	id := r.nextID
+	r.nextID++
+
+	r.banners[id] = &banner{
+		id:        id,
+		name:      name,
+		imagePath: imagePath,
+		eventPath: eventPath,
+		startDate: startDate,
+		endDate:   endDate,
+	}
+
+	return id, nil
+}
+
+// LisitValidBannerByUnixDate return banner list which have valid date
+func (r *Repository) LisitValidBannerByUnixDate(unixtime int64) ([]*model.Banner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var bans []*model.Banner
+	for _, b := range r.banners {
+		if b.startDate.Unix() <= unixtime && b.endDate.Unix() >= unixtime {
+			bans = append(bans, b.model())
+		}
+	}
+
+	sort.Slice(bans, func(i, j int) bool { return bans[i].BannerID < bans[j].BannerID })
+	return bans, nil
+}
+
+// InfoByID returns ErrNotFound if there's no such banner.
+func (r *Repository) InfoByID(id int) (*model.Banner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b, ok := r.banners[id]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+
+	return b.model(), nil
+}
+
+// DeleteByID delete by id
+func (r *Repository) DeleteByID(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.banners[id]; !ok {
+		return model.ErrNotFound
+	}
+
+	delete(r.banners, id)
+	return nil
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/abserari/shower/utils/errs"
+)
+
+// ErrNotFound is returned if there's no such banner.
+var ErrNotFound = errs.NotFound("banner not found")
+
+// Banner -
+type Banner struct {
+	BannerID  int
+	Name      string
+	ImagePath string
+	EventPath string
+	StartDate string
+	EndDate   string
+}
+
+// Repository stores the banners.
+type Repository interface {
+	// Insert return id
+	Insert(name string, imagePath string, eventPath string, startDate time.Time, endDate time.Time) (int, error)
+	// LisitValidBannerByUnixDate return banner list which have valid date
+	LisitValidBannerByUnixDate(unixtime int64) ([]*Banner, error)
+	InfoByID(id int) (*Banner, error)
+	DeleteByID(id int) error
+}
@@ -10,17 +10,14 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/abserari/shower/pkgs/banner/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 )
 
-// Banner -
-type Banner struct {
-	BannerID  int
-	Name      string
-	ImagePath string
-	EventPath string
-	StartDate string
-	EndDate   string
-}
+// Banner is the banner of the model.
+type Banner = model.Banner
 
 const (
 	mysqlBannerCreateTable = iota
@@ -28,6 +25,7 @@ const (
 	mysqlBannerLisitValidBanner
 	mysqlBannerInfoByID
 	mysqlBannerDeleteByID
+	mysqlBannerDropTable
 )
 
 var (
@@ -47,9 +45,22 @@ var (
 		`SELECT * FROM %s WHERE unix_timestamp(startDate) <= ? AND unix_timestamp(endDate) >= ? LOCK IN SHARE MODE`,
 		`SELECT * FROM %s WHERE bannerid = ? LIMIT 1 LOCK IN SHARE MODE`,
 		`DELETE FROM %s WHERE bannerid = ? LIMIT 1`,
+		`DROP TABLE IF EXISTS %s`,
 	}
 )
 
+// Migrations returns the migrations creating the tables.
+func Migrations(tableName string) []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version:     1,
+			Description: "create banner table",
+			Up:          []string{fmt.Sprintf(bannerSQLString[mysqlBannerCreateTable], tableName)},
+			Down:        []string{fmt.Sprintf(bannerSQLString[mysqlBannerDropTable], tableName)},
+		},
+	}
+}
+
 // CreateTable -
 func CreateTable(db *sql.DB, tableName string) error {
 	sql := fmt.Sprintf(bannerSQLString[mysqlBannerCreateTable], tableName)
@@ -62,7 +73,7 @@ func InsertBanner(db *sql.DB, tableName string, name string, imagePath string, e
 	sql := fmt.Sprintf(bannerSQLString[mysqlBannerInsert], tableName)
 	result, err := db.Exec(sql, name, imagePath, eventPath, startDate, endDate)
 	if err != nil {
-		return 0, err
+		return 0, errs.FromDB(err, "banner")
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
@@ -128,10 +139,15 @@ func InfoByID(db *sql.DB, tableName string, id int) (*Banner, error) {
 	}
 	defer rows.Close()
 
-	for rows.Next() {
-		if err := rows.Scan(&ban.BannerID, &ban.Name, &ban.ImagePath, &ban.EventPath, &ban.StartDate, &ban.EndDate); err != nil {
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
 			return nil, err
 		}
+		return nil, model.ErrNotFound
+	}
+
+	if err := rows.Scan(&ban.BannerID, &ban.Name, &ban.ImagePath, &ban.EventPath, &ban.StartDate, &ban.EndDate); err != nil {
+		return nil, err
 	}
 
 	return &ban, nil
@@ -140,6 +156,14 @@ func InfoByID(db *sql.DB, tableName string, id int) (*Banner, error) {
 // DeleteByID delete by id
 func DeleteByID(db *sql.DB, tableName string, id int) error {
 	sql := fmt.Sprintf(bannerSQLString[mysqlBannerDeleteByID], tableName)
-	_, err := db.Exec(sql, id)
-	return err
+	result, err := db.Exec(sql, id)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
 }
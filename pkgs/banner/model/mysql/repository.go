@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/abserari/shower/utils/migrate"
+)
+
+// Repository stores the banners in a MySQL table.
+type Repository struct {
+	db        *sql.DB
+	tableName string
+}
+
+// NewRepository creates a Repository of the table tableName in db.
+func NewRepository(db *sql.DB, tableName string) *Repository {
+	return &Repository{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+// Migrations returns the migrations of the tables.
+func (r *Repository) Migrations() []migrate.Migration {
+	return Migrations(r.tableName)
+}
+
+// Insert creates a banner shown from startDate to endDate, and returns its ID.
+func (r *Repository) Insert(name string, imagePath string, eventPath string, startDate time.Time, endDate time.Time) (int, error) {
+	return InsertBanner(r.db, r.tableName, name, imagePath, eventPath, startDate, endDate)
+}
+
+// LisitValidBannerByUnixDate lists the banners shown at unixtime.
+func (r *Repository) LisitValidBannerByUnixDate(unixtime int64) ([]*Banner, error) {
+	return LisitValidBannerByUnixDate(r.db, r.tableName, unixtime)
+}
+
+// InfoByID returns ErrNotFound if there's no such banner.
+func (r *Repository) InfoByID(id int) (*Banner, error) {
+	return InfoByID(r.db, r.tableName, id)
+}
+
+// DeleteByID removes the banner of id.
+func (r *Repository) DeleteByID(id int) error {
+	return DeleteByID(r.db, r.tableName, id)
+}
@@ -1,19 +1,18 @@
 package category
 
 import (
-	"database/sql"
 	"log"
 	"net/http"
 
-	"github.com/abserari/shower/pkgs/category/model/mysql"
+	"github.com/abserari/shower/pkgs/category/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 	"github.com/gin-gonic/gin"
 )
 
 // Controller -
 type Controller struct {
-	db        *sql.DB
-	tableName string
-	dbName    string
+	repo model.Repository
 }
 
 // Config -
@@ -22,135 +21,122 @@ type Config struct {
 	CategoryTable string
 }
 
-// Register -
-func Register(db *sql.DB, tableName string, dbName string, r gin.IRouter) error {
-	c := New(db, tableName, dbName)
+// New creates a controller of repo.
+func New(repo model.Repository) *Controller {
+	return &Controller{
+		repo: repo,
+	}
+}
 
-	if err := c.CreateTable(); err != nil {
-		log.Fatal(err)
-		return err
+// RegisterRouter registers the routes of the module on r.
+func (con *Controller) RegisterRouter(r gin.IRouter) {
+	if r == nil {
+		log.Fatal("[InitRouter]: server is nil")
 	}
 
-	r.POST("/api/v1/category/create", c.Insert)
-	r.POST("/api/v1/category/modify/status", c.ChangeCategoryStatus)
-	r.POST("/api/v1/category/modify/name", c.ChangeCategoryName)
-	r.POST("/api/v1/category/children", c.LisitChirldrenByParentID)
-	return nil
+	r.POST("/create", con.Insert)
+	r.POST("/modify/status", con.ChangeCategoryStatus)
+	r.POST("/modify/name", con.ChangeCategoryName)
+	r.POST("/children", con.LisitChirldrenByParentID)
 }
 
-// New -
-func New(db *sql.DB, tableName string, dbName string) *Controller {
-	return &Controller{
-		db:        db,
-		tableName: tableName,
-		dbName:    dbName,
-	}
+// Migrations returns the migrations of the repository.
+func (con *Controller) Migrations() []migrate.Migration {
+	return migrate.Of(con.repo)
 }
 
-// CreateTable -
-func (con *Controller) CreateTable() error {
-	err := mysql.CreateTable(con.db, con.tableName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	return err
+type insertRequest struct {
+	ParentID uint   `json:"parentId"`
+	Name     string `json:"name"`
 }
 
 // Insert -
 func (con *Controller) Insert(c *gin.Context) {
-	var (
-		req struct {
-			ParentID uint   `json:"parentId"`
-			Name     string `json:"name"`
-		}
-	)
+	var req insertRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	_, err := mysql.InsertCategory(con.db, con.tableName, req.ParentID, req.Name)
+	_, err := con.repo.Insert(req.ParentID, req.Name)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 	return
 }
 
+type changeCategoryStatusRequest struct {
+	CategoryID uint `json:"categoryId"`
+	Status     int8 `json:"status"`
+}
+
 // ChangeCategoryStatus -
 func (con *Controller) ChangeCategoryStatus(c *gin.Context) {
-	var (
-		req struct {
-			CategoryID uint `json:"categoryId"`
-			Status     int8 `json:"status"`
-		}
-	)
+	var req changeCategoryStatusRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	err := mysql.ChangeCategoryStatus(con.db, con.tableName, req.CategoryID, req.Status)
+	err := con.repo.ChangeCategoryStatus(req.CategoryID, req.Status)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 	return
 }
 
+type changeCategoryNameRequest struct {
+	CategoryID uint   `json:"categoryId"`
+	Name       string `json:"name"`
+}
+
 // ChangeCategoryName -
 func (con *Controller) ChangeCategoryName(c *gin.Context) {
-	var (
-		req struct {
-			CategoryID uint   `json:"categoryId"`
-			Name       string `json:"name"`
-		}
-	)
+	var req changeCategoryNameRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	err := mysql.ChangeCategoryName(con.db, con.tableName, req.CategoryID, req.Name)
+	err := con.repo.ChangeCategoryName(req.CategoryID, req.Name)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 	return
 }
 
+type lisitChirldrenByParentIDRequest struct {
+	ParentID uint `json:"parentId"`
+}
+
+type lisitChirldrenByParentIDResponse struct {
+	Status int               `json:"status"`
+	List   []*model.Category `json:"list"`
+}
+
 // LisitChirldrenByParentID -
 func (con *Controller) LisitChirldrenByParentID(c *gin.Context) {
-	var (
-		req struct {
-			ParentID uint `json:"parentId"`
-		}
-	)
+	var req lisitChirldrenByParentIDRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	list, err := mysql.LisitChirldrenByParentID(con.db, con.tableName, req.ParentID)
+	list, err := con.repo.LisitChirldrenByParentID(req.ParentID)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "list": list})
+	c.JSON(http.StatusOK, lisitChirldrenByParentIDResponse{Status: http.StatusOK, List: list})
 	return
 }
@@ -0,0 +1,17 @@
+package category
+
+import (
+	"net/http"
+
+	"github.com/abserari/shower/utils/openapi"
+)
+
+// Operations describes the routes of the module in the OpenAPI document.
+func (con *Controller) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/create", Summary: "Create a category", Request: insertRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/modify/status", Summary: "Modify the status of a category", Request: changeCategoryStatusRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/modify/name", Summary: "Rename a category", Request: changeCategoryNameRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/children", Summary: "List the children of a category", Request: lisitChirldrenByParentIDRequest{}, Response: lisitChirldrenByParentIDResponse{}},
+	}
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abserari/shower/pkgs/category/model"
+)
+
+// Repository stores the categories in memory, it's used in development and tests.
+type Repository struct {
+	mu         sync.RWMutex
+	nextID     uint
+	categories map[uint]*model.Category
+}
+
+// NewRepository creates an empty Repository.
+func NewRepository() *Repository {
+	return &Repository{
+		nextID:     10000,
+		categories: make(map[uint]*model.Category),
+	}
+}
+
+// Insert 自动设定 id 和 status状态和 创建时间
+func (r *Repository) Insert(parentID uint, name string) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+
+	r.categories[id] = &model.Category{
+		CategoryID: id,
+		ParentID:   parentID,
+		Name:       name,
+		Status:     1,
+		CreateTime: time.Now(),
+	}
+
+	return id, nil
+}
+
+// ChangeCategoryStatus 改变目录状态
+func (r *Repository) ChangeCategoryStatus(category uint, status int8) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.categories[category]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	c.Status = status
+	return nil
+}
+
+// ChangeCategoryName 改变目录名称
+func (r *Repository) ChangeCategoryName(category uint, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.categories[category]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	c.Name = name
+	return nil
+}
+
+// LisitChirldrenByParentID lists the categories directly under parentID.
+func (r *Repository) LisitChirldrenByParentID(parentID uint) ([]*model.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var categorys []*model.Category
+	for _, c := range r.categories {
+		if c.ParentID == parentID {
+			category := *c
+			categorys = append(categorys, &category)
+		}
+	}
+
+	sort.Slice(categorys, func(i, j int) bool { return categorys[i].CategoryID < categorys[j].CategoryID })
+	return categorys, nil
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/abserari/shower/utils/errs"
+)
+
+// ErrNotFound is returned if there's no such category.
+var ErrNotFound = errs.NotFound("category not found")
+
+// Category -
+type Category struct {
+	CategoryID uint
+	ParentID   uint //为0则是根目录
+	Name       string
+	Status     int8
+	CreateTime time.Time
+}
+
+// Repository stores the categories.
+type Repository interface {
+	// Insert 自动设定 id 和 status状态和 创建时间
+	Insert(parentID uint, name string) (uint, error)
+	ChangeCategoryStatus(category uint, status int8) error
+	ChangeCategoryName(category uint, name string) error
+	LisitChirldrenByParentID(parentID uint) ([]*Category, error)
+}
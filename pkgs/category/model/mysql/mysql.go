@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/abserari/shower/pkgs/category/model"
+	"github.com/abserari/shower/utils/migrate"
 )
 
 const (
@@ -13,11 +16,12 @@ const (
 	mysqlUpdateStatus
 	mysqlUpdateName
 	mysqlSelectByParentID
+	mysqlDropTable
 )
 
 var (
 	errInvaildInsert         = errors.New("insert comment: insert affected 0 rows")
-	errInvalidChangeCategory = errors.New("change status: affected 0 rows")
+	errInvalidChangeCategory = model.ErrNotFound
 	categorySQLFormatStr     = []string{
 		`CREATE TABLE IF NOT EXISTS %s(
 			categoryId INT(11) NOT NULL AUTO_INCREMENT COMMENT '类别id',
@@ -32,16 +36,23 @@ var (
 		`UPDATE %s SET status = ? WHERE categoryId = ? LIMIT 1`,
 		`UPDATE %s SET name = ? WHERE categoryId = ? LIMIT 1`,
 		`SELECT * FROM %s WHERE parentId = ?`,
+		`DROP TABLE IF EXISTS %s`,
 	}
 )
 
-// Category -
-type Category struct {
-	CategoryID uint
-	ParentID   uint //为0则是根目录
-	Name       string
-	Status     int8
-	CreateTime time.Time
+// Category is the category of the model.
+type Category = model.Category
+
+// Migrations returns the migrations creating the tables.
+func Migrations(tableName string) []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version:     1,
+			Description: "create category table",
+			Up:          []string{fmt.Sprintf(categorySQLFormatStr[mysqlCreateTable], tableName)},
+			Down:        []string{fmt.Sprintf(categorySQLFormatStr[mysqlDropTable], tableName)},
+		},
+	}
 }
 
 // CreateTable -
@@ -0,0 +1,46 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/abserari/shower/utils/migrate"
+)
+
+// Repository stores the categories in a MySQL table.
+type Repository struct {
+	db        *sql.DB
+	tableName string
+}
+
+// NewRepository creates a Repository of the table tableName in db.
+func NewRepository(db *sql.DB, tableName string) *Repository {
+	return &Repository{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+// Migrations returns the migrations of the tables.
+func (r *Repository) Migrations() []migrate.Migration {
+	return Migrations(r.tableName)
+}
+
+// Insert creates an active category under parentID, and returns its ID.
+func (r *Repository) Insert(parentID uint, name string) (uint, error) {
+	return InsertCategory(r.db, r.tableName, parentID, name)
+}
+
+// ChangeCategoryStatus modifies the status of the category.
+func (r *Repository) ChangeCategoryStatus(category uint, status int8) error {
+	return ChangeCategoryStatus(r.db, r.tableName, category, status)
+}
+
+// ChangeCategoryName modifies the name of the category.
+func (r *Repository) ChangeCategoryName(category uint, name string) error {
+	return ChangeCategoryName(r.db, r.tableName, category, name)
+}
+
+// LisitChirldrenByParentID lists the categories directly under parentID.
+func (r *Repository) LisitChirldrenByParentID(parentID uint) ([]*Category, error) {
+	return LisitChirldrenByParentID(r.db, r.tableName, parentID)
+}
@@ -1,74 +1,57 @@
 package Department
 
 import (
-	"database/sql"
 	"log"
 	"net/http"
 
-	mysql "github.com/abserari/shower/pkgs/department/model"
+	"github.com/abserari/shower/pkgs/department/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Controller -
 type Controller struct {
-	db        *sql.DB
-	tableName string
-	dbName    string
+	repo model.Repository
 }
 
-// Register -
-func Register(db *sql.DB, tableName string, dbName string, r gin.IRouter) error {
-	c := New(db, tableName, dbName)
-
-	if err := c.CreateTable(); err != nil {
-		log.Fatal(err)
-		return err
+// New creates a controller of repo.
+func New(repo model.Repository) *Controller {
+	return &Controller{
+		repo: repo,
 	}
-
-	r.POST("/department/create", c.InsertDepartment)
-	r.POST("/department-member/create", c.InsertDepartmentMember)
-	return nil
 }
 
-// New -
-func New(db *sql.DB, tableName string, dbName string) *Controller {
-	return &Controller{
-		db:        db,
-		tableName: tableName,
-		dbName:    dbName,
+// RegisterRouter registers the routes of the module on r.
+func (con *Controller) RegisterRouter(r gin.IRouter) {
+	if r == nil {
+		log.Fatal("[InitRouter]: server is nil")
 	}
+
+	r.POST("/create", con.InsertDepartment)
+	r.POST("/member/create", con.InsertDepartmentMember)
 }
 
-// CreateTable -
-func (con *Controller) CreateTable() error {
-	err := mysql.CreateDepartmentTable(con.db, con.dbName, con.tableName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	err = mysql.CreateDepartmentMemberTable(con.db, con.dbName, con.tableName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	return err
+// Migrations returns the migrations of the repository.
+func (con *Controller) Migrations() []migrate.Migration {
+	return migrate.Of(con.repo)
 }
 
 // Insert -
 func (con *Controller) InsertDepartment(c *gin.Context) {
 	var (
-		d *mysql.Department
+		d model.Department
 	)
 
-	if err := c.ShouldBindJSON(d); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+	if err := c.ShouldBindJSON(&d); err != nil {
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	_, err := mysql.InsertDepartment(con.db, con.dbName, con.tableName, d)
+	_, err := con.repo.InsertDepartment(&d)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
@@ -78,20 +61,18 @@ func (con *Controller) InsertDepartment(c *gin.Context) {
 // Insert -
 func (con *Controller) InsertDepartmentMember(c *gin.Context) {
 	var (
-		dm *mysql.DepartmentMember
+		dm model.DepartmentMember
 	)
 
-	if err := c.ShouldBindJSON(dm); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+	if err := c.ShouldBindJSON(&dm); err != nil {
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	_, err := mysql.InsertDepartmentMember(con.db, con.dbName, con.tableName, dm)
+	_, err := con.repo.InsertDepartmentMember(&dm)
 
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
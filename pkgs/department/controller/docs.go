@@ -0,0 +1,16 @@
+package Department
+
+import (
+	"net/http"
+
+	"github.com/abserari/shower/pkgs/department/model"
+	"github.com/abserari/shower/utils/openapi"
+)
+
+// Operations describes the routes of the module in the OpenAPI document.
+func (con *Controller) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/create", Summary: "Create a department", Request: model.Department{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/member/create", Summary: "Add a member to a department", Request: model.DepartmentMember{}, Response: openapi.StatusBody{}},
+	}
+}
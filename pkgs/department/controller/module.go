@@ -0,0 +1,33 @@
+package Department
+
+import (
+	"context"
+
+	"github.com/abserari/shower/pkgs/department/model/memory"
+	"github.com/abserari/shower/pkgs/department/model/mysql"
+	"github.com/abserari/shower/pkgs/module"
+)
+
+// ModuleName is the name of department in config.
+const ModuleName = "department"
+
+func init() {
+	module.Register(ModuleName, func(env *module.Env) (module.Module, error) {
+		if env.Memory() {
+			return New(memory.NewRepository()), nil
+		}
+		return New(mysql.NewRepository(env.DB, env.Module.Database, env.Module.Table, env.Module.Table+"_member")), nil
+	})
+}
+
+// Name returns the name of the module.
+func (con *Controller) Name() string { return ModuleName }
+
+// Dependencies is empty, the module works alone.
+func (con *Controller) Dependencies() []string { return nil }
+
+// Start does nothing, there is no background work.
+func (con *Controller) Start(ctx context.Context) error { return nil }
+
+// Shutdown has nothing to stop.
+func (con *Controller) Shutdown(ctx context.Context) error { return nil }
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/abserari/shower/pkgs/department/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+var (
+	errDuplicateCode       = errs.Conflict("department already exists")
+	errDuplicateMemberCode = errs.Conflict("department member already exists")
+)
+
+// Repository stores the departments in memory, it's used in development and tests.
+type Repository struct {
+	mu           sync.Mutex
+	nextID       int64
+	nextMemberID int64
+	departments  map[string]model.Department
+	members      map[string]model.DepartmentMember
+}
+
+// NewRepository creates an empty Repository.
+func NewRepository() *Repository {
+	return &Repository{
+		nextID:       1000,
+		nextMemberID: 1000,
+		departments:  make(map[string]model.Department),
+		members:      make(map[string]model.DepartmentMember),
+	}
+}
+
+// InsertDepartment creates a department, and returns its ID.
+func (r *Repository) InsertDepartment(d *model.Department) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.departments[d.Code]; ok {
+		return 0, errDuplicateCode
+	}
+
+	department := *d
+	department.Id = r.nextID
+	r.nextID++
+
+	r.departments[d.Code] = department
+	return department.Id, nil
+}
+
+// InsertDepartmentMember adds an account to a department, and returns the ID of the membership.
+func (r *Repository) InsertDepartmentMember(dm *model.DepartmentMember) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[dm.Code]; ok {
+		return 0, errDuplicateMemberCode
+	}
+
+	member := *dm
+	member.Id = r.nextMemberID
+	r.nextMemberID++
+
+	r.members[dm.Code] = member
+	return member.Id, nil
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+// Department is a unit of an organization, under the department of Pcode.
+type Department struct {
+	Id               int64     `json:"id,omitempty"`
+	Code             string    `json:"code,omitempty"`
+	OrganizationCode string    `json:"organization_code,omitempty"`
+	Name             string    `json:"name,omitempty"`
+	Sort             string    `json:"sort,omitempty"`
+	Pcode            string    `json:"pcode,omitempty"`
+	Icon             string    `json:"icon,omitempty"`
+	CreateTime       time.Time `json:"create_time,omitempty"`
+	Path             string    `json:"path,omitempty"`
+}
+
+// DepartmentMember is an account in a department of an organization.
+type DepartmentMember struct {
+	Id               int64     `json:"id,omitempty"`
+	Code             string    `json:"code,omitempty"`
+	DepartmentCode   string    `json:"department_code,omitempty"`
+	OrganizationCode string    `json:"organization_code,omitempty"`
+	AccountCode      string    `json:"account_code,omitempty"`
+	JoinTime         time.Time `json:"join_time,omitempty"`
+	IsPrincipal      int8      `json:"is_principal,omitempty"`
+	IsOwner          int8      `json:"is_owner,omitempty"`
+	Authorize        string    `json:"authorize,omitempty"`
+}
+
+// Repository stores the departments and their members.
+type Repository interface {
+	InsertDepartment(d *Department) (int64, error)
+	InsertDepartmentMember(dm *DepartmentMember) (int64, error)
+}
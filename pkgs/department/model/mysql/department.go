@@ -9,12 +9,16 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"time"
+
+	"github.com/abserari/shower/pkgs/department/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 )
 
 const (
 	mysqlDepartmentCreateTable = iota
 	mysqlDepartmentInsert
+	mysqlDepartmentDropTable
 )
 
 const (
@@ -42,6 +46,7 @@ var (
 			)comment '部门表' ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ;`,
 		// insert one entry
 		`INSERT INTO %s.%s (code,organization_code,name,sort,pcode,icon,create_time,path)  VALUES (?,?,?,?,?,?,?,?)`,
+		`DROP TABLE IF EXISTS %s.%s`,
 	}
 
 	departmentMemberSQLString = []string{
@@ -66,28 +71,28 @@ var (
 	}
 )
 
-type Department struct {
-	Id               int64     `json:"id,omitempty"`
-	Code             string    `json:"code,omitempty"`
-	OrganizationCode string    `json:"organization_code,omitempty"`
-	Name             string    `json:"name,omitempty"`
-	Sort             string    `json:"sort,omitempty"`
-	Pcode            string    `json:"pcode,omitempty"`
-	Icon             string    `json:"icon,omitempty"`
-	CreateTime       time.Time `json:"create_time,omitempty"`
-	Path             string    `json:"path,omitempty"`
-}
-
-type DepartmentMember struct {
-	Id               int64     `json:"id,omitempty"`
-	Code             string    `json:"code,omitempty"`
-	DepartmentCode   string    `json:"department_code,omitempty"`
-	OrganizationCode string    `json:"organization_code,omitempty"`
-	AccountCode      string    `json:"account_code,omitempty"`
-	JoinTime         time.Time `json:"join_time,omitempty"`
-	IsPrincipal      int8      `json:"is_principal,omitempty"`
-	IsOwner          int8      `json:"is_owner,omitempty"`
-	Authorize        string    `json:"authorize,omitempty"`
+// Department is a unit of an organization, under the department of Pcode.
+type Department = model.Department
+
+// DepartmentMember is an account in a department of an organization.
+type DepartmentMember = model.DepartmentMember
+
+// Migrations of department and department member table.
+func Migrations(DBName, TableName, MemberTableName string) []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version:     1,
+			Description: "create department and department member table",
+			Up: []string{
+				fmt.Sprintf(departmentSQLString[mysqlDepartmentCreateTable], DBName, TableName),
+				fmt.Sprintf(departmentMemberSQLString[mysqlDepartmentMemberCreateTable], DBName, MemberTableName),
+			},
+			Down: []string{
+				fmt.Sprintf(departmentSQLString[mysqlDepartmentDropTable], DBName, MemberTableName),
+				fmt.Sprintf(departmentSQLString[mysqlDepartmentDropTable], DBName, TableName),
+			},
+		},
+	}
 }
 
 // CreateTable create
@@ -101,11 +106,11 @@ func InsertDepartment(db *sql.DB, DBName, TableName string, d *Department) (int6
 		d.Code, d.OrganizationCode, d.Name, d.Sort,
 		d.Pcode, d.Icon, d.CreateTime, d.Path)
 	if err != nil {
-		return 0, err
+		return 0, errs.FromDB(err, "department")
 	}
 
-	if rows, err := result.RowsAffected(); rows == 0 {
-		return 0, err
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return 0, errInvalidMysql
 	}
 
 	departmentId, err := result.LastInsertId()
@@ -119,16 +124,8 @@ func InsertDepartment(db *sql.DB, DBName, TableName string, d *Department) (int6
 
 //CreateDepartmentMemberTable create an administrative userAuth
 func CreateDepartmentMemberTable(db *sql.DB, DBName, TableName string) error {
-	result, err := db.Exec(fmt.Sprintf(departmentMemberSQLString[mysqlDepartmentMemberCreateTable], DBName, TableName))
-	if err != nil {
-		return err
-	}
-
-	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidMysql
-	}
-
-	return nil
+	_, err := db.Exec(fmt.Sprintf(departmentMemberSQLString[mysqlDepartmentMemberCreateTable], DBName, TableName))
+	return err
 }
 
 //InsertDepartmentMember create an
@@ -138,11 +135,11 @@ func InsertDepartmentMember(db *sql.DB, DBName, TableName string, dm *Department
 		dm.AccountCode, dm.JoinTime, dm.IsPrincipal, dm.IsOwner,
 		dm.Authorize)
 	if err != nil {
-		return 0, err
+		return 0, errs.FromDB(err, "department member")
 	}
 
-	if rows, err := result.RowsAffected(); rows == 0 {
-		return 0, err
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return 0, errInvalidMysql
 	}
 
 	departmentMemberId, err := result.LastInsertId()
@@ -0,0 +1,40 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/abserari/shower/utils/migrate"
+)
+
+// Repository stores the departments in MySQL, the members are in their own table.
+type Repository struct {
+	db              *sql.DB
+	dbName          string
+	tableName       string
+	memberTableName string
+}
+
+// NewRepository creates a Repository of the table tableName in db.
+func NewRepository(db *sql.DB, dbName, tableName, memberTableName string) *Repository {
+	return &Repository{
+		db:              db,
+		dbName:          dbName,
+		tableName:       tableName,
+		memberTableName: memberTableName,
+	}
+}
+
+// Migrations returns the migrations of the tables.
+func (r *Repository) Migrations() []migrate.Migration {
+	return Migrations(r.dbName, r.tableName, r.memberTableName)
+}
+
+// InsertDepartment creates a department, and returns its ID.
+func (r *Repository) InsertDepartment(d *Department) (int64, error) {
+	return InsertDepartment(r.db, r.dbName, r.tableName, d)
+}
+
+// InsertDepartmentMember adds an account to a department, and returns the ID of the membership.
+func (r *Repository) InsertDepartmentMember(dm *DepartmentMember) (int64, error) {
+	return InsertDepartmentMember(r.db, r.dbName, r.memberTableName, dm)
+}
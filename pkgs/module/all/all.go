@@ -0,0 +1,16 @@
+// Package all registers every module under pkgs.
+// Import it for side effects, and add the new module here.
+package all
+
+import (
+	// modules
+	_ "github.com/abserari/shower/pkgs/banner/controller/gin"
+	_ "github.com/abserari/shower/pkgs/category/controller/gin"
+	_ "github.com/abserari/shower/pkgs/department/controller"
+	_ "github.com/abserari/shower/pkgs/order/controller/gin"
+	_ "github.com/abserari/shower/pkgs/permission/controller/gin"
+	_ "github.com/abserari/shower/pkgs/pet/controller/gin"
+	_ "github.com/abserari/shower/pkgs/smservice/controller/gin"
+	_ "github.com/abserari/shower/pkgs/upload/controller/gin"
+	_ "github.com/abserari/shower/pkgs/userAuth/controller"
+)
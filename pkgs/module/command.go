@@ -0,0 +1,72 @@
+package module
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/abserari/shower/utils/migrate"
+)
+
+var (
+	errMigrateUsage = errors.New("usage: migrate up|down|status [-module name] [-steps n] [-dry-run]")
+)
+
+// MigrateCommand applies, rolls back or lists migrations of the enabled
+// modules by the arguments of the migrate subcommand, the status is written to out.
+func (r *Registry) MigrateCommand(mi *migrate.Migrator, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	name := fs.String("module", "", "only this module, all the enabled modules if empty. Required by down")
+	steps := fs.Int("steps", 1, "how many migrations to roll back")
+	fs.BoolVar(&mi.DryRun, "dry-run", false, "print the statements instead of executing")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	targets := r.Modules()
+	if *name != "" {
+		m, ok := r.Module(*name)
+		if !ok {
+			return fmt.Errorf("module %s is not enabled", *name)
+		}
+		targets = []Module{m}
+	}
+
+	switch args[0] {
+	case "up":
+		for _, m := range targets {
+			if err := mi.Up(m.Name(), m.Migrations()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "down":
+		if *name == "" {
+			return errMigrateUsage
+		}
+		return r.Rollback(mi, *name, *steps)
+	case "status":
+		for _, m := range targets {
+			list, err := mi.Status(m.Name(), m.Migrations())
+			if err != nil {
+				return err
+			}
+
+			for _, s := range list {
+				applied := "pending"
+				if s.Applied {
+					applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(out, "%-12s %4d  %-20s %s\n", m.Name(), s.Version, applied, s.Description)
+			}
+		}
+		return nil
+	}
+
+	return errMigrateUsage
+}
@@ -0,0 +1,163 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/migrate"
+	"github.com/abserari/shower/utils/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Module is a package under pkgs that could be composed into the server.
+type Module interface {
+	// Name is the name used in config.
+	Name() string
+	// Dependencies lists the modules should be ready before this one.
+	Dependencies() []string
+	// Migrations create or update the tables of the module, in ascending version.
+	Migrations() []migrate.Migration
+	// RegisterRouter register the API of the module.
+	RegisterRouter(r gin.IRouter)
+	// Start the background work, it shouldn't block.
+	Start(ctx context.Context) error
+	// Shutdown stop the background work.
+	Shutdown(ctx context.Context) error
+}
+
+// PublicRouter is a Module that has API reachable before any middleware.
+type PublicRouter interface {
+	RegisterPublicRouter(r gin.IRouter)
+}
+
+// MiddlewareProvider is a Module providing named middlewares.
+type MiddlewareProvider interface {
+	Middlewares() map[string]gin.HandlerFunc
+}
+
+// Documented is a Module describing its API, the operations are matched
+// with the registered routes to build the OpenAPI document.
+type Documented interface {
+	Operations() []openapi.Operation
+}
+
+// Identifier is a Module knowing which admin sends the request.
+type Identifier interface {
+	GetID(c *gin.Context) (uint32, error)
+}
+
+// SecondFactor is an Identifier knowing whether the admin passed the second
+// factor of the login, like a TOTP code.
+type SecondFactor interface {
+	SecondFactor(c *gin.Context) bool
+}
+
+// Role is a role of an admin.
+type Role struct {
+	ID   uint32 `json:"id"`
+	Name string `json:"name"`
+}
+
+// Roles is a Module knowing the roles of the admins, like permission.
+type Roles interface {
+	AdminRoles(id uint32) ([]Role, error)
+}
+
+// Grants are the IDs of the roles of an admin at a version of the permissions.
+type Grants struct {
+	Roles   []uint32
+	Version uint64
+}
+
+// Granter is a Roles module granting the roles carried by the token, they
+// are current as long as the version of the permissions is.
+type Granter interface {
+	Grants(id uint32) (*Grants, error)
+}
+
+// RolesUser is an Identifier showing the roles of the admins, the Roles
+// module depending on it sets itself when created.
+type RolesUser interface {
+	UseRoles(r Roles)
+}
+
+// Env is what a Factory could use to create the module.
+type Env struct {
+	DB     *sql.DB
+	Config *config.Config
+	Module *config.Module
+
+	registry *Registry
+}
+
+// Memory reports whether the modules should store in memory, there's no database then.
+func (e *Env) Memory() bool {
+	return e.Config.Database.Driver == config.MemoryDriver
+}
+
+// Dependency returns the module by name, creating it if not yet.
+func (e *Env) Dependency(name string) (Module, error) {
+	return e.registry.build(name)
+}
+
+// Identifier returns the Identifier module by name.
+func (e *Env) Identifier(name string) (Identifier, error) {
+	m, err := e.Dependency(name)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := m.(Identifier)
+	if !ok {
+		return nil, fmt.Errorf("module: %s is not an identifier", name)
+	}
+	return id, nil
+}
+
+// Factory creates a module.
+type Factory func(env *Env) (Module, error)
+
+// Register makes a module available by the provided name.
+// If Register is called twice with the same name or if factory is nil, it panics.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("module: Register factory is nil")
+	}
+	if _, dup := factories[name]; dup {
+		panic("module: Register called twice for module " + name)
+	}
+	factories[name] = factory
+}
+
+// Modules returns a sorted list of the names of the registered modules.
+func Modules() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	list := make([]string, 0, len(factories))
+	for name := range factories {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func factory(name string) (Factory, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	f, ok := factories[name]
+	return f, ok
+}
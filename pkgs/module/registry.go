@@ -0,0 +1,320 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
+	"github.com/abserari/shower/utils/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errUnknownModule = func(name string) error {
+		return fmt.Errorf("module: unknown module %s, forgotten import?", name)
+	}
+	errNotEnabled = func(name, by string) error {
+		return fmt.Errorf("module: %s needs module %s, but it's not enabled", by, name)
+	}
+	errCycle = func(name string) error {
+		return fmt.Errorf("module: dependency cycle on %s", name)
+	}
+	errUnknownMiddleware = func(name string) error {
+		return fmt.Errorf("module: unknown middleware %s", name)
+	}
+	errDuplicateMiddleware = func(name string) error {
+		return fmt.Errorf("module: middleware %s provided twice", name)
+	}
+)
+
+// Registry holds the modules enabled by one config.
+type Registry struct {
+	db   *sql.DB
+	conf *config.Config
+
+	modules  map[string]Module
+	building map[string]bool
+	// modules in dependency order.
+	ordered []Module
+	// routes registered by RegisterRouter.
+	routes []route
+}
+
+// route is a registered route of a module.
+type route struct {
+	module Module
+	public bool
+	gin.RouteInfo
+}
+
+// NewRegistry creates every module enabled in conf and resolves the dependency order.
+func NewRegistry(conf *config.Config, db *sql.DB) (*Registry, error) {
+	r := &Registry{
+		db:       db,
+		conf:     conf,
+		modules:  make(map[string]Module, len(conf.Modules)),
+		building: make(map[string]bool),
+	}
+
+	for _, m := range conf.Modules {
+		if _, err := r.build(m.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	visited := make(map[string]bool, len(r.modules))
+	for _, m := range conf.Modules {
+		if err := r.resolve(m.Name, "", visited); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (r *Registry) build(name string) (Module, error) {
+	if m, ok := r.modules[name]; ok {
+		return m, nil
+	}
+
+	if r.building[name] {
+		return nil, errCycle(name)
+	}
+
+	mc, ok := r.conf.Module(name)
+	if !ok {
+		return nil, errNotEnabled(name, "config")
+	}
+
+	f, ok := factory(name)
+	if !ok {
+		return nil, errUnknownModule(name)
+	}
+
+	r.building[name] = true
+	defer delete(r.building, name)
+
+	m, err := f(&Env{
+		DB:       r.db,
+		Config:   r.conf,
+		Module:   mc,
+		registry: r,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.modules[name] = m
+	return m, nil
+}
+
+// resolve appends the module after its dependencies.
+func (r *Registry) resolve(name, by string, visited map[string]bool) error {
+	if visited[name] {
+		return nil
+	}
+
+	m, ok := r.modules[name]
+	if !ok {
+		return errNotEnabled(name, by)
+	}
+
+	if r.building[name] {
+		return errCycle(name)
+	}
+	r.building[name] = true
+	defer delete(r.building, name)
+
+	for _, dep := range m.Dependencies() {
+		if err := r.resolve(dep, name, visited); err != nil {
+			return err
+		}
+	}
+
+	visited[name] = true
+	r.ordered = append(r.ordered, m)
+	return nil
+}
+
+// Modules returns the modules in dependency order.
+func (r *Registry) Modules() []Module {
+	return r.ordered
+}
+
+// Module returns the enabled module by name.
+func (r *Registry) Module(name string) (Module, bool) {
+	m, ok := r.modules[name]
+	return m, ok
+}
+
+// Migrate applies the pending migrations of every module in dependency order.
+func (r *Registry) Migrate(mi *migrate.Migrator) error {
+	for _, m := range r.ordered {
+		if err := mi.Up(m.Name(), m.Migrations()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rollback rolls back the last steps migrations of the module by name.
+func (r *Registry) Rollback(mi *migrate.Migrator, name string, steps int) error {
+	m, ok := r.modules[name]
+	if !ok {
+		return errNotEnabled(name, "rollback")
+	}
+
+	return mi.Down(name, m.Migrations(), steps)
+}
+
+// RegisterRouter registers the public API first, then uses the middlewares
+// in config order, and registers the API left at last.
+func (r *Registry) RegisterRouter(router *gin.Engine) error {
+	var protected []Module
+
+	for _, m := range r.ordered {
+		mc, _ := r.conf.Module(m.Name())
+
+		if p, ok := m.(PublicRouter); ok {
+			r.record(router, m, true, func() { p.RegisterPublicRouter(router.Group(mc.Group)) })
+		}
+
+		if mc.Public {
+			r.record(router, m, true, func() { m.RegisterRouter(router.Group(mc.Group)) })
+			continue
+		}
+		protected = append(protected, m)
+	}
+
+	middlewares := make(map[string]gin.HandlerFunc)
+	for _, m := range r.ordered {
+		p, ok := m.(MiddlewareProvider)
+		if !ok {
+			continue
+		}
+
+		for name, handler := range p.Middlewares() {
+			if _, dup := middlewares[name]; dup {
+				return errDuplicateMiddleware(name)
+			}
+			middlewares[name] = handler
+		}
+	}
+
+	for _, name := range r.conf.Middlewares {
+		handler, ok := middlewares[name]
+		if !ok {
+			return errUnknownMiddleware(name)
+		}
+		router.Use(handler)
+	}
+
+	public := len(r.conf.Middlewares) == 0
+	for _, m := range protected {
+		mc, _ := r.conf.Module(m.Name())
+		r.record(router, m, public, func() { m.RegisterRouter(router.Group(mc.Group)) })
+	}
+
+	return nil
+}
+
+// Setup makes router serve the API of the modules the way the server does,
+// the errors are answered in the same body and the docs are served.
+func (r *Registry) Setup(router *gin.Engine) error {
+	router.Use(errs.Handler())
+	router.NoRoute(errs.NoRoute)
+
+	// the docs are public, and filled after every module is registered.
+	doc := openapi.New(openapi.Info{Title: "shower", Version: "v1"})
+	if r.conf.Server.Docs != "" {
+		openapi.Serve(router, r.conf.Server.Docs, doc)
+	}
+
+	if err := r.RegisterRouter(router); err != nil {
+		return err
+	}
+	r.Document(doc)
+
+	return nil
+}
+
+// record runs register and remembers the routes it adds as the routes of m.
+func (r *Registry) record(router *gin.Engine, m Module, public bool, register func()) {
+	before := make(map[string]bool)
+	for _, ri := range router.Routes() {
+		before[ri.Method+" "+ri.Path] = true
+	}
+
+	register()
+
+	for _, ri := range router.Routes() {
+		if !before[ri.Method+" "+ri.Path] {
+			r.routes = append(r.routes, route{module: m, public: public, RouteInfo: ri})
+		}
+	}
+}
+
+// Document adds the routes registered by RegisterRouter to doc, a route is
+// described by the operation of its module with the same method and path.
+func (r *Registry) Document(doc *openapi.Document) {
+	routes := make([]route, len(r.routes))
+	copy(routes, r.routes)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	for _, rt := range routes {
+		var op *openapi.Operation
+
+		if d, ok := rt.module.(Documented); ok {
+			mc, _ := r.conf.Module(rt.module.Name())
+			ops := d.Operations()
+			for i := range ops {
+				if ops[i].Method == rt.Method && openapi.JoinPath(mc.Group, ops[i].Path) == rt.Path {
+					op = &ops[i]
+					break
+				}
+			}
+		}
+
+		doc.Add(rt.module.Name(), rt.Method, rt.Path, rt.Handler, rt.public, op)
+	}
+}
+
+// Start starts every module in dependency order.
+func (r *Registry) Start(ctx context.Context) error {
+	for _, m := range r.ordered {
+		if err := m.Start(ctx); err != nil {
+			return fmt.Errorf("module: start %s: %w", m.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops every module in reverse dependency order.
+// It returns the first error but still tries to stop the others.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	var first error
+
+	for i := len(r.ordered) - 1; i >= 0; i-- {
+		m := r.ordered[i]
+		if err := m.Shutdown(ctx); err != nil {
+			log.Println("[Module] : shutdown", m.Name(), err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+
+	return first
+}
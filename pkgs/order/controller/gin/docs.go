@@ -0,0 +1,17 @@
+package order
+
+import (
+	"net/http"
+
+	"github.com/abserari/shower/utils/openapi"
+)
+
+// Operations describes the routes of the module in the OpenAPI document.
+func (ctl *Controller) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/create", Summary: "Create an order", Request: insertRequest{}, Response: insertResponse{}},
+		{Method: http.MethodPost, Path: "/info", Summary: "Get an order with its items", Request: orderInfoByOrderIDRequest{}, Response: orderInfoByOrderIDResponse{}},
+		{Method: http.MethodPost, Path: "/userAuth", Summary: "List the orders of a user by status", Request: lisitOrderByUserIDAndStatusRequest{}, Response: lisitOrderByUserIDAndStatusResponse{}},
+		{Method: http.MethodPost, Path: "/id", Summary: "Get the order ID by the order code", Request: orderIDByOrderCodeRequest{}, Response: orderIDByOrderCodeResponse{}},
+	}
+}
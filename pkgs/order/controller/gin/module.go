@@ -0,0 +1,46 @@
+package order
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/abserari/shower/pkgs/module"
+	"github.com/abserari/shower/pkgs/order/model"
+	"github.com/abserari/shower/pkgs/order/model/memory"
+	"github.com/abserari/shower/pkgs/order/model/mysql"
+)
+
+// ModuleName is the name of order in config.
+const ModuleName = "order"
+
+func init() {
+	module.Register(ModuleName, func(env *module.Env) (module.Module, error) {
+		interval, err := strconv.Atoi(env.Module.Option("closed_interval", "5"))
+		if err != nil {
+			return nil, err
+		}
+
+		var repo model.Repository
+		if env.Memory() {
+			repo = memory.NewRepository()
+		} else {
+			repo = mysql.NewRepository(env.DB, env.Module.Database, env.Module.Table, env.Module.Option("item_table", "Items"))
+		}
+
+		return New(repo, Config{
+			ClosedInterval: interval,
+		}), nil
+	})
+}
+
+// Name returns the name of the module.
+func (ctl *Controller) Name() string { return ModuleName }
+
+// Dependencies is empty, the module works alone.
+func (ctl *Controller) Dependencies() []string { return nil }
+
+// Start does nothing, there is no background work.
+func (ctl *Controller) Start(ctx context.Context) error { return nil }
+
+// Shutdown has nothing to stop.
+func (ctl *Controller) Shutdown(ctx context.Context) error { return nil }
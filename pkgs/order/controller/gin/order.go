@@ -1,13 +1,14 @@
 package order
 
 import (
-	"database/sql"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/abserari/shower/pkgs/order/model/mysql"
+	"github.com/abserari/shower/pkgs/order/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,91 +21,61 @@ import (
 // }
 
 type Config struct {
-	OrderDB        string
-	OrderTable     string
-	ItemTable      string
 	ClosedInterval int
 	// Stock          Stocker
 	// User           UserChecker
 }
 
 type Controller struct {
-	db  *sql.DB
-	Cnf Config
+	repo model.Repository
+	Cnf  Config
 }
 
-func Register(r gin.IRouter, db *sql.DB) error {
+// RegisterRouter registers the routes of the module on r.
+func (ctl *Controller) RegisterRouter(r gin.IRouter) {
 	if r == nil {
 		log.Fatal("[InitRouter]: server is nil")
 	}
-	cnf := Config{
-		OrderDB:        "test",
-		OrderTable:     "orderTable",
-		ItemTable:      "Items",
-		ClosedInterval: 5,
-	}
-	c := New(db, cnf)
-
-	if err := c.CreateDB(); err != nil {
-		log.Fatal(err)
-		return err
-	}
-
-	if err := c.CreateOrderTable(); err != nil {
-		log.Fatal(err)
-		return err
-	}
-
-	if err := c.CreateItemTable(); err != nil {
-		log.Fatal(err)
-		return err
-	}
-
-	r.POST("/api/v1/order/create", c.Insert)
-	r.POST("/api/v1/order/info", c.OrderInfoByOrderID)
-	r.POST("/api/v1/order/userAuth", c.LisitOrderByUserIDAndStatus)
-	r.POST("/api/v1/order/id", c.OrderIDByOrderCode)
 
-	return nil
+	r.POST("/create", ctl.Insert)
+	r.POST("/info", ctl.OrderInfoByOrderID)
+	r.POST("/userAuth", ctl.LisitOrderByUserIDAndStatus)
+	r.POST("/id", ctl.OrderIDByOrderCode)
 }
 
-// New -
-func New(db *sql.DB, cnf Config) *Controller {
+// New creates a controller of repo.
+func New(repo model.Repository, cnf Config) *Controller {
 	return &Controller{
-		db:  db,
-		Cnf: cnf,
+		repo: repo,
+		Cnf:  cnf,
 	}
 }
 
-// CreateDB -
-func (ctl *Controller) CreateDB() error {
-	return mysql.CreateDB(ctl.db, ctl.Cnf.OrderDB)
+// Migrations returns the migrations of the repository.
+func (ctl *Controller) Migrations() []migrate.Migration {
+	return migrate.Of(ctl.repo)
 }
 
-// CreateOrderTable -
-func (ctl *Controller) CreateOrderTable() error {
-	ostore := ctl.Cnf.OrderDB + "." + ctl.Cnf.OrderTable
-	return mysql.CreateTable(ctl.db, ostore)
+type insertRequest struct {
+	UserID     uint64 `json:"userid"`
+	AddressID  string `json:"addressid"`
+	TotalPrice uint32 `json:"totalprice"`
+	Promotion  string `json:"promotion"`
+	Freight    uint32 `json:"freight"`
+
+	Items []model.Item `json:"items"`
 }
 
-// CreateItemTable -
-func (ctl *Controller) CreateItemTable() error {
-	istore := ctl.Cnf.OrderDB + "." + ctl.Cnf.ItemTable
-	return mysql.CreateTable(ctl.db, istore)
+type insertResponse struct {
+	Status    int    `json:"status"`
+	OrderID   uint32 `json:"orderid"`
+	OrderCode string `json:"ordercode"`
 }
 
 // Insert -
 func (ctl *Controller) Insert(c *gin.Context) {
 	var (
-		req struct {
-			UserID     uint64 `json:"userid"`
-			AddressID  string `json:"addressid"`
-			TotalPrice uint32 `json:"totalprice"`
-			Promotion  string `json:"promotion"`
-			Freight    uint32 `json:"freight"`
-
-			Items []mysql.Item `json:"items"`
-		}
+		req insertRequest
 		rep struct {
 			ordercode string
 			orderid   uint32
@@ -112,20 +83,18 @@ func (ctl *Controller) Insert(c *gin.Context) {
 		err error
 	)
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 	promotion, err := strconv.ParseBool(req.Promotion)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+		c.Error(errs.Wrap(errs.KindValidation, "promotion is not a boolean", err))
 		return
 	}
 
 	times := time.Now()
 	rep.ordercode = strconv.Itoa(times.Year()) + strconv.Itoa(int(times.Month())) + strconv.Itoa(times.Day()) + strconv.Itoa(times.Hour()) + strconv.Itoa(times.Minute()) + strconv.Itoa(times.Second()) + strconv.Itoa(int(req.UserID))
-	order := mysql.Order{
+	order := model.Order{
 		OrderCode:  rep.ordercode,
 		UserID:     req.UserID,
 		AddressID:  req.AddressID,
@@ -135,56 +104,63 @@ func (ctl *Controller) Insert(c *gin.Context) {
 		Created:    times,
 	}
 
-	rep.orderid, err = mysql.Insert(order, req.Items, ctl.db, ctl.Cnf.ClosedInterval, ctl.Cnf.OrderDB, ctl.Cnf.OrderTable, ctl.Cnf.ItemTable)
+	rep.orderid, err = ctl.repo.Insert(order, req.Items, ctl.Cnf.ClosedInterval)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"statue": http.StatusOK, "orderid": rep.orderid, "ordercode": rep.ordercode})
+	c.JSON(http.StatusOK, insertResponse{Status: http.StatusOK, OrderID: rep.orderid, OrderCode: rep.ordercode})
 	return
 }
 
+type orderIDByOrderCodeRequest struct {
+	Ordercode string `json:"ordercode"`
+}
+
+type orderIDByOrderCodeResponse struct {
+	Status int    `json:"status"`
+	ID     uint32 `json:"id"`
+}
+
 //optional
 func (ctl *Controller) OrderIDByOrderCode(c *gin.Context) {
-	var req struct {
-		Ordercode string `json:"ordercode"`
-	}
+	var req orderIDByOrderCodeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
-	ostore := ctl.Cnf.OrderDB + "." + ctl.Cnf.OrderTable
-	id, err := mysql.OrderIDByOrderCode(ctl.db, ostore, req.Ordercode)
+	id, err := ctl.repo.OrderIDByOrderCode(req.Ordercode)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"statue": http.StatusOK, "id": id})
+	c.JSON(http.StatusOK, orderIDByOrderCodeResponse{Status: http.StatusOK, ID: id})
 	return
 }
 
+type orderInfoByOrderIDRequest struct {
+	OrderID uint32 `json:"orderid"`
+}
+
+type orderInfoByOrderIDResponse struct {
+	Status int           `json:"status"`
+	Order  *model.Order  `json:"order"`
+	Orm    []*model.Item `json:"items"`
+}
+
 //full info for One Order
 func (ctl *Controller) OrderInfoByOrderID(c *gin.Context) {
-	var req struct {
-		OrderID uint32 `json:"orderid"`
-	}
+	var req orderInfoByOrderIDRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
-	ostore := ctl.Cnf.OrderDB + "." + ctl.Cnf.OrderTable
-	istore := ctl.Cnf.OrderDB + "." + ctl.Cnf.ItemTable
-	rep, err := mysql.SelectByOrderKey(ctl.db, ostore, istore, req.OrderID)
+	rep, err := ctl.repo.SelectByOrderKey(req.OrderID)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"statue": http.StatusOK, "Order": rep.Order, "Orm": rep.Orm})
+	c.JSON(http.StatusOK, orderInfoByOrderIDResponse{Status: http.StatusOK, Order: rep.Order, Orm: rep.Orm})
 	return
 }
 
@@ -196,25 +172,28 @@ mode:
   Consigned  = 3
   Canceled   = 4
 */
+type lisitOrderByUserIDAndStatusRequest struct {
+	Userid uint64 `json:"userid"`
+	Status uint8  `json:"status"`
+}
+
+type lisitOrderByUserIDAndStatusResponse struct {
+	Status int               `json:"status"`
+	Orders []*model.OrmOrder `json:"orders"`
+}
+
 func (ctl *Controller) LisitOrderByUserIDAndStatus(c *gin.Context) {
-	var req struct {
-		Userid uint64 `json:"userid"`
-		Status uint8  `json:"status"`
-	}
+	var req lisitOrderByUserIDAndStatusRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
-	ostore := ctl.Cnf.OrderDB + "." + ctl.Cnf.OrderTable
-	istore := ctl.Cnf.OrderDB + "." + ctl.Cnf.ItemTable
-	orders, err := mysql.LisitOrderByUserID(ctl.db, ostore, istore, req.Userid, req.Status)
+	orders, err := ctl.repo.LisitOrderByUserID(req.Userid, req.Status)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": http.StatusBadRequest})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"statue": http.StatusOK, "Orders": orders})
+	c.JSON(http.StatusOK, lisitOrderByUserIDAndStatusResponse{Status: http.StatusOK, Orders: orders})
 	return
 }
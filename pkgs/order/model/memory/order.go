@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abserari/shower/pkgs/order/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+var (
+	errDuplicateOrderCode = errs.Conflict("order already exists")
+)
+
+// Repository stores the orders in memory, it's used in development and tests.
+type Repository struct {
+	mu     sync.RWMutex
+	nextID uint32
+	orders map[uint32]*model.Order
+	items  map[uint32][]model.Item
+}
+
+// NewRepository creates an empty Repository.
+func NewRepository() *Repository {
+	return &Repository{
+		nextID: 10000,
+		orders: make(map[uint32]*model.Order),
+		items:  make(map[uint32][]model.Item),
+	}
+}
+
+// Insert closes the order closedInterval hours after it's created.
+func (r *Repository) Insert(order model.Order, items []model.Item, closedInterval int) (uint32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, o := range r.orders {
+		if o.OrderCode == order.OrderCode {
+			return 0, errDuplicateOrderCode
+		}
+	}
+
+	now := time.Now()
+	o := model.Order{
+		ID:         r.nextID,
+		OrderCode:  order.OrderCode,
+		UserID:     order.UserID,
+		ShipCode:   "100000",
+		AddressID:  order.AddressID,
+		TotalPrice: order.TotalPrice,
+		Promotion:  order.Promotion,
+		Freight:    order.Freight,
+		Created:    now,
+		Closed:     order.Created.Add(time.Duration(closedInterval * int(time.Hour))),
+		Updated:    now,
+	}
+	r.nextID++
+
+	stored := make([]model.Item, len(items))
+	for i, x := range items {
+		x.OrderID = o.ID
+		stored[i] = x
+	}
+
+	r.orders[o.ID] = &o
+	r.items[o.ID] = stored
+	return o.ID, nil
+}
+
+// OrderIDByOrderCode returns ErrNotFound if there's no order of the code.
+func (r *Repository) OrderIDByOrderCode(ordercode string) (uint32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for id, o := range r.orders {
+		if o.OrderCode == ordercode {
+			return id, nil
+		}
+	}
+
+	return 0, model.ErrNotFound
+}
+
+func (r *Repository) orm(o *model.Order) *model.OrmOrder {
+	order := *o
+
+	var items []*model.Item
+	for _, x := range r.items[o.ID] {
+		item := x
+		items = append(items, &item)
+	}
+
+	return &model.OrmOrder{Order: &order, Orm: items}
+}
+
+// SelectByOrderKey returns the order of orderid with its items.
+func (r *Repository) SelectByOrderKey(orderid uint32) (*model.OrmOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	o, ok := r.orders[orderid]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+
+	return r.orm(o), nil
+}
+
+// LisitOrderByUserID lists the orders of the user in the status mode.
+func (r *Repository) LisitOrderByUserID(userid uint64, mode uint8) ([]*model.OrmOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var OOs []*model.OrmOrder
+	for _, o := range r.orders {
+		if o.UserID == userid && o.Status == mode {
+			OOs = append(OOs, r.orm(o))
+		}
+	}
+
+	sort.Slice(OOs, func(i, j int) bool { return OOs[i].ID < OOs[j].ID })
+	return OOs, nil
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/abserari/shower/utils/errs"
+)
+
+// ErrNotFound is returned if there's no such order.
+var ErrNotFound = errs.NotFound("order not found")
+
+// Order is an order of a user, its items are kept apart.
+type Order struct {
+	ID         uint32
+	OrderCode  string    `json:"ordercode"`
+	UserID     uint64    `json:"userid"`
+	ShipCode   string    `json:"shipcode"`
+	AddressID  string    `json:"addressid"`
+	TotalPrice uint32    `json:"totalprice"`
+	PayWay     uint8     `json:"payway"`
+	Promotion  bool      `json:"promotion"`
+	Freight    uint32    `json:"freight"`
+	Status     uint8     `json:"status"`
+	Created    time.Time `json:"created"`
+	Closed     time.Time `json:"closed"`
+	Updated    time.Time `json:"updated"`
+}
+
+// Item is a product bought in an order.
+type Item struct {
+	ProductId uint32 `json:"productid"`
+	OrderID   uint32 `json:"orderid"`
+	Count     uint32 `json:"count"`
+	Price     uint32 `json:"price"`
+	Discount  uint32 `json:"discount"`
+}
+
+// OrmOrder is an order with its items.
+type OrmOrder struct {
+	*Order
+	Orm []*Item
+}
+
+// Repository stores the orders and their items.
+type Repository interface {
+	// Insert closes the order closedInterval hours after it's created.
+	Insert(order Order, items []Item, closedInterval int) (uint32, error)
+	OrderIDByOrderCode(ordercode string) (uint32, error)
+	SelectByOrderKey(orderid uint32) (*OrmOrder, error)
+	LisitOrderByUserID(userid uint64, mode uint8) ([]*OrmOrder, error)
+}
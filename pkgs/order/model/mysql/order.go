@@ -5,36 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/abserari/shower/pkgs/order/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 )
 
-type Order struct {
-	ID         uint32
-	OrderCode  string    `json:"ordercode"`
-	UserID     uint64    `json:"userid"`
-	ShipCode   string    `json:"shipcode"`
-	AddressID  string    `json:"addressid"`
-	TotalPrice uint32    `json:"totalprice"`
-	PayWay     uint8     `json:"payway"`
-	Promotion  bool      `json:"promotion"`
-	Freight    uint32    `json:"freight"`
-	Status     uint8     `json:"status"`
-	Created    time.Time `json:"created"`
-	Closed     time.Time `json:"closed"`
-	Updated    time.Time `json:"updated"`
-}
+// Order is an order of a user, its items are kept apart.
+type Order = model.Order
 
-type Item struct {
-	ProductId uint32 `json:"productid"`
-	OrderID   uint32 `json:"orderid"`
-	Count     uint32 `json:"count"`
-	Price     uint32 `json:"price"`
-	Discount  uint32 `json:"discount"`
-}
+// Item is a product bought in an order.
+type Item = model.Item
 
-type OrmOrder struct {
-	*Order
-	Orm []*Item
-}
+// OrmOrder is an order with its items.
+type OrmOrder = model.OrmOrder
 
 const (
 	orderDB = iota
@@ -49,6 +33,7 @@ const (
 	payByOrderID
 	consignByOrderID
 	statusByOrderID
+	dropTable
 )
 
 var categorySQLFormatStr = []string{
@@ -91,6 +76,26 @@ var categorySQLFormatStr = []string{
 	`UPDATE %s.%s SET payWay = ? , updated = ? , status = 2 WHERE id = ? LIMIT 1 `,
 	`UPDATE %s.%s SET shipCode = ? , updated = ? , status = 3 WHERE id = ? LIMIT 1 `,
 	`UPDATE %s.%s SET status = ? , updated = ? WHERE id = ? LIMIT 1 `,
+	`DROP TABLE IF EXISTS %s`,
+}
+
+// Migrations returns the migrations creating the tables.
+func Migrations(createDB, ostore, istore string) []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version:     1,
+			Description: "create order and item table",
+			Up: []string{
+				fmt.Sprintf(categorySQLFormatStr[orderDB], createDB),
+				fmt.Sprintf(categorySQLFormatStr[orderTable], ostore),
+				fmt.Sprintf(categorySQLFormatStr[itemTable], istore),
+			},
+			Down: []string{
+				fmt.Sprintf(categorySQLFormatStr[dropTable], istore),
+				fmt.Sprintf(categorySQLFormatStr[dropTable], ostore),
+			},
+		},
+	}
 }
 
 // CreateDB -
@@ -107,6 +112,13 @@ func CreateTable(db *sql.DB, ostore string) error {
 	return err
 }
 
+// CreateItemTable creates the table of the items of the orders.
+func CreateItemTable(db *sql.DB, istore string) error {
+	sql := fmt.Sprintf(categorySQLFormatStr[itemTable], istore)
+	_, err := db.Exec(sql)
+	return err
+}
+
 // Insert -
 func Insert(order Order, items []Item, db *sql.DB, closedInterval int, orderDB string, orderTable string, itemTable string) (id uint32, err error) {
 	tx, err := db.Begin()
@@ -114,12 +126,13 @@ func Insert(order Order, items []Item, db *sql.DB, closedInterval int, orderDB s
 		return 0, err
 	}
 
+	// keep the error of the insert, rollback is only cleaning up.
 	defer func() {
 		if err != nil {
-			err = tx.Rollback()
-		} else {
-			err = tx.Commit()
+			tx.Rollback()
+			return
 		}
+		err = tx.Commit()
 	}()
 
 	order.Closed = order.Created.Add(time.Duration(closedInterval * int(time.Hour)))
@@ -128,7 +141,7 @@ func Insert(order Order, items []Item, db *sql.DB, closedInterval int, orderDB s
 	result, err := tx.Exec(ostoresql, order.OrderCode, order.UserID, order.AddressID, order.TotalPrice, order.Promotion, order.Freight, order.Closed)
 
 	if err != nil {
-		return 0, err
+		return 0, errs.FromDB(err, "order")
 	}
 
 	if affected, _ := result.RowsAffected(); affected == 0 {
@@ -178,10 +191,15 @@ func OrderIDByOrderCode(db *sql.DB, ostore string, ordercode string) (uint32, er
 	}
 	defer rows.Close()
 
-	for rows.Next() {
-		if err := rows.Scan(&orderid); err != nil {
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
 			return 0, err
 		}
+		return 0, model.ErrNotFound
+	}
+
+	if err := rows.Scan(&orderid); err != nil {
+		return 0, err
 	}
 
 	return orderid, nil
@@ -198,10 +216,14 @@ func SelectByOrderKey(db *sql.DB, ostore, istore string, orderid uint32) (*OrmOr
 		return nil, err
 	}
 	defer rows.Close()
-	for rows.Next() {
-		if err := rows.Scan(&o.ID, &o.OrderCode, &o.UserID, &o.ShipCode, &o.AddressID, &o.TotalPrice, &o.PayWay, &o.Promotion, &o.Freight, &o.Status, &o.Created, &o.Closed, &o.Updated); err != nil {
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
 			return nil, err
 		}
+		return nil, model.ErrNotFound
+	}
+	if err := rows.Scan(&o.ID, &o.OrderCode, &o.UserID, &o.ShipCode, &o.AddressID, &o.TotalPrice, &o.PayWay, &o.Promotion, &o.Freight, &o.Status, &o.Created, &o.Closed, &o.Updated); err != nil {
+		return nil, err
 	}
 	lisitItemByOrderIdsql := fmt.Sprintf(categorySQLFormatStr[itemsByOrderID], istore)
 	oo.Order = &o
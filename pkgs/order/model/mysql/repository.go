@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/abserari/shower/utils/migrate"
+)
+
+// Repository stores the orders in MySQL, the items are in their own table.
+type Repository struct {
+	db         *sql.DB
+	orderDB    string
+	orderTable string
+	itemTable  string
+}
+
+// NewRepository creates a Repository in db.
+func NewRepository(db *sql.DB, orderDB, orderTable, itemTable string) *Repository {
+	return &Repository{
+		db:         db,
+		orderDB:    orderDB,
+		orderTable: orderTable,
+		itemTable:  itemTable,
+	}
+}
+
+func (r *Repository) ostore() string {
+	return r.orderDB + "." + r.orderTable
+}
+
+func (r *Repository) istore() string {
+	return r.orderDB + "." + r.itemTable
+}
+
+// Migrations returns the migrations of the tables.
+func (r *Repository) Migrations() []migrate.Migration {
+	return Migrations(r.orderDB, r.ostore(), r.istore())
+}
+
+// Insert creates the order of items, which is closed closedInterval hours after it's created.
+func (r *Repository) Insert(order Order, items []Item, closedInterval int) (uint32, error) {
+	return Insert(order, items, r.db, closedInterval, r.orderDB, r.orderTable, r.itemTable)
+}
+
+// OrderIDByOrderCode returns ErrNotFound if there's no order of the code.
+func (r *Repository) OrderIDByOrderCode(ordercode string) (uint32, error) {
+	return OrderIDByOrderCode(r.db, r.ostore(), ordercode)
+}
+
+// SelectByOrderKey returns the order of orderid with its items.
+func (r *Repository) SelectByOrderKey(orderid uint32) (*OrmOrder, error) {
+	return SelectByOrderKey(r.db, r.ostore(), r.istore(), orderid)
+}
+
+// LisitOrderByUserID lists the orders of the user in the status mode.
+func (r *Repository) LisitOrderByUserID(userid uint64, mode uint8) ([]*OrmOrder, error) {
+	return LisitOrderByUserID(r.db, r.ostore(), r.istore(), userid, mode)
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/abserari/shower/utils/openapi"
+)
+
+// Operations describes the routes of the module in the OpenAPI document.
+func (c *Controller) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/addrole", Summary: "Create a role", Request: createRoleRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/modifyrole", Summary: "Modify a role", Request: modifyRoleRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/activerole", Summary: "Activate or deactivate a role", Request: modifyRoleActiveRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/twofactorrole", Summary: "Require the second factor by a role or not", Request: modifyRoleTwoFactorRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/getallrole", Summary: "List the roles", Response: roleListResponse{}},
+		{Method: http.MethodPost, Path: "/idgetrole", Summary: "Get a role", Request: getRoleByIDRequest{}, Response: getRoleByIDResponse{}},
+		{Method: http.MethodPost, Path: "/addurl", Summary: "Grant a URL to a role", Request: addURLPermissionRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/removeurl", Summary: "Revoke a URL from a role", Request: removeURLPermissionRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/urlgetrole", Summary: "List the roles granted a URL", Request: urlPermissionsRequest{}, Response: urlPermissionsResponse{}},
+		{Method: http.MethodPost, Path: "/geturl", Summary: "List the URL permissions", Response: permissionsResponse{}},
+		{Method: http.MethodPost, Path: "/addrelation", Summary: "Assign a role to an admin", Request: addRelationRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/removerelation", Summary: "Remove a role from an admin", Request: removeRelationRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/admingetrole", Summary: "List the roles of an admin", Request: adminGetRoleMapRequest{}, Response: adminGetRoleMapResponse{}},
+		{Method: http.MethodPost, Path: "/getalladmin", Summary: "List the admin role relations", Response: getAdminIDMapResponse{}},
+		{Method: http.MethodPost, Path: "/getallroleid", Summary: "List the role admin relations", Response: getRoleIDMapResponse{}},
+	}
+}
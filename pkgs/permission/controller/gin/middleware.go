@@ -6,25 +6,26 @@
 package controller
 
 import (
-	"errors"
-	"net/http"
-
-	permission "github.com/abserari/shower/pkgs/permission/model/mysql"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/token"
 	"github.com/gin-gonic/gin"
 )
 
 var (
-	errPermission = errors.New("userAuth permission is wrong")
+	errPermission   = errs.Forbidden("userAuth permission is wrong")
+	errSecondFactor = errs.Forbidden("two-factor authentication is required by the role")
 )
 
-//CheckPermission middleware that checks the permission
+//CheckPermission middleware that checks the permission. The roles in the token
+// of the current permissions version are checked without the database.
 func (c *Controller) CheckPermission() func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
 		reqURL := ctx.Request.URL.Path
 
 		adminID, err := c.getIDFunc(ctx)
 		if err != nil {
-			ctx.AbortWithError(http.StatusBadGateway, err)
+			ctx.Error(err)
+			ctx.Abort()
 			return
 		}
 
@@ -33,15 +34,41 @@ func (c *Controller) CheckPermission() func(ctx *gin.Context) {
 			return
 		}
 
-		adRole, err := permission.AdminGetRoleMap(c.db, adminID)
+		if claims, ok := token.ClaimsOf(ctx); ok && claims.AdminID == adminID && claims.PermissionsVersion != 0 {
+			s, err := c.snapshotOf(claims.PermissionsVersion)
+			if err != nil {
+				ctx.Error(err)
+				ctx.Abort()
+				return
+			}
+
+			if s != nil {
+				passed := c.secondFactor == nil || c.secondFactor(ctx)
+				if err = s.check(claims.Roles, reqURL, passed); err != nil {
+					ctx.Error(err)
+					ctx.Abort()
+				}
+				return
+			}
+		}
+
+		adRole, err := c.repo.AdminGetRoleMap(adminID)
 		if err != nil {
-			ctx.AbortWithError(http.StatusConflict, err)
+			ctx.Error(err)
+			ctx.Abort()
 			return
 		}
 
-		urlRole, err := permission.URLPermissions(c.db, &reqURL)
+		if err = c.checkSecondFactor(ctx, adRole); err != nil {
+			ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+
+		urlRole, err := c.repo.URLPermissions(&reqURL)
 		if err != nil {
-			ctx.AbortWithError(http.StatusFailedDependency, err)
+			ctx.Error(err)
+			ctx.Abort()
 			return
 		}
 
@@ -53,8 +80,27 @@ func (c *Controller) CheckPermission() func(ctx *gin.Context) {
 			}
 		}
 
-		ctx.AbortWithError(http.StatusForbidden, errPermission)
-		ctx.JSON(http.StatusForbidden, gin.H{"status": http.StatusForbidden})
+		ctx.Error(errPermission)
+		ctx.Abort()
+	}
+}
+
+// checkSecondFactor rejects the admin not passed the second factor if any of
+// the roles requires it.
+func (c *Controller) checkSecondFactor(ctx *gin.Context, roles map[uint32]bool) error {
+	if c.secondFactor == nil || c.secondFactor(ctx) {
+		return nil
+	}
+
+	required, err := c.repo.TwoFactorRoles()
+	if err != nil {
+		return err
+	}
 
+	for id := range roles {
+		if required[id] {
+			return errSecondFactor
+		}
 	}
+	return nil
 }
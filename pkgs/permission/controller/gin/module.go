@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/abserari/shower/pkgs/module"
+	"github.com/abserari/shower/pkgs/permission/model"
+	"github.com/abserari/shower/pkgs/permission/model/memory"
+	mysql "github.com/abserari/shower/pkgs/permission/model/mysql"
+	"github.com/gin-gonic/gin"
+)
+
+// ModuleName is the name of permission in config.
+const ModuleName = "permission"
+
+// identifier is the module who knows the current admin.
+const identifier = "userAuth"
+
+func init() {
+	module.Register(ModuleName, func(env *module.Env) (module.Module, error) {
+		id, err := env.Identifier(identifier)
+		if err != nil {
+			return nil, err
+		}
+
+		var repo model.Repository
+		if env.Memory() {
+			repo = memory.NewRepository()
+		} else {
+			repo = mysql.NewRepository(env.DB)
+		}
+
+		c := New(repo, id.GetID)
+		if f, ok := id.(module.SecondFactor); ok {
+			c.secondFactor = f.SecondFactor
+		}
+		if u, ok := id.(module.RolesUser); ok {
+			u.UseRoles(c)
+		}
+		return c, nil
+	})
+}
+
+// Name returns the name of the module.
+func (c *Controller) Name() string { return ModuleName }
+
+// Dependencies requires the module identifying the admins.
+func (c *Controller) Dependencies() []string { return []string{identifier} }
+
+// Start watches the version of the permissions changed by the other servers.
+func (c *Controller) Start(ctx context.Context) error {
+	c.done = make(chan struct{})
+	go c.watch(c.done)
+	return nil
+}
+
+// Shutdown stops watching the version.
+func (c *Controller) Shutdown(ctx context.Context) error {
+	if c.done != nil {
+		close(c.done)
+		c.done = nil
+	}
+	return nil
+}
+
+// AdminRoles lists the active roles of the admin by ID.
+func (c *Controller) AdminRoles(id uint32) ([]module.Role, error) {
+	assigned, err := c.repo.AdminGetRoleMap(id)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := c.repo.RoleList()
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []module.Role{}
+	for _, r := range all {
+		if assigned[r.RoleID] {
+			roles = append(roles, module.Role{ID: r.RoleID, Name: r.Name})
+		}
+	}
+	return roles, nil
+}
+
+// Middlewares provides permission which checks the userAuth permission.
+func (c *Controller) Middlewares() map[string]gin.HandlerFunc {
+	return map[string]gin.HandlerFunc{
+		"permission": c.CheckPermission(),
+	}
+}
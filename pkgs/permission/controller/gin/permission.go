@@ -6,11 +6,14 @@
 package controller
 
 import (
-	"database/sql"
 	"log"
 	"net/http"
+	"sync"
+	"time"
 
-	mysql "github.com/abserari/shower/pkgs/permission/model/mysql"
+	"github.com/abserari/shower/pkgs/permission/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,14 +24,26 @@ var (
 
 // Controller external service interface
 type Controller struct {
-	db        *sql.DB
+	repo      model.Repository
 	getIDFunc func(c *gin.Context) (uint32, error)
+	// secondFactor reports whether the admin passed the second factor, the
+	// roles requiring it are not checked if nil.
+	secondFactor func(c *gin.Context) bool
+
+	// snapshot checks the tokens of its version, it's nil once the
+	// permissions are changed until loaded again.
+	mu         sync.RWMutex
+	snapshot   *snapshot
+	loadedAt   time.Time
+	generation uint64
+	// done stops watching the version.
+	done chan struct{}
 }
 
 // New create an external service interface
-func New(db *sql.DB, getID func(c *gin.Context) (uint32, error)) *Controller {
+func New(repo model.Repository, getID func(c *gin.Context) (uint32, error)) *Controller {
 	return &Controller{
-		db:        db,
+		repo:      repo,
 		getIDFunc: getID,
 	}
 }
@@ -39,12 +54,16 @@ func (c *Controller) InitWithUserAPI() {
 }
 func (c *Controller) initWithRole() {}
 
+// Migrations create role, permission and relation table.
+func (c *Controller) Migrations() []migrate.Migration {
+	return migrate.Of(c.repo)
+}
+
 //RegisterRouter register router and from now on, every API would check if valid on current AdminID.
 // Should init the permission to the API.
 func (c *Controller) RegisterRouter(r gin.IRouter) {
-	err := mysql.CreateTable(c.db)
-	if err != nil {
-		log.Fatal(err)
+	if r == nil {
+		log.Fatal("[InitRouter]: server is nil")
 	}
 	// init with userAuth defined API.
 	c.InitWithUserAPI()
@@ -56,6 +75,7 @@ func (c *Controller) RegisterRouter(r gin.IRouter) {
 	r.POST("/addrole", c.createRole)
 	r.POST("/modifyrole", c.modifyRole)
 	r.POST("/activerole", c.modifyRoleActive)
+	r.POST("/twofactorrole", c.modifyRoleTwoFactor)
 	r.POST("/getallrole", c.roleList)
 	r.POST("/idgetrole", c.getRoleByID)
 
@@ -74,294 +94,334 @@ func (c *Controller) RegisterRouter(r gin.IRouter) {
 
 }
 
+type createRoleRequest struct {
+	Name  string `json:"name"        binding:"required,alphanum,min=5,max=64"`
+	Intro string `json:"intro"       binding:"required,alphanum,min=2,max=256"`
+}
+
 func (c *Controller) createRole(ctx *gin.Context) {
-	var (
-		role struct {
-			Name  string `json:"name"        binding:"required,alphanum,min=5,max=64"`
-			Intro string `json:"intro"       binding:"required,alphanum,min=2,max=256"`
-		}
-	)
+	var role createRoleRequest
 
 	err := ctx.ShouldBind(&role)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.CreateRole(c.db, &role.Name, &role.Intro)
+	err = c.repo.CreateRole(&role.Name, &role.Intro)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type modifyRoleRequest struct {
+	RoleID uint32 `json:"role_id"     binding:"required"`
+	Name   string `json:"name"        binding:"required,alphanum,min=5,max=64"`
+	Intro  string `json:"intro"       binding:"required,alphanum,min=2,max=256"`
+}
+
 func (c *Controller) modifyRole(ctx *gin.Context) {
-	var (
-		role struct {
-			RoleID uint32 `json:"role_id"     binding:"required"`
-			Name   string `json:"name"        binding:"required,alphanum,min=5,max=64"`
-			Intro  string `json:"intro"       binding:"required,alphanum,min=2,max=256"`
-		}
-	)
+	var role modifyRoleRequest
 
 	err := ctx.ShouldBind(&role)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyRole(c.db, role.RoleID, &role.Name, &role.Intro)
+	err = c.repo.ModifyRole(role.RoleID, &role.Name, &role.Intro)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type modifyRoleActiveRequest struct {
+	RoleID uint32 `json:"role_id"     binding:"required"`
+	Active bool   `json:"active"`
+}
+
 func (c *Controller) modifyRoleActive(ctx *gin.Context) {
-	var (
-		role struct {
-			RoleID uint32 `json:"role_id"     binding:"required"`
-			Active bool   `json:"active"`
-		}
-	)
+	var role modifyRoleActiveRequest
 
 	err := ctx.ShouldBind(&role)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	err = c.repo.ModifyRoleActive(role.RoleID, role.Active)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
 		return
 	}
+	c.invalidate()
 
-	err = mysql.ModifyRoleActive(c.db, role.RoleID, role.Active)
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type modifyRoleTwoFactorRequest struct {
+	RoleID    uint32 `json:"role_id"     binding:"required"`
+	TwoFactor bool   `json:"two_factor"`
+}
+
+func (c *Controller) modifyRoleTwoFactor(ctx *gin.Context) {
+	var role modifyRoleTwoFactorRequest
+
+	err := ctx.ShouldBind(&role)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	err = c.repo.ModifyRoleTwoFactor(role.RoleID, role.TwoFactor)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
+	c.invalidate()
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type roleListResponse struct {
+	Status   int           `json:"status"`
+	RoleList []*model.Role `json:"RoleList"`
+}
+
 func (c *Controller) roleList(ctx *gin.Context) {
-	result, err := mysql.RoleList(c.db)
+	result, err := c.repo.RoleList()
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "RoleList": result})
+	ctx.JSON(http.StatusOK, roleListResponse{Status: http.StatusOK, RoleList: result})
+}
+
+type getRoleByIDRequest struct {
+	RoleID uint32 `json:"role_id"     binding:"required"`
+}
+
+type getRoleByIDResponse struct {
+	Status   int         `json:"status"`
+	RoleByID *model.Role `json:"RoleByID"`
 }
 
 func (c *Controller) getRoleByID(ctx *gin.Context) {
-	var (
-		role struct {
-			RoleID uint32 `json:"role_id"     binding:"required"`
-		}
-	)
+	var role getRoleByIDRequest
 
 	err := ctx.ShouldBind(&role)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	result, err := mysql.GetRoleByID(c.db, role.RoleID)
+	result, err := c.repo.GetRoleByID(role.RoleID)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "RoleByID": result})
+	ctx.JSON(http.StatusOK, getRoleByIDResponse{Status: http.StatusOK, RoleByID: result})
+}
+
+type addURLPermissionRequest struct {
+	URL    string `json:"url"         binding:"required"`
+	RoleID uint32 `json:"role_id"     binding:"required"`
 }
 
 func (c *Controller) addURLPermission(ctx *gin.Context) {
-	var (
-		url struct {
-			URL    string `json:"url"         binding:"required"`
-			RoleID uint32 `json:"role_id"     binding:"required"`
-		}
-	)
+	var url addURLPermissionRequest
 
 	err := ctx.ShouldBind(&url)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.AddURLPermission(c.db, url.RoleID, url.URL)
+	err = c.repo.AddURLPermission(url.RoleID, url.URL)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
+	c.invalidate()
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type removeURLPermissionRequest struct {
+	URL    string `json:"url"     binding:"required"`
+	RoleID uint32 `json:"role_id" binding:"required"`
+}
+
 func (c *Controller) removeURLPermission(ctx *gin.Context) {
-	var (
-		url struct {
-			URL    string `json:"url"     binding:"required"`
-			RoleID uint32 `json:"role_id" binding:"required"`
-		}
-	)
+	var url removeURLPermissionRequest
 
 	err := ctx.ShouldBind(&url)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.RemoveURLPermission(c.db, url.RoleID, url.URL)
+	err = c.repo.RemoveURLPermission(url.RoleID, url.URL)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
+	c.invalidate()
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type urlPermissionsRequest struct {
+	URL string `json:"url"         binding:"required"`
+}
+
+type urlPermissionsResponse struct {
+	Status         int             `json:"status"`
+	URLPermissions map[uint32]bool `json:"URLPermissions"`
+}
+
 func (c *Controller) urlPermissions(ctx *gin.Context) {
-	var (
-		url struct {
-			URL string `json:"url"         binding:"required"`
-		}
-	)
+	var url urlPermissionsRequest
 
 	err := ctx.ShouldBind(&url)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	result, err := mysql.URLPermissions(c.db, &url.URL)
+	result, err := c.repo.URLPermissions(&url.URL)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "URLPermissions": result})
+	ctx.JSON(http.StatusOK, urlPermissionsResponse{Status: http.StatusOK, URLPermissions: result})
+}
+
+type permissionsResponse struct {
+	Status      int                  `json:"status"`
+	Permissions *[]*model.Permission `json:"Permissions"`
 }
 
 func (c *Controller) permissions(ctx *gin.Context) {
-	result, err := mysql.Permissions(c.db)
+	result, err := c.repo.Permissions()
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "Permissions": result})
+	ctx.JSON(http.StatusOK, permissionsResponse{Status: http.StatusOK, Permissions: result})
+}
+
+type addRelationRequest struct {
+	AdminID uint32 `json:"admin_id" binding:"required"`
+	RoleID  uint32 `json:"role_id"  binding:"required"`
 }
 
 func (c *Controller) addRelation(ctx *gin.Context) {
-	var (
-		relation struct {
-			AdminID uint32 `json:"admin_id" binding:"required"`
-			RoleID  uint32 `json:"role_id"  binding:"required"`
-		}
-	)
+	var relation addRelationRequest
 
 	err := ctx.ShouldBind(&relation)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.AddRelation(c.db, relation.AdminID, relation.RoleID)
+	err = c.repo.AddRelation(relation.AdminID, relation.RoleID)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
+	c.invalidate()
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type removeRelationRequest struct {
+	AdminID uint32 `json:"admin_id" binding:"required"`
+	RoleID  uint32 `json:"role_id"  binding:"required"`
+}
+
 func (c *Controller) removeRelation(ctx *gin.Context) {
-	var (
-		relation struct {
-			AdminID uint32 `json:"admin_id" binding:"required"`
-			RoleID  uint32 `json:"role_id"  binding:"required"`
-		}
-	)
+	var relation removeRelationRequest
 
 	err := ctx.ShouldBind(&relation)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.RemoveRelation(c.db, relation.AdminID, relation.RoleID)
+	err = c.repo.RemoveRelation(relation.AdminID, relation.RoleID)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
+	c.invalidate()
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type adminGetRoleMapRequest struct {
+	AdminID uint32 `json:"admin_id" binding:"required"`
+}
+
+type adminGetRoleMapResponse struct {
+	Status  int             `json:"status"`
+	RoleMap map[uint32]bool `json:"RoleMap"`
+}
+
 func (c *Controller) adminGetRoleMap(ctx *gin.Context) {
-	var (
-		relation struct {
-			AdminID uint32 `json:"admin_id" binding:"required"`
-		}
-	)
+	var relation adminGetRoleMapRequest
 
 	err := ctx.ShouldBind(&relation)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	result, err := mysql.AdminGetRoleMap(c.db, relation.AdminID)
+	result, err := c.repo.AdminGetRoleMap(relation.AdminID)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "RoleMap": result})
+	ctx.JSON(http.StatusOK, adminGetRoleMapResponse{Status: http.StatusOK, RoleMap: result})
+}
+
+type getAdminIDMapResponse struct {
+	Status     int             `json:"status"`
+	AdminIDMap map[uint32]bool `json:"AdminIDMap"`
 }
 
 func (c *Controller) getAdminIDMap(ctx *gin.Context) {
-	result, err := mysql.GetAdminIDMap(c.db)
+	result, err := c.repo.GetAdminIDMap()
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "AdminIDMap": result})
+	ctx.JSON(http.StatusOK, getAdminIDMapResponse{Status: http.StatusOK, AdminIDMap: result})
+}
+
+type getRoleIDMapResponse struct {
+	Status    int             `json:"status"`
+	RoleIDMap map[uint32]bool `json:"RoleIDMap"`
 }
 
 func (c *Controller) getRoleIDMap(ctx *gin.Context) {
-	result, err := mysql.GetRoleIDMap(c.db)
+	result, err := c.repo.GetRoleIDMap()
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "RoleIDMap": result})
+	ctx.JSON(http.StatusOK, getRoleIDMapResponse{Status: http.StatusOK, RoleIDMap: result})
 }
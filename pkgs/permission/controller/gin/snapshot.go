@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/abserari/shower/pkgs/module"
+)
+
+const (
+	// refreshInterval is how often the version is checked for the changes
+	// made by the other servers.
+	refreshInterval = 10 * time.Second
+	// reloadInterval bounds how often the snapshot is loaded for the newer tokens.
+	reloadInterval = time.Second
+	// loadRetries is how many times the permissions are read if they change meanwhile.
+	loadRetries = 3
+)
+
+// snapshot is the permissions at a version, the requests of the tokens of
+// the same version are checked by it without the database.
+type snapshot struct {
+	version uint64
+	// urls are the active roles of the URLs.
+	urls map[string]map[uint32]bool
+	// twoFactor are the active roles requiring the second factor.
+	twoFactor map[uint32]bool
+}
+
+// check rejects the roles if none of them could access url, or any of them
+// requires the second factor not passed.
+func (s *snapshot) check(roles []uint32, url string, passed bool) error {
+	if !passed {
+		for _, id := range roles {
+			if s.twoFactor[id] {
+				return errSecondFactor
+			}
+		}
+	}
+
+	for _, id := range roles {
+		if s.urls[url][id] {
+			return nil
+		}
+	}
+	return errPermission
+}
+
+// Grants returns the active roles of the admin by ID at the current version.
+func (c *Controller) Grants(id uint32) (*module.Grants, error) {
+	// the version is read first, the roles changed meanwhile are of a newer one.
+	version, err := c.repo.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	assigned, err := c.repo.AdminGetRoleMap(id)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &module.Grants{Roles: make([]uint32, 0, len(assigned)), Version: version}
+	for id := range assigned {
+		g.Roles = append(g.Roles, id)
+	}
+	sort.Slice(g.Roles, func(i, j int) bool { return g.Roles[i] < g.Roles[j] })
+
+	return g, nil
+}
+
+// snapshotOf returns the snapshot of version, or nil if the version is not
+// current, the requests are checked by the database then.
+func (c *Controller) snapshotOf(version uint64) (*snapshot, error) {
+	c.mu.RLock()
+	s, loadedAt := c.snapshot, c.loadedAt
+	c.mu.RUnlock()
+
+	if s != nil && s.version == version {
+		return s, nil
+	}
+
+	// a token newer than the snapshot tells the permissions are changed.
+	if (s == nil || version > s.version) && time.Since(loadedAt) >= reloadInterval {
+		var err error
+		if s, err = c.reload(); err != nil {
+			return nil, err
+		}
+		if s != nil && s.version == version {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+// reload loads the snapshot of the current version, it's nil if the
+// permissions keep changing.
+func (c *Controller) reload() (*snapshot, error) {
+	c.mu.Lock()
+	c.loadedAt = time.Now()
+	generation := c.generation
+	c.mu.Unlock()
+
+	s, err := c.load()
+	if err != nil || s == nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the snapshot loaded before invalidated may be outdated.
+	if generation != c.generation {
+		return nil, nil
+	}
+	if c.snapshot == nil || s.version > c.snapshot.version {
+		c.snapshot = s
+	}
+	return c.snapshot, nil
+}
+
+// load reads the permissions, they are read again if the version is
+// changed meanwhile.
+func (c *Controller) load() (*snapshot, error) {
+	for i := 0; i < loadRetries; i++ {
+		version, err := c.repo.Version()
+		if err != nil {
+			return nil, err
+		}
+
+		roles, err := c.repo.RoleList()
+		if err != nil {
+			return nil, err
+		}
+		permissions, err := c.repo.Permissions()
+		if err != nil {
+			return nil, err
+		}
+
+		after, err := c.repo.Version()
+		if err != nil {
+			return nil, err
+		}
+		if after != version {
+			continue
+		}
+
+		s := &snapshot{
+			version:   version,
+			urls:      make(map[string]map[uint32]bool),
+			twoFactor: make(map[uint32]bool),
+		}
+		active := make(map[uint32]bool, len(roles))
+		for _, r := range roles {
+			active[r.RoleID] = r.Active
+			if r.Active && r.TwoFactor {
+				s.twoFactor[r.RoleID] = true
+			}
+		}
+		for _, p := range *permissions {
+			if !active[p.RoleID] {
+				continue
+			}
+			if s.urls[p.URL] == nil {
+				s.urls[p.URL] = make(map[uint32]bool)
+			}
+			s.urls[p.URL][p.RoleID] = true
+		}
+		return s, nil
+	}
+
+	return nil, nil
+}
+
+// invalidate drops the snapshot after the permissions are changed, it's
+// loaded again by the next request.
+func (c *Controller) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.snapshot, c.loadedAt = nil, time.Time{}
+	c.generation++
+}
+
+// watch invalidates the snapshot once the version is changed by the other
+// servers, until done is closed.
+func (c *Controller) watch(done <-chan struct{}) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		version, err := c.repo.Version()
+		if err != nil {
+			log.Println("[permission]: check the version:", err)
+			continue
+		}
+
+		c.mu.RLock()
+		changed := c.snapshot != nil && c.snapshot.version != version
+		c.mu.RUnlock()
+
+		if changed {
+			c.invalidate()
+		}
+	}
+}
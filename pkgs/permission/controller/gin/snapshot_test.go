@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/abserari/shower/pkgs/permission/model"
+	"github.com/abserari/shower/pkgs/permission/model/memory"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/token"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	adminID = 1001
+	petURL  = "/api/pet"
+	userURL = "/api/user"
+)
+
+// countingRepository counts the reads of the permissions checked.
+type countingRepository struct {
+	model.Repository
+
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func (r *countingRepository) read(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reads[name]++
+}
+
+// Reads returns the reads counted and resets them.
+func (r *countingRepository) Reads() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reads := r.reads
+	r.reads = make(map[string]int)
+	return reads
+}
+
+func (r *countingRepository) Version() (uint64, error) {
+	r.read("Version")
+	return r.Repository.Version()
+}
+
+func (r *countingRepository) RoleList() ([]*model.Role, error) {
+	r.read("RoleList")
+	return r.Repository.RoleList()
+}
+
+func (r *countingRepository) Permissions() (*[]*model.Permission, error) {
+	r.read("Permissions")
+	return r.Repository.Permissions()
+}
+
+func (r *countingRepository) AdminGetRoleMap(aid uint32) (map[uint32]bool, error) {
+	r.read("AdminGetRoleMap")
+	return r.Repository.AdminGetRoleMap(aid)
+}
+
+func (r *countingRepository) URLPermissions(url *string) (map[uint32]bool, error) {
+	r.read("URLPermissions")
+	return r.Repository.URLPermissions(url)
+}
+
+// fixture is a controller checking the requests of the claims.
+type fixture struct {
+	t      *testing.T
+	c      *Controller
+	repo   *countingRepository
+	router *gin.Engine
+	role   uint32
+	claims *token.Claims
+}
+
+// newFixture creates a role of petURL for adminID, the claims are of the current version.
+func newFixture(t *testing.T) *fixture {
+	gin.SetMode(gin.TestMode)
+
+	f := &fixture{
+		t:    t,
+		repo: &countingRepository{Repository: memory.NewRepository(), reads: make(map[string]int)},
+	}
+	f.c = New(f.repo, func(ctx *gin.Context) (uint32, error) {
+		return f.claims.AdminID, nil
+	})
+
+	f.router = gin.New()
+	f.router.Use(errs.Handler())
+	f.c.RegisterRouter(f.router.Group("/permission"))
+
+	api := f.router.Group("/api", func(ctx *gin.Context) {
+		ctx.Set(token.ClaimsKey, f.claims)
+	}, f.c.CheckPermission())
+	for _, url := range []string{petURL, userURL} {
+		api.GET(url[len("/api"):], func(ctx *gin.Context) {
+			ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+		})
+	}
+
+	name, intro := "petreader", "test"
+	if err := f.repo.CreateRole(&name, &intro); err != nil {
+		t.Fatal(err)
+	}
+	roles, err := f.repo.RoleList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.role = roles[0].RoleID
+
+	f.post("/permission/addurl", map[string]interface{}{"role_id": f.role, "url": petURL})
+	f.post("/permission/addrelation", map[string]interface{}{"admin_id": adminID, "role_id": f.role})
+	f.claims = f.grant()
+	f.repo.Reads()
+
+	return f
+}
+
+// grant returns the claims of the roles of adminID at the current version.
+func (f *fixture) grant() *token.Claims {
+	g, err := f.c.Grants(adminID)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return &token.Claims{AdminID: adminID, Roles: g.Roles, PermissionsVersion: g.Version}
+}
+
+func (f *fixture) post(path string, body interface{}) {
+	f.t.Helper()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		f.t.Fatalf("%s: status %d: %s", path, w.Code, w.Body)
+	}
+}
+
+// get returns the status of url requested by the claims.
+func (f *fixture) get(url string) int {
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	return w.Code
+}
+
+func (f *fixture) expect(url string, status int) {
+	f.t.Helper()
+
+	if got := f.get(url); got != status {
+		f.t.Fatalf("%s: status %d, want %d", url, got, status)
+	}
+}
+
+func TestCheckBySnapshot(t *testing.T) {
+	f := newFixture(t)
+
+	// the first request loads the snapshot.
+	f.expect(petURL, http.StatusOK)
+	if reads := f.repo.Reads(); reads["Permissions"] != 1 || reads["AdminGetRoleMap"] != 0 {
+		t.Fatalf("reads %v, want the snapshot loaded", reads)
+	}
+
+	for i := 0; i < 3; i++ {
+		f.expect(petURL, http.StatusOK)
+		f.expect(userURL, http.StatusForbidden)
+	}
+	if reads := f.repo.Reads(); len(reads) != 0 {
+		t.Fatalf("reads %v, want none", reads)
+	}
+}
+
+func TestCheckAfterChange(t *testing.T) {
+	f := newFixture(t)
+	f.expect(petURL, http.StatusOK)
+
+	before, _ := f.repo.Version()
+	f.post("/permission/activerole", map[string]interface{}{"role_id": f.role, "active": false})
+
+	if after, _ := f.repo.Version(); after <= before {
+		t.Fatalf("version %d after the change, want more than %d", after, before)
+	}
+	if f.c.snapshot != nil {
+		t.Fatal("the snapshot is kept after the change")
+	}
+	f.repo.Reads()
+
+	// the token of the old version is checked by the database.
+	f.expect(petURL, http.StatusForbidden)
+	if reads := f.repo.Reads(); reads["AdminGetRoleMap"] != 1 {
+		t.Fatalf("reads %v, want the roles read", reads)
+	}
+}
+
+func TestCheckStaleVersion(t *testing.T) {
+	f := newFixture(t)
+	stale := f.claims
+
+	f.post("/permission/addurl", map[string]interface{}{"role_id": f.role, "url": userURL})
+	f.post("/permission/removerelation", map[string]interface{}{"admin_id": adminID, "role_id": f.role})
+
+	// a token of the new version loads its snapshot.
+	f.claims = f.grant()
+	f.expect(userURL, http.StatusForbidden)
+	if f.c.snapshot == nil || f.c.snapshot.version != f.claims.PermissionsVersion {
+		t.Fatal("the snapshot of the new version is not loaded")
+	}
+	f.repo.Reads()
+
+	// the stale token still claims the role, but the database doesn't.
+	f.claims = stale
+	f.expect(petURL, http.StatusForbidden)
+	f.expect(userURL, http.StatusForbidden)
+	if reads := f.repo.Reads(); reads["AdminGetRoleMap"] != 2 || reads["Permissions"] != 0 {
+		t.Fatalf("reads %v, want the roles read without the snapshot", reads)
+	}
+}
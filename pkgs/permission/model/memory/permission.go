@@ -0,0 +1,347 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abserari/shower/pkgs/permission/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+var (
+	errDuplicateName       = errs.Conflict("role already exists")
+	errDuplicatePermission = errs.Conflict("permission already exists")
+	errDuplicateRelation   = errs.Conflict("relation already exists")
+)
+
+type relation struct {
+	adminID uint32
+	roleID  uint32
+}
+
+type permission struct {
+	url    string
+	roleID uint32
+}
+
+// Repository stores the roles, permissions and relations in memory,
+// it's used in development and tests.
+//
+// It doesn't know the admins, so GetAdminIDMap doesn't filter out the
+// inactive admins as MySQL does.
+type Repository struct {
+	mu          sync.RWMutex
+	nextID      uint32
+	roles       map[uint32]*model.Role
+	permissions map[permission]time.Time
+	relations   map[relation]time.Time
+	// version of the permissions, increased by every change.
+	version uint64
+}
+
+// NewRepository creates an empty Repository.
+func NewRepository() *Repository {
+	return &Repository{
+		nextID:      1000,
+		roles:       make(map[uint32]*model.Role),
+		permissions: make(map[permission]time.Time),
+		relations:   make(map[relation]time.Time),
+		version:     1,
+	}
+}
+
+func (r *Repository) nameUsed(id uint32, name string) bool {
+	for _, role := range r.roles {
+		if role.RoleID != id && role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateRole create a new role information.
+func (r *Repository) CreateRole(name, intro *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameUsed(0, *name) {
+		return errDuplicateName
+	}
+
+	r.roles[r.nextID] = &model.Role{
+		RoleID:   r.nextID,
+		Name:     *name,
+		Intro:    *intro,
+		Active:   true,
+		CreateAt: time.Now().Format(time.RFC3339),
+	}
+	r.nextID++
+
+	return nil
+}
+
+// ModifyRole modify role information.
+func (r *Repository) ModifyRole(id uint32, name, intro *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	role, ok := r.roles[id]
+	if !ok {
+		return nil
+	}
+
+	if r.nameUsed(id, *name) {
+		return errDuplicateName
+	}
+
+	role.Name = *name
+	role.Intro = *intro
+	return nil
+}
+
+// ModifyRoleActive modify role active.
+func (r *Repository) ModifyRoleActive(id uint32, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if role, ok := r.roles[id]; ok {
+		role.Active = active
+		r.version++
+	}
+
+	return nil
+}
+
+// ModifyRoleTwoFactor modify whether the role requires the second factor.
+func (r *Repository) ModifyRoleTwoFactor(id uint32, required bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if role, ok := r.roles[id]; ok {
+		role.TwoFactor = required
+		r.version++
+	}
+
+	return nil
+}
+
+// RoleList get all role information.
+func (r *Repository) RoleList() ([]*model.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var roles []*model.Role
+	for _, role := range r.roles {
+		copied := *role
+		roles = append(roles, &copied)
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].RoleID < roles[j].RoleID })
+	return roles, nil
+}
+
+// GetRoleByID get an active role by id.
+func (r *Repository) GetRoleByID(id uint32) (*model.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	role, ok := r.roles[id]
+	if !ok || !role.Active {
+		return &model.Role{}, model.ErrRoleNotFound
+	}
+
+	copied := *role
+	return &copied, nil
+}
+
+// TwoFactorRoles lists the active roles requiring the second factor.
+func (r *Repository) TwoFactorRoles() (map[uint32]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[uint32]bool)
+	for id, role := range r.roles {
+		if role.Active && role.TwoFactor {
+			result[id] = true
+		}
+	}
+	return result, nil
+}
+
+func (r *Repository) isActive(id uint32) bool {
+	role, ok := r.roles[id]
+	return ok && role.Active
+}
+
+// AddURLPermission allows the role to access url.
+func (r *Repository) AddURLPermission(rid uint32, url string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.isActive(rid) {
+		return model.ErrRoleInactive
+	}
+
+	p := permission{url: url, roleID: rid}
+	if _, ok := r.permissions[p]; ok {
+		return errDuplicatePermission
+	}
+
+	r.permissions[p] = time.Now()
+	r.version++
+	return nil
+}
+
+// RemoveURLPermission stops the role accessing url.
+func (r *Repository) RemoveURLPermission(rid uint32, url string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.isActive(rid) {
+		return model.ErrRoleInactive
+	}
+
+	delete(r.permissions, permission{url: url, roleID: rid})
+	r.version++
+	return nil
+}
+
+// URLPermissions lists the active roles of the specified URL.
+func (r *Repository) URLPermissions(url *string) (map[uint32]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[uint32]bool)
+	for p := range r.permissions {
+		if p.url == *url && r.isActive(p.roleID) {
+			result[p.roleID] = true
+		}
+	}
+
+	return result, nil
+}
+
+// Permissions lists all the permissions.
+func (r *Repository) Permissions() (*[]*model.Permission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*model.Permission
+	for p, createdAt := range r.permissions {
+		result = append(result, &model.Permission{
+			URL:       p.url,
+			RoleID:    p.roleID,
+			CreatedAt: createdAt.Format(time.RFC3339),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].URL != result[j].URL {
+			return result[i].URL < result[j].URL
+		}
+		return result[i].RoleID < result[j].RoleID
+	})
+	return &result, nil
+}
+
+// AddRelation add an relation
+func (r *Repository) AddRelation(aid, rid uint32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.isActive(rid) {
+		return model.ErrRoleInactive
+	}
+
+	rel := relation{adminID: aid, roleID: rid}
+	if _, ok := r.relations[rel]; ok {
+		return errDuplicateRelation
+	}
+
+	r.relations[rel] = time.Now()
+	r.version++
+	return nil
+}
+
+// RemoveRelation delate an relation
+func (r *Repository) RemoveRelation(aid, rid uint32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.relations, relation{adminID: aid, roleID: rid})
+	r.version++
+	return nil
+}
+
+// AdminGetRoleMap lists the active roles of the specified admin.
+func (r *Repository) AdminGetRoleMap(aid uint32) (map[uint32]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[uint32]bool)
+	for rel := range r.relations {
+		if rel.adminID == aid && r.isActive(rel.roleID) {
+			result[rel.roleID] = true
+		}
+	}
+
+	return result, nil
+}
+
+// AssociatedRoleList lists the active roles of the specified admin.
+func (r *Repository) AssociatedRoleList(aid uint32) ([]*model.RelationData, error) {
+	roles, err := r.AdminGetRoleMap(aid)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*model.RelationData
+	for rid := range roles {
+		result = append(result, &model.RelationData{
+			AdminID: aid,
+			RoleID:  rid,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].RoleID < result[j].RoleID })
+	return result, nil
+}
+
+// GetAdminIDMap lists the admins having an active role.
+func (r *Repository) GetAdminIDMap() (map[uint32]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[uint32]bool)
+	for rel := range r.relations {
+		if r.isActive(rel.roleID) {
+			result[rel.adminID] = true
+		}
+	}
+
+	return result, nil
+}
+
+// GetRoleIDMap lists the active roles having an admin.
+func (r *Repository) GetRoleIDMap() (map[uint32]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[uint32]bool)
+	for rel := range r.relations {
+		if r.isActive(rel.roleID) {
+			result[rel.roleID] = true
+		}
+	}
+
+	return result, nil
+}
+
+// Version is the version of the permissions.
+func (r *Repository) Version() (uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.version, nil
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"github.com/abserari/shower/utils/errs"
+)
+
+var (
+	// ErrRoleNotFound is returned if there's no such role.
+	ErrRoleNotFound = errs.NotFound("role not found")
+	// ErrRoleInactive is returned if the role is deactivated.
+	ErrRoleInactive = errs.Inactive("the role is not activated")
+)
+
+type (
+	//Role -
+	Role struct {
+		RoleID   uint32
+		Name     string
+		Intro    string
+		Active   bool
+		CreateAt string
+		// TwoFactor requires the admins of the role to login by the second factor.
+		TwoFactor bool
+	}
+	//Permission -
+	Permission struct {
+		URL       string
+		RoleID    uint32
+		CreatedAt string
+	}
+	//RelationData -
+	RelationData struct {
+		AdminID uint32
+		RoleID  uint32
+	}
+)
+
+// Repository stores the roles, the URLs a role could access, and the roles of an admin.
+type Repository interface {
+	CreateRole(name, intro *string) error
+	ModifyRole(id uint32, name, intro *string) error
+	ModifyRoleActive(id uint32, active bool) error
+	ModifyRoleTwoFactor(id uint32, required bool) error
+	RoleList() ([]*Role, error)
+	// GetRoleByID get an active role by id.
+	GetRoleByID(id uint32) (*Role, error)
+	// TwoFactorRoles lists the active roles requiring the second factor.
+	TwoFactorRoles() (map[uint32]bool, error)
+
+	// AddURLPermission and RemoveURLPermission fail if the role is inactive.
+	AddURLPermission(rid uint32, url string) error
+	RemoveURLPermission(rid uint32, url string) error
+	// URLPermissions lists the active roles of the specified URL.
+	URLPermissions(url *string) (map[uint32]bool, error)
+	Permissions() (*[]*Permission, error)
+
+	// AddRelation fails if the role is inactive.
+	AddRelation(aid, rid uint32) error
+	RemoveRelation(aid, rid uint32) error
+	// AdminGetRoleMap lists the active roles of the specified admin.
+	AdminGetRoleMap(aid uint32) (map[uint32]bool, error)
+	AssociatedRoleList(aid uint32) ([]*RelationData, error)
+	// GetAdminIDMap lists the admins having an active role.
+	GetAdminIDMap() (map[uint32]bool, error)
+	// GetRoleIDMap lists the active roles having an admin.
+	GetRoleIDMap() (map[uint32]bool, error)
+
+	// Version is the version of the permissions from 1, it's increased by
+	// every change of the active roles, their URLs or their admins.
+	Version() (uint64, error)
+}
@@ -9,28 +9,19 @@ import (
 	"database/sql"
 	"errors"
 	"time"
+
+	"github.com/abserari/shower/pkgs/permission/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 )
 
 type (
 	//Role -
-	Role struct {
-		RoleID   uint32
-		Name     string
-		Intro    string
-		Active   bool
-		CreateAt string
-	}
+	Role = model.Role
 	//Permission -
-	Permission struct {
-		URL       string
-		RoleID    uint32
-		CreatedAt string
-	}
+	Permission = model.Permission
 	//RelationData -
-	RelationData struct {
-		AdminID uint32
-		RoleID  uint32
-	}
+	RelationData = model.RelationData
 )
 
 const (
@@ -41,6 +32,11 @@ const (
 	mysqlRoleGetList
 	mysqlRoleGetByID
 	mysqlRoleGetIsActive
+	mysqlRoleDropTable
+	mysqlRoleAddTwoFactor
+	mysqlRoleDropTwoFactor
+	mysqlRoleModifyTwoFactor
+	mysqlRoleGetTwoFactor
 )
 
 const (
@@ -49,6 +45,7 @@ const (
 	mysqlPermissionDelete
 	mysqlPermissonGetRole
 	mysqlPermissonGetAll
+	mysqlPermissionDropTable
 )
 
 const (
@@ -58,11 +55,12 @@ const (
 	mysqlRelationRoleMap
 	mysqlRelationSelectAdmin
 	mysqlRelationSelectRole
+	mysqlRelationDropTable
 )
 
 var (
 	errInvalidMysql  = errors.New("affected 0 rows")
-	errRoleInactive  = errors.New("the role is not activated")
+	errRoleInactive  = model.ErrRoleInactive
 
 	roleSQLString = []string{
 		`CREATE TABLE IF NOT EXISTS role (
@@ -76,9 +74,14 @@ var (
 		`INSERT INTO role(name,intro,active) VALUES (?,?,?)`,
 		`UPDATE role SET name = ?,intro = ? WHERE role_id = ? LIMIT 1`,
 		`UPDATE role SET active = ? WHERE role_id = ? LIMIT 1`,
-		`SELECT * FROM role LOCK IN SHARE MODE`,
-		`SELECT * FROM role WHERE role_id = ? AND active = true LOCK IN SHARE MODE`,
+		`SELECT role_id,name,intro,active,created_at,two_factor FROM role LOCK IN SHARE MODE`,
+		`SELECT role_id,name,intro,active,created_at,two_factor FROM role WHERE role_id = ? AND active = true LOCK IN SHARE MODE`,
 		`SELECT active FROM role WHERE role_id = ? LOCK IN SHARE MODE`,
+		`DROP TABLE IF EXISTS role`,
+		`ALTER TABLE role ADD COLUMN two_factor BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE role DROP COLUMN two_factor`,
+		`UPDATE role SET two_factor = ? WHERE role_id = ? LIMIT 1`,
+		`SELECT role_id FROM role WHERE two_factor = true AND active = true LOCK IN SHARE MODE`,
 	}
 
 	permissionSQLString = []string{
@@ -92,6 +95,7 @@ var (
 		`DELETE FROM permission WHERE role_id = ? AND url = ? LIMIT 1`,
 		`SELECT permission.role_id FROM permission, role WHERE url = ? AND role.active = true AND permission.role_id = role.role_id LOCK IN SHARE MODE`,
 		`SELECT * FROM permission LOCK IN SHARE MODE`,
+		`DROP TABLE IF EXISTS permission`,
 	}
 
 	relationSQLString = []string{
@@ -106,9 +110,45 @@ var (
 		`SELECT relation.role_id FROM relation, role WHERE relation.admin_id = ? AND role.active = true AND relation.role_id = role.role_id LOCK IN SHARE MODE`,
 		`SELECT relation.admin_id FROM userAuth, relation,role WHERE relation.role_id = ? AND role.active = true AND userAuth.active AND relation.admin_id = userAuth.admin_id LOCK IN SHARE MODE`,
 		`SELECT relation.role_id FROM relation, role WHERE  role.active = true AND relation.role_id = role.role_id LOCK IN SHARE MODE`,
+		`DROP TABLE IF EXISTS relation`,
 	}
 )
 
+// Migrations of role, permission and relation table.
+func Migrations() []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version:     1,
+			Description: "create role, permission and relation table",
+			Up: []string{
+				roleSQLString[mysqlRoleCreateTable],
+				permissionSQLString[mysqlPermissionCreateTable],
+				relationSQLString[mysqlRelationCreateTable],
+			},
+			Down: []string{
+				relationSQLString[mysqlRelationDropTable],
+				permissionSQLString[mysqlPermissionDropTable],
+				roleSQLString[mysqlRoleDropTable],
+			},
+		},
+		{
+			Version:     2,
+			Description: "add two_factor to role table",
+			Up:          []string{roleSQLString[mysqlRoleAddTwoFactor]},
+			Down:        []string{roleSQLString[mysqlRoleDropTwoFactor]},
+		},
+		{
+			Version:     3,
+			Description: "create the table of the version of the permissions",
+			Up: []string{
+				versionSQLString[mysqlVersionCreateTable],
+				versionSQLString[mysqlVersionInsert],
+			},
+			Down: []string{versionSQLString[mysqlVersionDropTable]},
+		},
+	}
+}
+
 // CreateTable create role table.
 func CreateTable(db *sql.DB) error {
 	_, err := db.Exec(roleSQLString[mysqlRoleCreateTable])
@@ -133,7 +173,7 @@ func CreateTable(db *sql.DB) error {
 func CreateRole(db *sql.DB, name, intro *string) error {
 	result, err := db.Exec(roleSQLString[mysqlRoleInsert], name, intro, true)
 	if err != nil {
-		return err
+		return errs.FromDB(err, "role")
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
@@ -147,7 +187,7 @@ func CreateRole(db *sql.DB, name, intro *string) error {
 func ModifyRole(db *sql.DB, id uint32, name, intro *string) error {
 	_, err := db.Exec(roleSQLString[mysqlRoleModify], name, intro, id)
 
-	return err
+	return errs.FromDB(err, "role")
 }
 
 // ModifyRoleActive modify role active.
@@ -160,12 +200,13 @@ func ModifyRoleActive(db *sql.DB, id uint32, active bool) error {
 // RoleList get all role information.
 func RoleList(db *sql.DB) ([]*Role, error) {
 	var (
-		roleid   uint32
-		name     string
-		intro    string
-		active   bool
-		createAt string
-		roles    []*Role
+		roleid    uint32
+		name      string
+		intro     string
+		active    bool
+		createAt  string
+		twoFactor bool
+		roles     []*Role
 	)
 
 	rows, err := db.Query(roleSQLString[mysqlRoleGetList])
@@ -175,16 +216,17 @@ func RoleList(db *sql.DB) ([]*Role, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		if err := rows.Scan(&roleid, &name, &intro, &active, &createAt); err != nil {
+		if err := rows.Scan(&roleid, &name, &intro, &active, &createAt, &twoFactor); err != nil {
 			return nil, err
 		}
 
 		r := &Role{
-			RoleID:   roleid,
-			Name:     name,
-			Intro:    intro,
-			Active:   active,
-			CreateAt: createAt,
+			RoleID:    roleid,
+			Name:      name,
+			Intro:     intro,
+			Active:    active,
+			CreateAt:  createAt,
+			TwoFactor: twoFactor,
 		}
 
 		roles = append(roles, r)
@@ -199,10 +241,43 @@ func GetRoleByID(db *sql.DB, id uint32) (*Role, error) {
 		r Role
 	)
 
-	err := db.QueryRow(roleSQLString[mysqlRoleGetByID], id).Scan(&r.RoleID, &r.Name, &r.Intro, &r.Active, &r.CreateAt)
+	err := db.QueryRow(roleSQLString[mysqlRoleGetByID], id).Scan(&r.RoleID, &r.Name, &r.Intro, &r.Active, &r.CreateAt, &r.TwoFactor)
+	if err == sql.ErrNoRows {
+		return &r, model.ErrRoleNotFound
+	}
 	return &r, err
 }
 
+// ModifyRoleTwoFactor modify whether the role requires the second factor.
+func ModifyRoleTwoFactor(db *sql.DB, id uint32, required bool) error {
+	_, err := db.Exec(roleSQLString[mysqlRoleModifyTwoFactor], required, id)
+
+	return err
+}
+
+// TwoFactorRoles lists the active roles requiring the second factor.
+func TwoFactorRoles(db *sql.DB) (map[uint32]bool, error) {
+	var (
+		roleID uint32
+		result = make(map[uint32]bool)
+	)
+
+	rows, err := db.Query(roleSQLString[mysqlRoleGetTwoFactor])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&roleID); err != nil {
+			return nil, err
+		}
+		result[roleID] = true
+	}
+
+	return result, rows.Err()
+}
+
 // AddURLPermission -
 func AddURLPermission(db *sql.DB, rid uint32, url string) error {
 	roleIsActive, err := IsActive(db, rid)
@@ -215,7 +290,7 @@ func AddURLPermission(db *sql.DB, rid uint32, url string) error {
 	}
 
 	_, err = db.Exec(permissionSQLString[mysqlPermissionInstert], url, rid)
-	return err
+	return errs.FromDB(err, "permission")
 }
 
 // RemoveURLPermission -
@@ -300,7 +375,7 @@ func AddRelation(db *sql.DB, aid, rid uint32) error {
 
 	result, err := db.Exec(relationSQLString[mysqlRelationInsert], aid, rid, time.Now())
 	if err != nil {
-		return err
+		return errs.FromDB(err, "relation")
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
@@ -368,14 +443,17 @@ func AssociatedRoleList(db *sql.DB, aid uint32) ([]*RelationData, error) {
 	return result, nil
 }
 
-//IsActive return Active and nil if query success.
+//IsActive return Active and nil if query success, a role not exists is not active.
 func IsActive(db *sql.DB, id uint32) (bool, error) {
 	var (
 		isActive bool
 	)
 
-	db.QueryRow(roleSQLString[mysqlRoleGetIsActive], id).Scan(&isActive)
-	return isActive, nil
+	err := db.QueryRow(roleSQLString[mysqlRoleGetIsActive], id).Scan(&isActive)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return isActive, err
 }
 
 // GetAdminIDMap list all the roles of the specified userAuth and the return form is map.
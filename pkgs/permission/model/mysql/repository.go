@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/abserari/shower/utils/migrate"
+)
+
+// Repository stores the roles, permissions and relations in MySQL.
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a Repository in db.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Migrations returns the migrations of the tables.
+func (r *Repository) Migrations() []migrate.Migration {
+	return Migrations()
+}
+
+// CreateRole creates an active role.
+func (r *Repository) CreateRole(name, intro *string) error {
+	return CreateRole(r.db, name, intro)
+}
+
+// ModifyRole modifies the name and intro of the role.
+func (r *Repository) ModifyRole(id uint32, name, intro *string) error {
+	return ModifyRole(r.db, id, name, intro)
+}
+
+// ModifyRoleActive increases the version after modified.
+func (r *Repository) ModifyRoleActive(id uint32, active bool) error {
+	if err := ModifyRoleActive(r.db, id, active); err != nil {
+		return err
+	}
+	return IncreaseVersion(r.db)
+}
+
+// ModifyRoleTwoFactor increases the version after modified.
+func (r *Repository) ModifyRoleTwoFactor(id uint32, required bool) error {
+	if err := ModifyRoleTwoFactor(r.db, id, required); err != nil {
+		return err
+	}
+	return IncreaseVersion(r.db)
+}
+
+// RoleList lists every role.
+func (r *Repository) RoleList() ([]*Role, error) {
+	return RoleList(r.db)
+}
+
+// GetRoleByID returns ErrRoleNotFound if there's no such role.
+func (r *Repository) GetRoleByID(id uint32) (*Role, error) {
+	return GetRoleByID(r.db, id)
+}
+
+// TwoFactorRoles lists the active roles requiring the second factor.
+func (r *Repository) TwoFactorRoles() (map[uint32]bool, error) {
+	return TwoFactorRoles(r.db)
+}
+
+// AddURLPermission increases the version after added.
+func (r *Repository) AddURLPermission(rid uint32, url string) error {
+	if err := AddURLPermission(r.db, rid, url); err != nil {
+		return err
+	}
+	return IncreaseVersion(r.db)
+}
+
+// RemoveURLPermission increases the version after removed.
+func (r *Repository) RemoveURLPermission(rid uint32, url string) error {
+	if err := RemoveURLPermission(r.db, rid, url); err != nil {
+		return err
+	}
+	return IncreaseVersion(r.db)
+}
+
+// URLPermissions lists the active roles of url.
+func (r *Repository) URLPermissions(url *string) (map[uint32]bool, error) {
+	return URLPermissions(r.db, url)
+}
+
+// Permissions lists the URLs of the roles.
+func (r *Repository) Permissions() (*[]*Permission, error) {
+	return Permissions(r.db)
+}
+
+// AddRelation increases the version after added.
+func (r *Repository) AddRelation(aid, rid uint32) error {
+	if err := AddRelation(r.db, aid, rid); err != nil {
+		return err
+	}
+	return IncreaseVersion(r.db)
+}
+
+// RemoveRelation increases the version after removed.
+func (r *Repository) RemoveRelation(aid, rid uint32) error {
+	if err := RemoveRelation(r.db, aid, rid); err != nil {
+		return err
+	}
+	return IncreaseVersion(r.db)
+}
+
+// AdminGetRoleMap lists the active roles of the admin.
+func (r *Repository) AdminGetRoleMap(aid uint32) (map[uint32]bool, error) {
+	return AdminGetRoleMap(r.db, aid)
+}
+
+// AssociatedRoleList lists the relations of the admin to the roles.
+func (r *Repository) AssociatedRoleList(aid uint32) ([]*RelationData, error) {
+	return AssociatedRoleList(r.db, aid)
+}
+
+// GetAdminIDMap lists the admins having an active role.
+func (r *Repository) GetAdminIDMap() (map[uint32]bool, error) {
+	return GetAdminIDMap(r.db)
+}
+
+// GetRoleIDMap lists the active roles having an admin.
+func (r *Repository) GetRoleIDMap() (map[uint32]bool, error) {
+	return GetRoleIDMap(r.db)
+}
+
+// Version returns the version of the permissions.
+func (r *Repository) Version() (uint64, error) {
+	return Version(r.db)
+}
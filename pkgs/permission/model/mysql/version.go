@@ -0,0 +1,46 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	mysqlVersionCreateTable = iota
+	mysqlVersionInsert
+	mysqlVersionGet
+	mysqlVersionIncrease
+	mysqlVersionDropTable
+)
+
+var versionSQLString = []string{
+	`CREATE TABLE IF NOT EXISTS permission_version (
+		id			TINYINT UNSIGNED NOT NULL,
+		version		BIGINT UNSIGNED NOT NULL,
+		PRIMARY KEY (id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`,
+	`INSERT IGNORE INTO permission_version(id,version) VALUES (1,1)`,
+	`SELECT version FROM permission_version WHERE id = 1`,
+	`UPDATE permission_version SET version = version + 1 WHERE id = 1 LIMIT 1`,
+	`DROP TABLE IF EXISTS permission_version`,
+}
+
+// Version returns the version of the permissions.
+func Version(db *sql.DB) (uint64, error) {
+	var version uint64
+
+	err := db.QueryRow(versionSQLString[mysqlVersionGet]).Scan(&version)
+	if err != nil {
+		return 0, errs.FromDB(err, "permission version")
+	}
+
+	return version, nil
+}
+
+// IncreaseVersion increases the version of the permissions after a change.
+func IncreaseVersion(db *sql.DB) error {
+	_, err := db.Exec(versionSQLString[mysqlVersionIncrease])
+
+	return errs.FromDB(err, "permission version")
+}
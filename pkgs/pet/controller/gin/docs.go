@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/abserari/shower/utils/openapi"
+)
+
+// Operations describes the routes of the module in the OpenAPI document.
+func (b *PetController) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/create", Summary: "Create a pet", Request: createRequest{}, Response: createResponse{}},
+		{Method: http.MethodPost, Path: "/update/name", Summary: "Modify the name of a pet", Request: modifyNameRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/update/category", Summary: "Modify the category of a pet", Request: modifyCategoryRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/update/avatar", Summary: "Modify the avatar of a pet", Request: modifyAvatarRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/update/birthday", Summary: "Modify the birthday of a pet", Request: modifyBirthdayRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/update/medicalcurrent", Summary: "Modify the medical status of a pet", Request: modifyMedicalCurrentRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/update/hobbies", Summary: "Modify the hobbies of a pet", Request: modifyHobbiesRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/update/gender", Summary: "Modify the gender of a pet", Request: modifyGenderRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/delete", Summary: "Delete a pet", Request: deleteByIDRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/info/id", Summary: "Get a pet", Request: infoByIDRequest{}, Response: infoByIDResponse{}},
+		{Method: http.MethodPost, Path: "/list/adminid", Summary: "List the pets of an admin", Request: listPetByAdminIDRequest{}, Response: listPetByAdminIDResponse{}},
+	}
+}
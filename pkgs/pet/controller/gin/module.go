@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/abserari/shower/pkgs/module"
+	"github.com/abserari/shower/pkgs/pet/model/memory"
+	mysql "github.com/abserari/shower/pkgs/pet/model/mysql"
+)
+
+// ModuleName is the name of pet in config.
+const ModuleName = "pet"
+
+func init() {
+	module.Register(ModuleName, func(env *module.Env) (module.Module, error) {
+		if env.Memory() {
+			return New(memory.NewRepository()), nil
+		}
+		return New(mysql.NewRepository(env.DB, env.Module.Table)), nil
+	})
+}
+
+// Name returns the name of the module.
+func (b *PetController) Name() string { return ModuleName }
+
+// Dependencies is empty, the module works alone.
+func (b *PetController) Dependencies() []string { return nil }
+
+// Start does nothing, there is no background work.
+func (b *PetController) Start(ctx context.Context) error { return nil }
+
+// Shutdown has nothing to stop.
+func (b *PetController) Shutdown(ctx context.Context) error { return nil }
@@ -6,40 +6,39 @@
 package controller
 
 import (
-	"database/sql"
 	"log"
 	"net/http"
 	"time"
 
-	mysql "github.com/abserari/shower/pkgs/pet/model/mysql"
+	"github.com/abserari/shower/pkgs/pet/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 	"github.com/gin-gonic/gin"
 )
 
 // PetController -
 type PetController struct {
-	db        *sql.DB
-	tableName string
+	repo model.Repository
 }
 
-// New -
-func New(db *sql.DB, tableName string) *PetController {
+// New creates a controller of repo.
+func New(repo model.Repository) *PetController {
 	return &PetController{
-		db:        db,
-		tableName: tableName,
+		repo: repo,
 	}
 }
 
+// Migrations returns the migrations of the repository.
+func (b *PetController) Migrations() []migrate.Migration {
+	return migrate.Of(b.repo)
+}
+
 // RegisterRouter -
 func (b *PetController) RegisterRouter(r gin.IRouter) {
 	if r == nil {
 		log.Fatal("[InitRouter]: server is nil")
 	}
 
-	err := mysql.CreateTable(b.db, b.tableName)
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	r.POST("/create", b.create)
 
 	r.POST("/update/name", b.modifyName)
@@ -56,271 +55,271 @@ func (b *PetController) RegisterRouter(r gin.IRouter) {
 	r.POST("/list/adminid", b.listPetByAdminID)
 }
 
+type createRequest struct {
+	AdminID        uint64    `json:"adminID"    binding:"required"`
+	Name           string    `json:"name"      binding:"required"`
+	Category       string    `json:"category" `
+	Avatar         string    `json:"avatar" `
+	Birthday       time.Time `json:"birthday" `
+	MedicalCurrent string    `json:"medicalCurrent" `
+	Hobbies        string    `json:"hobbies" `
+	Gender         string    `json:"gender" `
+}
+
+type createResponse struct {
+	Status int `json:"status"`
+	ID     int `json:"ID"`
+}
+
 func (b *PetController) create(c *gin.Context) {
-	var (
-		req struct {
-			AdminID        uint64    `json:"adminID"    binding:"required"`
-			Name           string    `json:"name"      binding:"required"`
-			Category       string    `json:"category" `
-			Avatar         string    `json:"avatar" `
-			Birthday       time.Time `json:"birthday" `
-			MedicalCurrent string    `json:"medicalCurrent" `
-			Hobbies        string    `json:"hobbies" `
-			Gender         string    `json:"gender" `
-		}
-	)
+	var req createRequest
 
 	err := c.ShouldBind(&req)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	id, err := mysql.InsertPet(b.db, b.tableName, req.AdminID, req.Name, req.Category, req.Avatar, req.Birthday, req.MedicalCurrent, req.Hobbies, req.Gender)
+	id, err := b.repo.Insert(req.AdminID, req.Name, req.Category, req.Avatar, req.Birthday, req.MedicalCurrent, req.Hobbies, req.Gender)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "ID": id})
+	c.JSON(http.StatusOK, createResponse{Status: http.StatusOK, ID: id})
+}
+
+type listPetByAdminIDRequest struct {
+	AdminID uint64 `json:"adminID"    binding:"required"`
+}
+
+type listPetByAdminIDResponse struct {
+	Status int          `json:"status"`
+	Pets   []*model.Pet `json:"pets"`
 }
 
 func (b *PetController) listPetByAdminID(c *gin.Context) {
-	var (
-		req struct {
-			AdminID uint64 `json:"adminID"    binding:"required"`
-		}
-	)
+	var req listPetByAdminIDRequest
 
 	err := c.ShouldBind(&req)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	pets, err := mysql.ListPetByAdminID(b.db, b.tableName, req.AdminID)
+	pets, err := b.repo.ListByAdminID(req.AdminID)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "pets": pets})
+	c.JSON(http.StatusOK, listPetByAdminIDResponse{Status: http.StatusOK, Pets: pets})
+}
+
+type infoByIDRequest struct {
+	ID uint64 `json:"id"     binding:"required"`
+}
+
+type infoByIDResponse struct {
+	Status int        `json:"status"`
+	Ban    *model.Pet `json:"ban"`
 }
 
 func (b *PetController) infoByID(c *gin.Context) {
-	var (
-		req struct {
-			ID uint64 `json:"id"     binding:"required"`
-		}
-	)
+	var req infoByIDRequest
 
 	err := c.ShouldBind(&req)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	ban, err := mysql.InfoByID(b.db, b.tableName, req.ID)
+	ban, err := b.repo.InfoByID(req.ID)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "ban": ban})
+	c.JSON(http.StatusOK, infoByIDResponse{Status: http.StatusOK, Ban: ban})
 }
+
+type modifyNameRequest struct {
+	PetID uint64 `json:"petID"    binding:"required"`
+	Name  string `json:"name"       binding:"required"`
+}
+
 func (con *PetController) modifyName(ctx *gin.Context) {
-	var (
-		admin struct {
-			PetID uint64 `json:"petID"    binding:"required"`
-			Name  string `json:"name"       binding:"required,email"`
-		}
-	)
+	var admin modifyNameRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyName(con.db, admin.PetID, admin.Name)
+	err = con.repo.ModifyName(admin.PetID, admin.Name)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type modifyCategoryRequest struct {
+	PetID    uint64 `json:"petID"    binding:"required"`
+	Category string `json:"category"  binding:"required"`
+}
+
 func (con *PetController) modifyCategory(ctx *gin.Context) {
-	var (
-		admin struct {
-			PetID    uint64 `json:"petID"    binding:"required"`
-			Category string `json:"category"  binding:"required,email"`
-		}
-	)
+	var admin modifyCategoryRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyCategory(con.db, admin.PetID, admin.Category)
+	err = con.repo.ModifyCategory(admin.PetID, admin.Category)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
+
+type modifyAvatarRequest struct {
+	PetID  uint64 `json:"petID"     binding:"required"`
+	Avatar string `json:"avatar"       binding:"required"`
+}
+
 func (con *PetController) modifyAvatar(ctx *gin.Context) {
-	var (
-		admin struct {
-			PetID  uint64 `json:"petID"     binding:"required"`
-			Avatar string `json:"avatar"       binding:"required,email"`
-		}
-	)
+	var admin modifyAvatarRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyAvatar(con.db, admin.PetID, admin.Avatar)
+	err = con.repo.ModifyAvatar(admin.PetID, admin.Avatar)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
+
+type modifyBirthdayRequest struct {
+	PetID    uint64    `json:"petID"    binding:"required"`
+	Birthday time.Time `json:"birthday"     binding:"required"`
+}
+
 func (con *PetController) modifyBirthday(ctx *gin.Context) {
-	var (
-		admin struct {
-			PetID    uint64    `json:"petID"    binding:"required"`
-			Birthday time.Time `json:"birthday"     binding:"required,email"`
-		}
-	)
+	var admin modifyBirthdayRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyBirthday(con.db, admin.PetID, admin.Birthday)
+	err = con.repo.ModifyBirthday(admin.PetID, admin.Birthday)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
+
+type modifyMedicalCurrentRequest struct {
+	PetID          uint64 `json:"petID"    binding:"required"`
+	MedicalCurrent string `json:"medicalCurrent"     binding:"required"`
+}
+
 func (con *PetController) modifyMedicalCurrent(ctx *gin.Context) {
-	var (
-		admin struct {
-			PetID          uint64 `json:"petID"    binding:"required"`
-			MedicalCurrent string `json:"medicalCurrent"     binding:"required,email"`
-		}
-	)
+	var admin modifyMedicalCurrentRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyMedicalCurrent(con.db, admin.PetID, admin.MedicalCurrent)
+	err = con.repo.ModifyMedicalCurrent(admin.PetID, admin.MedicalCurrent)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
+
+type modifyHobbiesRequest struct {
+	PetID   uint64 `json:"petID"    binding:"required"`
+	Hobbies string `json:"hobbies"    binding:"required"`
+}
+
 func (con *PetController) modifyHobbies(ctx *gin.Context) {
-	var (
-		admin struct {
-			PetID   uint64 `json:"petID"    binding:"required"`
-			Hobbies string `json:"hobbies"    binding:"required,email"`
-		}
-	)
+	var admin modifyHobbiesRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyHobbies(con.db, admin.PetID, admin.Hobbies)
+	err = con.repo.ModifyHobbies(admin.PetID, admin.Hobbies)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
+
+type modifyGenderRequest struct {
+	PetID  uint64 `json:"petID"    binding:"required"`
+	Gender string `json:"gender"     binding:"required"`
+}
+
 func (con *PetController) modifyGender(ctx *gin.Context) {
-	var (
-		admin struct {
-			PetID  uint64 `json:"petID"    binding:"required"`
-			Gender string `json:"gender"     binding:"required,email"`
-		}
-	)
+	var admin modifyGenderRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyGender(con.db, admin.PetID, admin.Gender)
+	err = con.repo.ModifyGender(admin.PetID, admin.Gender)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
+
+type deleteByIDRequest struct {
+	ID uint64 `json:"id"    binding:"required"`
+}
+
 func (b *PetController) deleteByID(c *gin.Context) {
-	var (
-		req struct {
-			ID uint64 `json:"id"    binding:"required"`
-		}
-	)
+	var req deleteByIDRequest
 
 	err := c.ShouldBind(&req)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.DeleteByID(b.db, b.tableName, req.ID)
+	err = b.repo.DeleteByID(req.ID)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
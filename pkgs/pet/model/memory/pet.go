@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abserari/shower/pkgs/pet/model"
+)
+
+// Repository stores the pets in memory, it's used in development and tests.
+type Repository struct {
+	mu     sync.RWMutex
+	nextID uint64
+	pets   map[uint64]*model.Pet
+}
+
+// NewRepository creates an empty Repository.
+func NewRepository() *Repository {
+	return &Repository{
+		nextID: 1000000,
+		pets:   make(map[uint64]*model.Pet),
+	}
+}
+
+// Insert return id
+func (r *Repository) Insert(adminID uint64, name, category, avatar string, birthday time.Time, medicalCurrent, hobbies, gender string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+
+	r.pets[id] = &model.Pet{
+		PetID:          id,
+		AdminID:        adminID,
+		Name:           name,
+		Category:       category,
+		Avatar:         avatar,
+		Birthday:       birthday,
+		MedicalCurrent: medicalCurrent,
+		Hobbies:        hobbies,
+		Gender:         gender,
+	}
+
+	return int(id), nil
+}
+
+// ListByAdminID lists the pets of the admin.
+func (r *Repository) ListByAdminID(adminID uint64) ([]*model.Pet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var pets []*model.Pet
+	for _, p := range r.pets {
+		if p.AdminID == adminID {
+			pet := *p
+			pets = append(pets, &pet)
+		}
+	}
+
+	sort.Slice(pets, func(i, j int) bool { return pets[i].PetID < pets[j].PetID })
+	return pets, nil
+}
+
+// InfoByID returns ErrNotFound if there's no such pet.
+func (r *Repository) InfoByID(id uint64) (*model.Pet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.pets[id]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+
+	pet := *p
+	return &pet, nil
+}
+
+func (r *Repository) modify(id uint64, fn func(p *model.Pet)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pets[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	fn(p)
+	return nil
+}
+
+// ModifyName modifies the name of the pet.
+func (r *Repository) ModifyName(id uint64, name string) error {
+	return r.modify(id, func(p *model.Pet) { p.Name = name })
+}
+
+// ModifyCategory modifies the category of the pet.
+func (r *Repository) ModifyCategory(id uint64, category string) error {
+	return r.modify(id, func(p *model.Pet) { p.Category = category })
+}
+
+// ModifyAvatar modifies the avatar of the pet.
+func (r *Repository) ModifyAvatar(id uint64, avatar string) error {
+	return r.modify(id, func(p *model.Pet) { p.Avatar = avatar })
+}
+
+// ModifyBirthday modifies the birthday of the pet.
+func (r *Repository) ModifyBirthday(id uint64, birthday time.Time) error {
+	return r.modify(id, func(p *model.Pet) { p.Birthday = birthday })
+}
+
+// ModifyMedicalCurrent modifies the current medical record of the pet.
+func (r *Repository) ModifyMedicalCurrent(id uint64, medicalCurrent string) error {
+	return r.modify(id, func(p *model.Pet) { p.MedicalCurrent = medicalCurrent })
+}
+
+// ModifyHobbies modifies the hobbies of the pet.
+func (r *Repository) ModifyHobbies(id uint64, hobbies string) error {
+	return r.modify(id, func(p *model.Pet) { p.Hobbies = hobbies })
+}
+
+// ModifyGender modifies the gender of the pet.
+func (r *Repository) ModifyGender(id uint64, gender string) error {
+	return r.modify(id, func(p *model.Pet) { p.Gender = gender })
+}
+
+// DeleteByID delete by id
+func (r *Repository) DeleteByID(id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.pets[id]; !ok {
+		return model.ErrNotFound
+	}
+
+	delete(r.pets, id)
+	return nil
+}
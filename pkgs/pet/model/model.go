@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/abserari/shower/utils/errs"
+)
+
+// ErrNotFound is returned if there's no such pet.
+var ErrNotFound = errs.NotFound("pet not found")
+
+// Pet -
+type Pet struct {
+	PetID          uint64
+	AdminID        uint64
+	Name           string
+	Category       string
+	Avatar         string
+	Birthday       time.Time
+	MedicalCurrent string
+	Hobbies        string
+	Gender         string
+}
+
+// Repository stores the pets.
+type Repository interface {
+	// Insert return id
+	Insert(adminID uint64, name, category, avatar string, birthday time.Time, medicalCurrent, hobbies, gender string) (int, error)
+	ListByAdminID(adminID uint64) ([]*Pet, error)
+	InfoByID(id uint64) (*Pet, error)
+	ModifyName(id uint64, name string) error
+	ModifyCategory(id uint64, category string) error
+	ModifyAvatar(id uint64, avatar string) error
+	ModifyBirthday(id uint64, birthday time.Time) error
+	ModifyMedicalCurrent(id uint64, medicalCurrent string) error
+	ModifyHobbies(id uint64, hobbies string) error
+	ModifyGender(id uint64, gender string) error
+	DeleteByID(id uint64) error
+}
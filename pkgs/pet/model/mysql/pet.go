@@ -10,20 +10,13 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/abserari/shower/pkgs/pet/model"
+	"github.com/abserari/shower/utils/migrate"
 )
 
-// Pet -
-type Pet struct {
-	PetID          uint64
-	AdminID        uint64
-	Name           string
-	Category       string
-	Avatar         string
-	Birthday       time.Time
-	MedicalCurrent string
-	Hobbies        string
-	Gender         string
-}
+// Pet is the pet of the model.
+type Pet = model.Pet
 
 const (
 	mysqlPetCreateTable = iota
@@ -38,6 +31,7 @@ const (
 	mysqlPetUpdateHobbiesByID
 	mysqlPetUpdateGenderByID
 	mysqlPetDeleteByID
+	mysqlPetDropTable
 )
 
 var (
@@ -67,9 +61,22 @@ PRIMARY KEY (petID)
 		`UPDATE %s SET hobbies=? WHERE petID = ? LIMIT 1`,
 		`UPDATE %s SET gender=? WHERE petID = ? LIMIT 1`,
 		`DELETE FROM %s WHERE petID = ? LIMIT 1`,
+		`DROP TABLE IF EXISTS %s`,
 	}
 )
 
+// Migrations returns the migrations creating the tables.
+func Migrations(tableName string) []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version:     1,
+			Description: "create pet table",
+			Up:          []string{fmt.Sprintf(petSQLString[mysqlPetCreateTable], tableName)},
+			Down:        []string{fmt.Sprintf(petSQLString[mysqlPetDropTable], tableName)},
+		},
+	}
+}
+
 // CreateTable -
 func CreateTable(db *sql.DB, tableName string) error {
 	sql := fmt.Sprintf(petSQLString[mysqlPetCreateTable], tableName)
@@ -153,106 +160,118 @@ func InfoByID(db *sql.DB, tableName string, id uint64) (*Pet, error) {
 	}
 	defer rows.Close()
 
-	for rows.Next() {
-		if err := rows.Scan(&pet.PetID, &pet.AdminID, &pet.Name, &pet.Category, &pet.Avatar, &pet.Birthday, &pet.MedicalCurrent, &pet.Hobbies, &pet.Gender); err != nil {
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
 			return nil, err
 		}
+		return nil, model.ErrNotFound
+	}
+
+	if err := rows.Scan(&pet.PetID, &pet.AdminID, &pet.Name, &pet.Category, &pet.Avatar, &pet.Birthday, &pet.MedicalCurrent, &pet.Hobbies, &pet.Gender); err != nil {
+		return nil, err
 	}
 
 	return &pet, nil
 }
 
 // ModifyEmail the administrative userAuth updates email
-func ModifyName(db *sql.DB, id uint64, name string) error {
-	result, err := db.Exec(petSQLString[mysqlPetUpdateNameByID], name, id)
+func ModifyName(db *sql.DB, tableName string, id uint64, name string) error {
+	sql := fmt.Sprintf(petSQLString[mysqlPetUpdateNameByID], tableName)
+	result, err := db.Exec(sql, name, id)
 	if err != nil {
 		return err
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidNoRowsAffected
+		return model.ErrNotFound
 	}
 
 	return nil
 }
 
 // ModifyEmail the administrative userAuth updates email
-func ModifyCategory(db *sql.DB, id uint64, category string) error {
-	result, err := db.Exec(petSQLString[mysqlPetUpdateCategoryByID], category, id)
+func ModifyCategory(db *sql.DB, tableName string, id uint64, category string) error {
+	sql := fmt.Sprintf(petSQLString[mysqlPetUpdateCategoryByID], tableName)
+	result, err := db.Exec(sql, category, id)
 	if err != nil {
 		return err
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidNoRowsAffected
+		return model.ErrNotFound
 	}
 
 	return nil
 } // ModifyAvatar the administrative userAuth updates email
-func ModifyAvatar(db *sql.DB, id uint64, avatar string) error {
-	result, err := db.Exec(petSQLString[mysqlPetUpdateAvatarByID], avatar, id)
+func ModifyAvatar(db *sql.DB, tableName string, id uint64, avatar string) error {
+	sql := fmt.Sprintf(petSQLString[mysqlPetUpdateAvatarByID], tableName)
+	result, err := db.Exec(sql, avatar, id)
 	if err != nil {
 		return err
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidNoRowsAffected
+		return model.ErrNotFound
 	}
 
 	return nil
 }
 
 // ModifyEmail the administrative userAuth updates email
-func ModifyBirthday(db *sql.DB, id uint64, birthday time.Time) error {
-	result, err := db.Exec(petSQLString[mysqlPetUpdateBirthdayByID], birthday, id)
+func ModifyBirthday(db *sql.DB, tableName string, id uint64, birthday time.Time) error {
+	sql := fmt.Sprintf(petSQLString[mysqlPetUpdateBirthdayByID], tableName)
+	result, err := db.Exec(sql, birthday, id)
 	if err != nil {
 		return err
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidNoRowsAffected
+		return model.ErrNotFound
 	}
 
 	return nil
 }
 
 // ModifyEmail the administrative userAuth updates email
-func ModifyMedicalCurrent(db *sql.DB, id uint64, MedicalCurrent string) error {
-	result, err := db.Exec(petSQLString[mysqlPetUpdateMedicalCurrentByID], MedicalCurrent, id)
+func ModifyMedicalCurrent(db *sql.DB, tableName string, id uint64, MedicalCurrent string) error {
+	sql := fmt.Sprintf(petSQLString[mysqlPetUpdateMedicalCurrentByID], tableName)
+	result, err := db.Exec(sql, MedicalCurrent, id)
 	if err != nil {
 		return err
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidNoRowsAffected
+		return model.ErrNotFound
 	}
 
 	return nil
 }
 
 // ModifyEmail the administrative userAuth updates email
-func ModifyHobbies(db *sql.DB, id uint64, hobbies string) error {
-	result, err := db.Exec(petSQLString[mysqlPetUpdateHobbiesByID], hobbies, id)
+func ModifyHobbies(db *sql.DB, tableName string, id uint64, hobbies string) error {
+	sql := fmt.Sprintf(petSQLString[mysqlPetUpdateHobbiesByID], tableName)
+	result, err := db.Exec(sql, hobbies, id)
 	if err != nil {
 		return err
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidNoRowsAffected
+		return model.ErrNotFound
 	}
 
 	return nil
 }
 
 // ModifyEmail the administrative userAuth updates email
-func ModifyGender(db *sql.DB, id uint64, gender string) error {
-	result, err := db.Exec(petSQLString[mysqlPetUpdateGenderByID], gender, id)
+func ModifyGender(db *sql.DB, tableName string, id uint64, gender string) error {
+	sql := fmt.Sprintf(petSQLString[mysqlPetUpdateGenderByID], tableName)
+	result, err := db.Exec(sql, gender, id)
 	if err != nil {
 		return err
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidNoRowsAffected
+		return model.ErrNotFound
 	}
 
 	return nil
@@ -261,6 +280,14 @@ func ModifyGender(db *sql.DB, id uint64, gender string) error {
 // DeleteByID delete by id
 func DeleteByID(db *sql.DB, tableName string, id uint64) error {
 	sql := fmt.Sprintf(petSQLString[mysqlPetDeleteByID], tableName)
-	_, err := db.Exec(sql, id)
-	return err
+	result, err := db.Exec(sql, id)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
 }
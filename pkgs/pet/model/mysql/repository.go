@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/abserari/shower/utils/migrate"
+)
+
+// Repository stores the pets in a MySQL table.
+type Repository struct {
+	db        *sql.DB
+	tableName string
+}
+
+// NewRepository creates a Repository of the table tableName in db.
+func NewRepository(db *sql.DB, tableName string) *Repository {
+	return &Repository{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+// Migrations returns the migrations of the tables.
+func (r *Repository) Migrations() []migrate.Migration {
+	return Migrations(r.tableName)
+}
+
+// Insert creates a pet of the admin, and returns its ID.
+func (r *Repository) Insert(adminID uint64, name, category, avatar string, birthday time.Time, medicalCurrent, hobbies, gender string) (int, error) {
+	return InsertPet(r.db, r.tableName, adminID, name, category, avatar, birthday, medicalCurrent, hobbies, gender)
+}
+
+// ListByAdminID lists the pets of the admin.
+func (r *Repository) ListByAdminID(adminID uint64) ([]*Pet, error) {
+	return ListPetByAdminID(r.db, r.tableName, adminID)
+}
+
+// InfoByID returns ErrNotFound if there's no such pet.
+func (r *Repository) InfoByID(id uint64) (*Pet, error) {
+	return InfoByID(r.db, r.tableName, id)
+}
+
+// ModifyName modifies the name of the pet.
+func (r *Repository) ModifyName(id uint64, name string) error {
+	return ModifyName(r.db, r.tableName, id, name)
+}
+
+// ModifyCategory modifies the category of the pet.
+func (r *Repository) ModifyCategory(id uint64, category string) error {
+	return ModifyCategory(r.db, r.tableName, id, category)
+}
+
+// ModifyAvatar modifies the avatar of the pet.
+func (r *Repository) ModifyAvatar(id uint64, avatar string) error {
+	return ModifyAvatar(r.db, r.tableName, id, avatar)
+}
+
+// ModifyBirthday modifies the birthday of the pet.
+func (r *Repository) ModifyBirthday(id uint64, birthday time.Time) error {
+	return ModifyBirthday(r.db, r.tableName, id, birthday)
+}
+
+// ModifyMedicalCurrent modifies the current medical record of the pet.
+func (r *Repository) ModifyMedicalCurrent(id uint64, medicalCurrent string) error {
+	return ModifyMedicalCurrent(r.db, r.tableName, id, medicalCurrent)
+}
+
+// ModifyHobbies modifies the hobbies of the pet.
+func (r *Repository) ModifyHobbies(id uint64, hobbies string) error {
+	return ModifyHobbies(r.db, r.tableName, id, hobbies)
+}
+
+// ModifyGender modifies the gender of the pet.
+func (r *Repository) ModifyGender(id uint64, gender string) error {
+	return ModifyGender(r.db, r.tableName, id, gender)
+}
+
+// DeleteByID removes the pet of id.
+func (r *Repository) DeleteByID(id uint64) error {
+	return DeleteByID(r.db, r.tableName, id)
+}
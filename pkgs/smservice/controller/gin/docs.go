@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/abserari/shower/utils/openapi"
+)
+
+// Operations describes the routes of the module in the OpenAPI document.
+func (s *SMController) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/send", Summary: "Send a code to a mobile", Request: sendRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/check", Summary: "Check the code sent to a mobile", Request: checkRequest{}, Response: openapi.StatusBody{}},
+	}
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/abserari/shower/pkgs/module"
+	"github.com/abserari/shower/pkgs/smservice/model"
+	"github.com/abserari/shower/pkgs/smservice/model/memory"
+	"github.com/abserari/shower/pkgs/smservice/model/mysql"
+	service "github.com/abserari/shower/pkgs/smservice/service"
+)
+
+// ModuleName is the name of smservice in config.
+const ModuleName = "smservice"
+
+// nopVerify ignores the check result.
+type nopVerify struct{}
+
+func (nopVerify) OnVerifySucceed(targetID, mobile string) {}
+func (nopVerify) OnVerifyFailed(targetID, mobile string)  {}
+
+func init() {
+	module.Register(ModuleName, func(env *module.Env) (module.Module, error) {
+		var repo model.Repository
+		if env.Memory() {
+			repo = memory.NewRepository()
+		} else {
+			repo = mysql.NewRepository(env.DB)
+		}
+
+		return New(repo, &service.Config{
+			Host:           env.Config.SMS.Host,
+			Appcode:        env.Config.SMS.Appcode,
+			Digits:         env.Config.SMS.Digits,
+			ResendInterval: env.Config.SMS.ResendInterval,
+			Expire:         env.Config.SMS.Expire,
+			OnCheck:        nopVerify{},
+		}), nil
+	})
+}
+
+// Name returns the name of the module.
+func (s *SMController) Name() string { return ModuleName }
+
+// Dependencies is empty, the module works alone.
+func (s *SMController) Dependencies() []string { return nil }
+
+// Start does nothing, there is no background work.
+func (s *SMController) Start(ctx context.Context) error { return nil }
+
+// Shutdown has nothing to stop.
+func (s *SMController) Shutdown(ctx context.Context) error { return nil }
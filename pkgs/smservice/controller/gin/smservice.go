@@ -6,12 +6,13 @@
 package controller
 
 import (
-	"database/sql"
 	"log"
 	"net/http"
 
-	"github.com/abserari/shower/pkgs/smservice/model/mysql"
+	"github.com/abserari/shower/pkgs/smservice/model"
 	service "github.com/abserari/shower/pkgs/smservice/service"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,60 +21,77 @@ type SMController struct {
 	ser *service.Controller
 }
 
-// New -
-func New(db *sql.DB, conf *service.Config) *SMController {
+// New creates a controller of repo.
+func New(repo model.Repository, conf *service.Config) *SMController {
 	return &SMController{
-		ser: service.NewController(db, conf),
+		ser: service.NewController(repo, conf),
 	}
 }
 
+// Migrations returns the migrations of the repository.
+func (s *SMController) Migrations() []migrate.Migration {
+	return migrate.Of(s.ser.Repo)
+}
+
 // RegisterRouter -
 func (s *SMController) RegisterRouter(r gin.IRouter) {
 	if r == nil {
 		log.Fatal("[InitRouter]: server is nil")
 	}
 
-	err := mysql.CreateTable(s.ser.DB)
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	r.POST("/send", s.Send)
 	r.POST("/check", s.Check)
 }
 
-// Send 调度分配出发送短信
+// SendCode sends a code to mobile by sign, it's how the other modules verify a
+// mobile. The sign of a module is like <module>:<key>, which is reserved.
+func (s *SMController) SendCode(mobile, sign string) error {
+	return service.Send(mobile, sign, &s.ser.Conf, s.ser.Repo)
+}
+
+// CheckCode checks the code sent to mobile by sign, a code is checked successfully once.
+func (s *SMController) CheckCode(mobile, code, sign string) error {
+	return service.Check(mobile, code, sign, &s.ser.Conf, s.ser.Repo)
+}
+
+type sendRequest struct {
+	Mobile string `json:"mobile"`
+	Sign   string `json:"sign"`
+}
+
+// Send 调度分配出发送短信，不能使用模块保留的sign
 func (s *SMController) Send(c *gin.Context) {
-	var (
-		req struct {
-			Mobile string `json:"mobile"`
-			Sign   string `json:"sign"`
-		}
-	)
+	var req sendRequest
 
 	err := c.ShouldBind(&req)
 	if err != nil {
+		c.Error(errs.Bind(err))
+		return
+	}
+
+	if err = service.CheckPublic(req.Sign); err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	if err = service.Send(req.Mobile, req.Sign, &s.ser.Conf, s.ser.DB); err != nil {
+	if err = service.Send(req.Mobile, req.Sign, &s.ser.Conf, s.ser.Repo); err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
-// Check 调度分配检查验证码
+type checkRequest struct {
+	Mobile string `json:"mobile"`
+	Code   string `json:"code"`
+	Sign   string `json:"sign"`
+}
+
+// Check 调度分配检查验证码，验证码需要是发送到该手机号的
 func (s *SMController) Check(c *gin.Context) {
 	var (
-		req struct {
-			Code string `json:"code"`
-			Sign string `json:"sign"`
-		}
+		req checkRequest
 
 		resp struct {
 			sign   string
@@ -83,19 +101,22 @@ func (s *SMController) Check(c *gin.Context) {
 
 	err := c.ShouldBind(&req)
 	if err != nil {
+		c.Error(errs.Bind(err))
+		return
+	}
+
+	if err = service.CheckPublic(req.Sign); err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	resp.sign = req.Sign
-	resp.mobile, _ = mysql.GetMobile(s.ser.DB, resp.sign)
+	resp.mobile = req.Mobile
 
-	if err = service.Check(req.Code, req.Sign, &s.ser.Conf, s.ser.DB); err != nil {
+	if err = service.Check(req.Mobile, req.Code, req.Sign, &s.ser.Conf, s.ser.Repo); err != nil {
 		s.ser.Conf.OnCheck.OnVerifyFailed(resp.sign, resp.mobile)
 
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
 		return
 	}
 
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/abserari/shower/pkgs/smservice/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+var (
+	errDuplicate = errs.Conflict("message already exists")
+)
+
+// Repository stores the messages in memory, it's used in development and tests.
+type Repository struct {
+	mu       sync.RWMutex
+	messages map[string]*model.Message
+}
+
+// NewRepository creates an empty Repository.
+func NewRepository() *Repository {
+	return &Repository{
+		messages: make(map[string]*model.Message),
+	}
+}
+
+// Insert Insert a new message, sign is unique.
+func (r *Repository) Insert(mobile string, date int64, code string, sign string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range r.messages {
+		if m.Sign == sign {
+			return errDuplicate
+		}
+	}
+
+	r.messages[sign] = &model.Message{
+		Mobile: mobile,
+		Date:   date,
+		Code:   code,
+		Sign:   sign,
+	}
+	return nil
+}
+
+func (r *Repository) get(sign string) (*model.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.messages[sign]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+
+	return m, nil
+}
+
+// GetDate return message date(unixtime) and nil if no err,or (0,err).
+func (r *Repository) GetDate(sign string) (int64, error) {
+	m, err := r.get(sign)
+	if err != nil {
+		return 0, err
+	}
+
+	return m.Date, nil
+}
+
+// Delete Clear delete a  message.
+func (r *Repository) Delete(sign string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.messages, sign)
+	return nil
+}
+
+// GetCode return message code and nil or "0" and err.
+func (r *Repository) GetCode(sign string) (string, error) {
+	m, err := r.get(sign)
+	if err != nil {
+		return "0", err
+	}
+
+	return m.Code, nil
+}
+
+// GetMobile return User's mobile like ID or "0" and err
+func (r *Repository) GetMobile(sign string) (string, error) {
+	m, err := r.get(sign)
+	if err != nil {
+		return "0", err
+	}
+
+	return m.Mobile, nil
+}
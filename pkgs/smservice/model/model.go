@@ -0,0 +1,28 @@
+package model
+
+import (
+	"github.com/abserari/shower/utils/errs"
+)
+
+// ErrNotFound is returned if there's no such message.
+var ErrNotFound = errs.NotFound("message not found")
+
+// Message -
+type Message struct {
+	Mobile string `db:"mobile"`
+	Date   int64  `db:"date"`
+	Code   string `db:"code"`
+	Sign   string `db:"sign"`
+}
+
+// Repository stores the sent messages by sign.
+type Repository interface {
+	Insert(mobile string, date int64, code string, sign string) error
+	// GetDate return message date(unixtime) and nil if no err,or (0,err).
+	GetDate(sign string) (int64, error)
+	Delete(sign string) error
+	// GetCode return message code and nil or "0" and err.
+	GetCode(sign string) (string, error)
+	// GetMobile return User's mobile like ID or "0" and err
+	GetMobile(sign string) (string, error)
+}
@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/abserari/shower/utils/migrate"
+)
+
+// Repository stores the messages in the MySQL message table.
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a Repository in db.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Migrations returns the migrations of the tables.
+func (r *Repository) Migrations() []migrate.Migration {
+	return Migrations()
+}
+
+// Insert records the code sent to mobile at date under sign.
+func (r *Repository) Insert(mobile string, date int64, code string, sign string) error {
+	return Insert(r.db, mobile, date, code, sign)
+}
+
+// GetDate returns when the code of sign is sent, in unix time.
+func (r *Repository) GetDate(sign string) (int64, error) {
+	return GetDate(r.db, sign)
+}
+
+// Delete removes the code of sign.
+func (r *Repository) Delete(sign string) error {
+	return Delete(r.db, sign)
+}
+
+// GetCode returns the code sent under sign.
+func (r *Repository) GetCode(sign string) (string, error) {
+	return GetCode(r.db, sign)
+}
+
+// GetMobile returns the mobile the code of sign is sent to.
+func (r *Repository) GetMobile(sign string) (string, error) {
+	return GetMobile(r.db, sign)
+}
@@ -8,15 +8,14 @@ package mysql
 import (
 	"database/sql"
 	"errors"
+
+	"github.com/abserari/shower/pkgs/smservice/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 )
 
-// Message -
-type Message struct {
-	Mobile string `db:"mobile"`
-	Date   int64  `db:"date"`
-	Code   string `db:"code"`
-	Sign   string `db:"sign"`
-}
+// Message is a code sent to a mobile.
+type Message = model.Message
 
 const (
 	mysqlMessageCreateTable = iota
@@ -25,6 +24,9 @@ const (
 	mysqlMessageDelete
 	mysqlMessageGetCode
 	mysqlMessageUGetMobile
+	mysqlMessageDropTable
+	mysqlMessageDropUniqueMobile
+	mysqlMessageAddUniqueMobile
 )
 
 var (
@@ -40,9 +42,30 @@ var (
 		`DELETE FROM message WHERE sign = ? LIMIT 1`,
 		`SELECT code FROM message WHERE sign = ?`,
 		`SELECT mobile FROM message WHERE sign = ?`,
+		`DROP TABLE IF EXISTS message`,
+		`ALTER TABLE message DROP INDEX mobile`,
+		`ALTER TABLE message ADD UNIQUE INDEX mobile (mobile)`,
 	}
 )
 
+// Migrations of message table.
+func Migrations() []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version:     1,
+			Description: "create message table",
+			Up:          []string{messageSQLString[mysqlMessageCreateTable]},
+			Down:        []string{messageSQLString[mysqlMessageDropTable]},
+		},
+		{
+			Version:     2,
+			Description: "allow the codes of several signs sent to a mobile",
+			Up:          []string{messageSQLString[mysqlMessageDropUniqueMobile]},
+			Down:        []string{messageSQLString[mysqlMessageAddUniqueMobile]},
+		},
+	}
+}
+
 // CreateTable create message table.
 func CreateTable(db *sql.DB) error {
 	_, err := db.Exec(messageSQLString[mysqlMessageCreateTable])
@@ -53,7 +76,7 @@ func CreateTable(db *sql.DB) error {
 func Insert(db *sql.DB, mobile string, date int64, code string, sign string) error {
 	result, err := db.Exec(messageSQLString[mysqlMessageInsert], mobile, date, code, sign)
 	if err != nil {
-		return err
+		return errs.FromDB(err, "message")
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
@@ -68,8 +91,11 @@ func GetDate(db *sql.DB, sign string) (int64, error) {
 	var unixtime int64
 
 	err := db.QueryRow(messageSQLString[mysqlMessageGetDate], sign).Scan(&unixtime)
+	if err == sql.ErrNoRows {
+		return 0, model.ErrNotFound
+	}
 	if err != nil {
-		return 0, errors.New("errQueryDate")
+		return 0, err
 	}
 
 	return unixtime, nil
@@ -78,11 +104,7 @@ func GetDate(db *sql.DB, sign string) (int64, error) {
 // Delete Clear delete a  message.
 func Delete(db *sql.DB, sign string) error {
 	_, err := db.Exec(messageSQLString[mysqlMessageDelete], sign)
-	if err != nil {
-		return errors.New("errDeleteMysql")
-	}
-
-	return nil
+	return err
 }
 
 // GetCode return message date and nil or "0"and err.
@@ -90,8 +112,11 @@ func GetCode(db *sql.DB, sign string) (string, error) {
 	var code string
 
 	err := db.QueryRow(messageSQLString[mysqlMessageGetCode], sign).Scan(&code)
+	if err == sql.ErrNoRows {
+		return "0", model.ErrNotFound
+	}
 	if err != nil {
-		return "0", errors.New("errQueryCode")
+		return "0", err
 	}
 
 	return code, nil
@@ -101,7 +126,10 @@ func GetCode(db *sql.DB, sign string) (string, error) {
 func GetMobile(db *sql.DB, sign string) (string, error) {
 	var mobile string
 
-	err := db.QueryRow(messageSQLString[mysqlMessageUGetMobile], mobile).Scan(&mobile)
+	err := db.QueryRow(messageSQLString[mysqlMessageUGetMobile], sign).Scan(&mobile)
+	if err == sql.ErrNoRows {
+		return "0", model.ErrNotFound
+	}
 	if err != nil {
 		return "0", err
 	}
@@ -7,21 +7,33 @@ package services
 
 import (
 	ran "crypto/rand"
-	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"regexp"
+	"strings"
 
 	"time"
 
-	"github.com/abserari/shower/pkgs/smservice/model/mysql"
+	"github.com/abserari/shower/pkgs/smservice/model"
+	"github.com/abserari/shower/utils/errs"
 )
 
 var numbers = []byte("012345678998765431234567890987654321")
 
+var (
+	errResend = errs.RateLimited("短时间内不允许发送两次")
+	errMobile = errs.Validation("手机号不符合规则")
+	errSign   = errs.NotFound("Sign error")
+	errCode   = errs.Validation("Code error")
+	errExpire = errs.Validation("Code expired")
+
+	// errReserved is a sign of a module used by the public API.
+	errReserved = errs.Forbidden("the sign is reserved")
+)
+
 // SMSVerify -
 type SMSVerify interface {
 	OnVerifySucceed(targetID, mobile string)
@@ -34,24 +46,27 @@ type Config struct {
 	Appcode        string
 	Digits         int
 	ResendInterval int
-	OnCheck        SMSVerify
+	// Expire is the seconds a code could be checked, 0 never expires.
+	Expire  int
+	OnCheck SMSVerify
 }
 
 // Controller -
 type Controller struct {
-	DB   *sql.DB
+	Repo model.Repository
 	Conf Config
 }
 
-// NewController -
-func NewController(db *sql.DB, Conf *Config) *Controller {
+// NewController creates a Controller sending the messages by Conf.
+func NewController(repo model.Repository, Conf *Config) *Controller {
 	sm := &Controller{
-		DB: db,
+		Repo: repo,
 		Conf: Config{
 			Host:           Conf.Host,
 			Appcode:        Conf.Appcode,
 			Digits:         Conf.Digits,
 			ResendInterval: Conf.ResendInterval,
+			Expire:         Conf.Expire,
 			OnCheck:        Conf.OnCheck,
 		},
 	}
@@ -108,22 +123,22 @@ func Code(size int) string {
 }
 
 //有效检验
-func (sms *SMS) checkvalid(db *sql.DB, conf *Config) error {
-	unixtime := sms.getDate(db)
+func (sms *SMS) checkvalid(repo model.Repository, conf *Config) error {
+	unixtime := sms.getDate(repo)
 
 	if unixtime > 0 && sms.Date-unixtime < int64(conf.ResendInterval) {
-		return errors.New("短时间内不允许发送两次")
+		return errResend
 	}
 
 	if err := VailMobile(sms.Mobile); err != nil {
-		return errors.New("手机号不符合规则")
+		return errMobile
 	}
 
 	return nil
 }
 
-func (sms *SMS) getDate(db *sql.DB) int64 {
-	unixtime, _ := mysql.GetDate(db, sms.Sign)
+func (sms *SMS) getDate(repo model.Repository) int64 {
+	unixtime, _ := repo.GetDate(sms.Sign)
 	return unixtime
 }
 
@@ -147,8 +162,8 @@ func VailMobile(mobile string) error {
 
 //发送后存储这个信息：手机号,时间，验证码
 //存储入数据库
-func (sms *SMS) save(db *sql.DB) error {
-	err := mysql.Insert(db, sms.Mobile, sms.Date, sms.Code, sms.Sign)
+func (sms *SMS) save(repo model.Repository) error {
+	err := repo.Insert(sms.Mobile, sms.Date, sms.Code, sms.Sign)
 
 	return err
 }
@@ -194,15 +209,18 @@ func (sms *SMS) sendmsg(conf *Config) error {
 }
 
 //Send 根据手机号和id生成时间和验证码，并发送后存入数据库
-func Send(mobile, sign string, conf *Config, db *sql.DB) error {
+func Send(mobile, sign string, conf *Config, repo model.Repository) error {
 	sms := newSms()
 	sms.prepare(mobile, sign, conf.Digits)
 
-	if err := sms.checkvalid(db, conf); err != nil {
+	if err := sms.checkvalid(repo, conf); err != nil {
 		return err
 	}
 
-	if err := sms.save(db); err != nil {
+	// the former code of sign is replaced after the resend interval.
+	sms.delete(sms.Sign, repo)
+
+	if err := sms.save(repo); err != nil {
 		return err
 	}
 
@@ -211,35 +229,68 @@ func Send(mobile, sign string, conf *Config, db *sql.DB) error {
 	return err
 }
 
-//Check 根据sign和验证码，返回nil表示成功
-func Check(code, sign string, conf *Config, db *sql.DB) error {
+// ReservedSeparator splits a module and the rest of the signs reserved for
+// the modules, like userAuth:login:13800000000.
+const ReservedSeparator = ":"
+
+// CheckPublic returns an error if sign is reserved, the public API mustn't
+// send or check the codes of the modules.
+func CheckPublic(sign string) error {
+	if strings.Contains(sign, ReservedSeparator) {
+		return errReserved
+	}
+	return nil
+}
+
+//Check 根据手机号、sign和验证码，返回nil表示成功，发送到其他手机号的验证码不匹配
+func Check(mobile, code, sign string, conf *Config, repo model.Repository) error {
 	sms := newSms()
+	sms.Mobile = mobile
 	sms.Date = time.Now().Unix()
 	sms.Code = code
 	sms.Sign = sign
+
+	//验证手机号
+	sent, err := repo.GetMobile(sms.Sign)
+	if errs.Is(err, errs.KindNotFound) || err == nil && sent != sms.Mobile {
+		return errSign
+	}
+	if err != nil {
+		return err
+	}
+
 	//验证超时
+	if conf.Expire > 0 {
+		if unixtime := sms.getDate(repo); unixtime > 0 && sms.Date-unixtime > int64(conf.Expire) {
+			sms.delete(sms.Sign, repo)
+			return errExpire
+		}
+	}
 
 	//验证
-	getcode, err := sms.getCode(db)
+	getcode, err := sms.getCode(repo)
+	if errs.Is(err, errs.KindNotFound) {
+		return errSign
+	}
 	if err != nil {
-		return errors.New("Sign error")
+		return err
 	}
 
 	if sms.Code == getcode {
-		sms.delete(sms.Sign, db)
+		sms.delete(sms.Sign, repo)
 		return nil
 	}
 
-	return errors.New("Code error")
+	return errCode
 }
 
-func (sms *SMS) getCode(db *sql.DB) (string, error) {
-	code, err := mysql.GetCode(db, sms.Sign)
+func (sms *SMS) getCode(repo model.Repository) (string, error) {
+	code, err := repo.GetCode(sms.Sign)
 	return code, err
 }
 
 //删除数据库数据
-func (sms *SMS) delete(sign string, db *sql.DB) { mysql.Delete(db, sign) }
+func (sms *SMS) delete(sign string, repo model.Repository) { repo.Delete(sign) }
 
 // UID 生成uid
 func UID() string {
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abserari/shower/pkgs/smservice/model/memory"
+)
+
+func TestCheckMobile(t *testing.T) {
+	const (
+		sender = "13800000001"
+		victim = "13800000002"
+		code   = "123456"
+		sign   = "userAuth:" + victim
+	)
+
+	conf := &Config{Expire: 60}
+	repo := memory.NewRepository()
+	if err := repo.Insert(sender, time.Now().Unix(), code, sign); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Check(victim, code, sign, conf, repo); err != errSign {
+		t.Fatalf("the code sent to %s verifies %s: %v", sender, victim, err)
+	}
+
+	// the code is not used up by the mobile mismatched.
+	if err := Check(sender, code, sign, conf, repo); err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(sender, code, sign, conf, repo); err != errSign {
+		t.Fatalf("the code is checked twice: %v", err)
+	}
+}
+
+func TestCheckCode(t *testing.T) {
+	const mobile = "13800000001"
+
+	conf := &Config{Expire: 60}
+	repo := memory.NewRepository()
+	if err := repo.Insert(mobile, time.Now().Unix(), "123456", "order"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Check(mobile, "654321", "order", conf, repo); err != errCode {
+		t.Fatalf("error %v, want %v", err, errCode)
+	}
+	if err := Check(mobile, "123456", "other", conf, repo); err != errSign {
+		t.Fatalf("error %v, want %v", err, errSign)
+	}
+
+	if err := repo.Delete("order"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Insert(mobile, time.Now().Unix()-61, "123456", "order"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(mobile, "123456", "order", conf, repo); err != errExpire {
+		t.Fatalf("error %v, want %v", err, errExpire)
+	}
+}
+
+func TestCheckPublic(t *testing.T) {
+	for sign, reserved := range map[string]bool{
+		"order":                false,
+		"":                     false,
+		"userAuth:13800000001": true,
+		"anything:13800000001": true,
+		":13800000001":         true,
+	} {
+		if err := CheckPublic(sign); (err != nil) != reserved {
+			t.Errorf("sign %q: error %v, reserved %t", sign, err, reserved)
+		}
+	}
+}
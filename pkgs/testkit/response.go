@@ -0,0 +1,76 @@
+package testkit
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/abserari/shower/utils/errs"
+)
+
+// envelopes are the keys of the status in a successful body, the token of
+// login answers "code".
+var envelopes = []string{"status", "code"}
+
+// Response is what the router answered.
+type Response struct {
+	t      testing.TB
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Decode decodes the JSON body into v.
+func (r *Response) Decode(v interface{}) *Response {
+	r.t.Helper()
+
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("testkit: decode %s: %v", r.Body, err)
+	}
+	return r
+}
+
+// OK asserts the request succeeded with the status 200 in the body, then
+// decodes the body into v unless it's nil.
+func (r *Response) OK(v interface{}) *Response {
+	r.t.Helper()
+
+	if r.Status != http.StatusOK {
+		r.t.Fatalf("testkit: status %d, want %d: %s", r.Status, http.StatusOK, r.Body)
+	}
+
+	var body map[string]interface{}
+	r.Decode(&body)
+	for _, key := range envelopes {
+		if status, ok := body[key]; ok {
+			if status != float64(http.StatusOK) {
+				r.t.Fatalf("testkit: %s %v, want %d: %s", key, status, http.StatusOK, r.Body)
+			}
+			break
+		}
+	}
+
+	if v != nil {
+		r.Decode(v)
+	}
+	return r
+}
+
+// Error asserts the request failed with the error of kind, and returns the error body.
+func (r *Response) Error(kind errs.Kind) *errs.Body {
+	r.t.Helper()
+
+	if r.Status != kind.Status() {
+		r.t.Fatalf("testkit: status %d, want %d: %s", r.Status, kind.Status(), r.Body)
+	}
+
+	var body errs.Body
+	r.Decode(&body)
+	if body.Code != kind.String() {
+		r.t.Fatalf("testkit: code %q, want %q: %s", body.Code, kind.String(), r.Body)
+	}
+	if body.RequestID == "" {
+		r.t.Fatalf("testkit: no request_id: %s", r.Body)
+	}
+	return &body
+}
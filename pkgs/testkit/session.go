@@ -0,0 +1,205 @@
+package testkit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// Session sends the requests with the token of an admin.
+type Session struct {
+	kit     *Kit
+	AdminID uint32
+	Token   string
+	// APIKey is sent in the header api_key.header instead of the token.
+	APIKey string
+	// Cookies are sent with every request, like the ones set by a redirect.
+	Cookies []*http.Cookie
+}
+
+// Anonymous sends the requests without token.
+func (k *Kit) Anonymous() *Session {
+	return &Session{kit: k}
+}
+
+// Login logs in by name and password, it fails the test if the login fails.
+func (k *Kit) Login(name, password string) *Session {
+	k.t.Helper()
+
+	var token struct {
+		Token string `json:"token"`
+	}
+	k.Anonymous().Post(loginPath, map[string]string{"name": name, "password": password}).OK(&token)
+
+	return k.Session(token.Token)
+}
+
+// KeySession is the session of a service account by its API key.
+func (k *Kit) KeySession(key string) *Session {
+	return &Session{kit: k, APIKey: key}
+}
+
+// Session is the session of token, like the one answered by another login.
+func (k *Kit) Session(token string) *Session {
+	k.t.Helper()
+
+	return &Session{
+		kit:     k,
+		AdminID: adminIDOf(k, token),
+		Token:   token,
+	}
+}
+
+// Root is the session of the bootstrap admin. The first one changes the
+// bootstrap password to RootPassword, which the admin must change first.
+func (k *Kit) Root() *Session {
+	k.t.Helper()
+
+	if k.root == nil {
+		k.Login(RootName, seedPassword).Post(passwordPath, map[string]string{
+			"password":     seedPassword,
+			"new_password": RootPassword,
+			"confirm":      RootPassword,
+		}).OK(nil)
+		k.root = k.Login(RootName, RootPassword)
+	}
+	return k.root
+}
+
+// CreateAdmin creates an admin by the root.
+func (k *Kit) CreateAdmin(name, password string) {
+	k.t.Helper()
+
+	k.Root().Post(createPath, map[string]string{"name": name, "password": password}).OK(nil)
+}
+
+// CreateRole creates a role which could access urls, and returns its ID.
+func (k *Kit) CreateRole(name string, urls ...string) uint32 {
+	k.t.Helper()
+
+	root := k.Root()
+	root.Post(addRolePath, map[string]string{"name": name, "intro": "testkit"}).OK(nil)
+
+	var list struct {
+		RoleList []struct {
+			RoleID uint32
+			Name   string
+		}
+	}
+	root.Post(roleListPath, nil).OK(&list)
+
+	var id uint32
+	for _, r := range list.RoleList {
+		if r.Name == name && r.RoleID > id {
+			id = r.RoleID
+		}
+	}
+	if id == 0 {
+		k.t.Fatalf("testkit: role %s is not listed", name)
+	}
+
+	for _, url := range urls {
+		root.Post(addURLPath, map[string]interface{}{"role_id": id, "url": url}).OK(nil)
+	}
+	return id
+}
+
+// LoginAs creates an admin having roles and logs in.
+func (k *Kit) LoginAs(name string, roles ...uint32) *Session {
+	k.t.Helper()
+
+	k.seq++
+	password := fmt.Sprintf("testkit%d", k.seq)
+	k.CreateAdmin(name, password)
+
+	s := k.Login(name, password)
+	for _, rid := range roles {
+		k.Root().Post(relationPath, map[string]interface{}{"admin_id": s.AdminID, "role_id": rid}).OK(nil)
+	}
+	return s
+}
+
+// Post sends body in JSON.
+func (s *Session) Post(path string, body interface{}) *Response {
+	s.kit.t.Helper()
+
+	return s.Call(http.MethodPost, path, body)
+}
+
+// Get sends a request without body.
+func (s *Session) Get(path string) *Response {
+	s.kit.t.Helper()
+
+	return s.Call(http.MethodGet, path, nil)
+}
+
+// Call sends body in JSON unless it's nil, or an io.Reader sent as it is.
+func (s *Session) Call(method, path string, body interface{}) *Response {
+	t := s.kit.t
+	t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if _, ok := body.(io.Reader); !ok && body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	if s.APIKey != "" {
+		req.Header.Set(s.kit.Config.APIKey.Header, s.APIKey)
+	}
+	for _, c := range s.Cookies {
+		req.AddCookie(c)
+	}
+
+	w := httptest.NewRecorder()
+	s.kit.Router.ServeHTTP(w, req)
+
+	return &Response{
+		t:      t,
+		Status: w.Code,
+		Header: w.Header(),
+		Body:   w.Body.Bytes(),
+	}
+}
+
+// adminIDOf reads the admin ID in the claims of token.
+func adminIDOf(k *Kit, token string) uint32 {
+	k.t.Helper()
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		k.t.Fatalf("testkit: %q is not a JWT", token)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		k.t.Fatal(err)
+	}
+
+	var claims struct {
+		UserID uint32 `json:"userID"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		k.t.Fatal(err)
+	}
+	return claims.UserID
+}
@@ -0,0 +1,36 @@
+package testkit
+
+import (
+	"net/http"
+	"sync"
+)
+
+// provider is the SMS provider of a kit, it keeps the last code sent to each mobile.
+type provider struct {
+	mu    sync.Mutex
+	codes map[string]string
+}
+
+func newProvider() *provider {
+	return &provider{codes: make(map[string]string)}
+}
+
+// ServeHTTP answers like the provider of smservice.
+func (p *provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	p.mu.Lock()
+	p.codes[q.Get("phone")] = q.Get("code")
+	p.mu.Unlock()
+
+	w.Write([]byte(`{"Code":"OK"}`))
+}
+
+// Code returns the last code sent to mobile by smservice, false if there's none.
+func (k *Kit) Code(mobile string) (string, bool) {
+	k.sms.mu.Lock()
+	defer k.sms.mu.Unlock()
+
+	code, ok := k.sms.codes[mobile]
+	return code, ok
+}
@@ -0,0 +1,152 @@
+// Package testkit runs the modules behind the middlewares of the server
+// against an isolated memory database, for end-to-end tests:
+//
+//	kit := testkit.New(t)
+//	role := kit.CreateRole("petreader", "/api/v1/pet/info/id")
+//	s := kit.LoginAs("reader", role)
+//	s.Post("/api/v1/pet/create", body).Error(errs.KindForbidden)
+package testkit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abserari/shower/pkgs/module"
+	_ "github.com/abserari/shower/pkgs/module/all"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/mail"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RootName and RootPassword are of the bootstrap admin, who passes every permission check.
+	RootName     = "Admin"
+	RootPassword = "testkit-root"
+	// seedPassword is the bootstrap password, Root changes it to RootPassword
+	// at the first login like a deployment does.
+	seedPassword = "testkit-seed"
+
+	// userAuth is the module logging in the admins.
+	userAuth = "userAuth"
+
+	// the API used to prepare the admins and roles.
+	loginPath    = "/api/v1/userAuth/login"
+	passwordPath = "/api/v1/userAuth/me/password"
+	createPath   = "/api/v1/userAuth/create"
+	addRolePath  = "/api/v1/permission/addrole"
+	roleListPath = "/api/v1/permission/getallrole"
+	addURLPath   = "/api/v1/permission/addurl"
+	relationPath = "/api/v1/permission/addrelation"
+)
+
+// Config returns the config of a kit, every module except upload is enabled
+// under /api/v1/<name>, smservice is public. upload saves the files under the
+// working directory, enable it if that's what the test wants.
+func Config() *config.Config {
+	c := config.Default()
+	c.Server.Docs = ""
+	c.Database.Driver = config.MemoryDriver
+	c.JWT.Key = "testkit"
+	c.Bootstrap.Password = seedPassword
+	c.FileServer.Address = "127.0.0.1:9573"
+	// nothing listens here, sending a message fails at once. New replaces it
+	// by a provider keeping the codes, read them by Code.
+	c.SMS.Host = "http://127.0.0.1:1/"
+	c.SMS.Appcode = "testkit"
+	// the mails are kept, read them by Mails.
+	c.Mail.Driver = config.MemoryMail
+	c.Middlewares = []string{"jwt", "active", "permission"}
+	c.Modules = []config.Module{
+		{Name: "smservice", Group: "/api/v1/message", Public: true},
+		{Name: "userAuth", Group: "/api/v1/userAuth"},
+		{Name: "permission", Group: "/api/v1/permission"},
+		{Name: "pet", Group: "/api/v1/pet"},
+		{Name: "banner", Group: "/api/v1/banner"},
+		{Name: "category", Group: "/api/v1/category"},
+		{Name: "order", Group: "/api/v1/order"},
+		{Name: "department", Group: "/api/v1/department"},
+	}
+	return c
+}
+
+// Kit is a server of its own, the requests are served in process.
+type Kit struct {
+	t       testing.TB
+	Config  *config.Config
+	Modules *module.Registry
+	Router  *gin.Engine
+
+	root *Session
+	seq  int
+	sms  *provider
+}
+
+// New builds the router of the server by Config modified by configure,
+// the modules are started and shut down when the test finishes.
+func New(t testing.TB, configure ...func(c *config.Config)) *Kit {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	sms := newProvider()
+	server := httptest.NewServer(sms)
+	t.Cleanup(server.Close)
+
+	conf := Config()
+	conf.SMS.Host = server.URL + "/"
+	for _, f := range configure {
+		f(conf)
+	}
+	if err := conf.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	modules, err := module.NewRegistry(conf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	if err = modules.Setup(router); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err = modules.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		modules.Shutdown(ctx)
+	})
+
+	return &Kit{
+		t:       t,
+		Config:  conf,
+		Modules: modules,
+		Router:  router,
+		sms:     sms,
+	}
+}
+
+// Mails returns the mails sent by userAuth, the oldest first.
+func (k *Kit) Mails() []mail.Message {
+	k.t.Helper()
+
+	m, ok := k.Modules.Module(userAuth)
+	if !ok {
+		k.t.Fatalf("testkit: %s is not enabled", userAuth)
+	}
+
+	sender, ok := m.(interface{ Mailer() mail.Mailer })
+	if !ok {
+		k.t.Fatalf("testkit: %s sends no mail", userAuth)
+	}
+	mem, ok := sender.Mailer().(*mail.Memory)
+	if !ok {
+		k.t.Fatalf("testkit: the mail driver is not %s", config.MemoryMail)
+	}
+
+	return mem.Messages()
+}
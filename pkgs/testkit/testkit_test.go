@@ -0,0 +1,55 @@
+package testkit_test
+
+import (
+	"testing"
+
+	"github.com/abserari/shower/pkgs/testkit"
+	"github.com/abserari/shower/utils/errs"
+)
+
+func TestPermission(t *testing.T) {
+	kit := testkit.New(t)
+
+	role := kit.CreateRole("petreader", "/api/v1/pet/list/adminid")
+	s := kit.LoginAs("reader", role)
+
+	var list struct {
+		Pets []interface{} `json:"pets"`
+	}
+	s.Post("/api/v1/pet/list/adminid", map[string]interface{}{"adminID": s.AdminID}).OK(&list)
+	if len(list.Pets) != 0 {
+		t.Fatalf("pets %v, want none", list.Pets)
+	}
+
+	s.Post("/api/v1/pet/create", map[string]interface{}{"adminID": s.AdminID, "name": "kitty"}).Error(errs.KindForbidden)
+	kit.Anonymous().Post("/api/v1/pet/list/adminid", map[string]interface{}{"adminID": s.AdminID}).Error(errs.KindUnauthorized)
+}
+
+func TestOrder(t *testing.T) {
+	kit := testkit.New(t)
+	root := kit.Root()
+
+	var created struct {
+		OrderID   uint32 `json:"orderid"`
+		OrderCode string `json:"ordercode"`
+	}
+	root.Post("/api/v1/order/create", map[string]interface{}{
+		"userid":     1,
+		"addressid":  "home",
+		"totalprice": 100,
+		"promotion":  "false",
+	}).OK(&created)
+	if created.OrderID == 0 || created.OrderCode == "" {
+		t.Fatalf("order %+v is not created", created)
+	}
+
+	var id struct {
+		ID uint32 `json:"id"`
+	}
+	root.Post("/api/v1/order/id", map[string]interface{}{"ordercode": created.OrderCode}).OK(&id)
+	if id.ID != created.OrderID {
+		t.Fatalf("order ID %d, want %d", id.ID, created.OrderID)
+	}
+
+	root.Post("/api/v1/order/create", map[string]interface{}{"userid": 1, "promotion": "maybe"}).Error(errs.KindValidation)
+}
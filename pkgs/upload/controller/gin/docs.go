@@ -0,0 +1,21 @@
+package controller
+
+import (
+	"mime/multipart"
+	"net/http"
+
+	"github.com/abserari/shower/utils/openapi"
+)
+
+// uploadRequest is the form read by upload, only used by the docs.
+type uploadRequest struct {
+	File *multipart.FileHeader `form:"file" binding:"required"`
+}
+
+// Operations describes the routes of the module in the OpenAPI document.
+func (u *UploadController) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/upload", Summary: "Upload a file", Request: uploadRequest{}, Multipart: true, Response: uploadResponse{}},
+		{Method: http.MethodPost, Path: "/delete", Summary: "Delete a file", Request: deleteByIDRequest{}, Response: openapi.StatusBody{}},
+	}
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/abserari/shower/pkgs/module"
+	"github.com/abserari/shower/pkgs/upload/model"
+	"github.com/abserari/shower/pkgs/upload/model/memory"
+	mysql "github.com/abserari/shower/pkgs/upload/model/mysql"
+	md "github.com/abserari/shower/utils/file"
+)
+
+// ModuleName is the name of upload in config.
+const ModuleName = "upload"
+
+// identifier is the module who knows the current userAuth.
+const identifier = "userAuth"
+
+func init() {
+	module.Register(ModuleName, func(env *module.Env) (module.Module, error) {
+		id, err := env.Identifier(identifier)
+		if err != nil {
+			return nil, err
+		}
+
+		var repo model.Repository
+		if env.Memory() {
+			repo = memory.NewRepository()
+		} else {
+			repo = mysql.NewRepository(env.DB)
+		}
+
+		return New(repo, env.Config.FileServer.Address, id.GetID), nil
+	})
+}
+
+// Name returns the name of the module.
+func (u *UploadController) Name() string { return ModuleName }
+
+// Dependencies requires the module identifying the uploaders.
+func (u *UploadController) Dependencies() []string { return []string{identifier} }
+
+// Start makes sure the directories to save files exist.
+func (u *UploadController) Start(ctx context.Context) error {
+	return md.CheckDir(PictureDir, VideoDir, OtherDir)
+}
+
+// Shutdown has nothing to stop.
+func (u *UploadController) Shutdown(ctx context.Context) error { return nil }
@@ -6,7 +6,6 @@
 package controller
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -15,14 +14,19 @@ import (
 	"path"
 	"strings"
 
-	mysql "github.com/abserari/shower/pkgs/upload/model/mysql"
+	"github.com/abserari/shower/pkgs/upload/model"
+	"github.com/abserari/shower/utils/errs"
 	md "github.com/abserari/shower/utils/file"
+	"github.com/abserari/shower/utils/migrate"
 	"github.com/gin-gonic/gin"
 )
 
 var (
-	errRequest = errors.New("Request is not post method")
-	erruserID  = errors.New("userID invalid")
+	errRequest   = errs.Validation("Request is not post method")
+	erruserID    = errs.Forbidden("userID invalid")
+	errNoFile    = errs.Validation("file is required")
+	errFileExist = errs.Conflict("the file already exists")
+	errPath      = errs.Validation("path is not an uploaded file")
 )
 
 const (
@@ -42,93 +46,90 @@ const (
 
 // UploadController -
 type UploadController struct {
-	db      *sql.DB
+	repo    model.Repository
 	BaseURL string
 	getUID  func(c *gin.Context) (uint32, error)
 }
 
-// New -
-func New(db *sql.DB, baseURL string, getUID func(c *gin.Context) (uint32, error)) *UploadController {
+// New creates a controller of repo.
+func New(repo model.Repository, baseURL string, getUID func(c *gin.Context) (uint32, error)) *UploadController {
 	return &UploadController{
-		db:      db,
+		repo:    repo,
 		BaseURL: "http://" + baseURL + "/",
 		getUID:  getUID,
 	}
 }
 
+// Migrations returns the migrations of the repository.
+func (u *UploadController) Migrations() []migrate.Migration {
+	return migrate.Of(u.repo)
+}
+
 // RegisterRouter -
 func (u *UploadController) RegisterRouter(r gin.IRouter) {
 	if r == nil {
 		log.Fatal("[InitRouter]: server is nil")
 	}
 
-	err := mysql.CreateTable(u.db)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = md.CheckDir(PictureDir, VideoDir, OtherDir)
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	r.POST("/upload", u.upload)
 	r.POST("/delete", u.deleteByID)
 }
 
+type uploadResponse struct {
+	Status int    `json:"status"`
+	URL    string `json:"URL"`
+}
+
 func (u *UploadController) upload(c *gin.Context) {
 	if c.Request.Method != "POST" {
 		c.Error(errRequest)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
 		return
 	}
 
 	userID, err := u.getUID(c)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusUnauthorized, gin.H{"status": http.StatusUnauthorized})
 		return
 	}
 
 	if userID == InvalidUID {
 		c.Error(erruserID)
-		c.JSON(http.StatusForbidden, gin.H{"status": http.StatusForbidden})
 		return
 	}
 
 	file, header, err := c.Request.FormFile(FileKey)
+	if err != nil {
+		c.Error(errs.Wrap(errs.KindValidation, errNoFile.Message, err))
+		return
+	}
 	defer func() {
 		file.Close()
 		c.Request.MultipartForm.RemoveAll()
 	}()
 
+	newfile, err := ioutil.ReadAll(file)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusNotFound, gin.H{"status": http.StatusNotFound})
+		c.Error(errs.Internal(err))
 		return
 	}
 
-	newfile, _ := ioutil.ReadAll(file)
-
 	MD5Str, err := md.MD5(newfile)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusMethodNotAllowed, gin.H{"status": http.StatusMethodNotAllowed})
+		c.Error(errs.Internal(err))
 		return
 	}
 
-	filePath, err := mysql.QueryByMD5(u.db, MD5Str)
+	filePath, err := u.repo.QueryByMD5(MD5Str)
 	// if the file exists, return it now.
 	if err == nil {
 		fmt.Println("The file already exists:", filePath)
-		c.JSON(http.StatusNotAcceptable, gin.H{"status": http.StatusNotAcceptable, "URL": u.BaseURL + filePath})
+		c.Error(errFileExist.WithDetails(gin.H{"URL": u.BaseURL + filePath}))
 		return
 	}
 
 	// check the error if is our expected - NoRows.
-	if err != mysql.ErrNoRows {
+	if !errors.Is(err, model.ErrNoRows) {
 		c.Error(err)
-		c.JSON(http.StatusConflict, gin.H{"status": http.StatusConflict})
 		return
 	}
 
@@ -137,41 +138,43 @@ func (u *UploadController) upload(c *gin.Context) {
 
 	err = md.CopyFile(filePath, newfile)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusPreconditionFailed, gin.H{"status": http.StatusPreconditionFailed})
+		c.Error(errs.Internal(err))
 		return
 	}
 
-	err = mysql.Insert(u.db, userID, filePath, MD5Str)
+	err = u.repo.Insert(userID, filePath, MD5Str)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusUnsupportedMediaType, gin.H{"status": http.StatusUnsupportedMediaType})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": http.StatusOK, "URL": u.BaseURL + filePath})
+	c.JSON(http.StatusOK, uploadResponse{Status: http.StatusOK, URL: u.BaseURL + filePath})
+}
+
+type deleteByIDRequest struct {
+	Path string `json:"path"    binding:"required"`
 }
 
 func (con *UploadController) deleteByID(c *gin.Context) {
-	var (
-		req struct {
-			Path string `json:"path"    binding:"required"`
-		}
-	)
+	var req deleteByIDRequest
 
 	err := c.ShouldBind(&req)
 	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		c.Error(errs.Bind(err))
+		return
+	}
+
+	i := strings.Index(req.Path, FileUploadDir+"/")
+	if i < 0 {
+		c.Error(errPath)
 		return
 	}
 
-	req.Path = req.Path[strings.Index(req.Path, "files/"):]
+	req.Path = req.Path[i:]
 	log.Println(req.Path, con.BaseURL)
-	err = mysql.DeleteByPath(con.db, req.Path)
+	err = con.repo.DeleteByPath(req.Path)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/abserari/shower/pkgs/upload/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+var (
+	errDuplicateMD5 = errs.Conflict("file already exists")
+)
+
+type file struct {
+	userID    uint32
+	md5       string
+	path      string
+	createdAt time.Time
+}
+
+// Repository records the files in memory, it's used in development and tests.
+type Repository struct {
+	mu    sync.RWMutex
+	files map[string]*file
+}
+
+// NewRepository creates an empty Repository.
+func NewRepository() *Repository {
+	return &Repository{
+		files: make(map[string]*file),
+	}
+}
+
+// Insert insert a file
+func (r *Repository) Insert(userID uint32, path, md5 string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.files[md5]; ok {
+		return errDuplicateMD5
+	}
+
+	r.files[md5] = &file{
+		userID:    userID,
+		md5:       md5,
+		path:      path,
+		createdAt: time.Now(),
+	}
+	return nil
+}
+
+// QueryByMD5 select by MD5
+func (r *Repository) QueryByMD5(md5 string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f, ok := r.files[md5]
+	if !ok {
+		return "", model.ErrNoRows
+	}
+
+	return f.path, nil
+}
+
+// DeleteByPath clears the records of file so file could reupload.
+func (r *Repository) DeleteByPath(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for md5, f := range r.files {
+		if f.path == path {
+			delete(r.files, md5)
+			break
+		}
+	}
+
+	return nil
+}
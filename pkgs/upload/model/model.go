@@ -0,0 +1,19 @@
+package model
+
+import (
+	"github.com/abserari/shower/utils/errs"
+)
+
+var (
+	// ErrNoRows is returned if there's no such file.
+	ErrNoRows = errs.NotFound("file not found")
+)
+
+// Repository records the uploaded files by MD5.
+type Repository interface {
+	Insert(userID uint32, path, md5 string) error
+	// QueryByMD5 returns ErrNoRows if the file is not uploaded.
+	QueryByMD5(md5 string) (string, error)
+	// DeleteByPath clears the records of file so file could reupload.
+	DeleteByPath(path string) error
+}
@@ -0,0 +1,39 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/abserari/shower/utils/migrate"
+)
+
+// Repository records the files in the MySQL files table.
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a Repository in db.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Migrations returns the migrations of the tables.
+func (r *Repository) Migrations() []migrate.Migration {
+	return Migrations()
+}
+
+// Insert records the file at path uploaded by the user.
+func (r *Repository) Insert(userID uint32, path, md5 string) error {
+	return Insert(r.db, userID, path, md5)
+}
+
+// QueryByMD5 returns the path of the file of md5, ErrNoRows if there's none.
+func (r *Repository) QueryByMD5(md5 string) (string, error) {
+	return QueryByMD5(r.db, md5)
+}
+
+// DeleteByPath forgets the file at path.
+func (r *Repository) DeleteByPath(path string) error {
+	return DeleteByPath(r.db, path)
+}
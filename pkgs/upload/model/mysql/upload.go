@@ -9,6 +9,10 @@ import (
 	"database/sql"
 	"errors"
 	"time"
+
+	"github.com/abserari/shower/pkgs/upload/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 )
 
 const (
@@ -16,11 +20,12 @@ const (
 	mysqlFileInsert
 	mysqlFileQueryByMD5
 	mysqlDeleteByPath
+	mysqlFileDropTable
 )
 
 var (
 	//ErrNoRows -
-	ErrNoRows        = errors.New("there is no such data in database")
+	ErrNoRows        = model.ErrNoRows
 	errInvalidInsert = errors.New("upload file: insert affected 0 rows")
 
 	sqlString = []string{
@@ -34,9 +39,22 @@ var (
 		`INSERT INTO files(user_id,md5,path,created_at) VALUES (?,?,?,?)`,
 		`SELECT path FROM files WHERE md5 = ? LOCK IN SHARE MODE`,
 		`DELETE FROM files WHERE path = ? LIMIT 1`,
+		`DROP TABLE IF EXISTS files`,
 	}
 )
 
+// Migrations of files table.
+func Migrations() []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version:     1,
+			Description: "create files table",
+			Up:          []string{sqlString[mysqlFileCreateTable]},
+			Down:        []string{sqlString[mysqlFileDropTable]},
+		},
+	}
+}
+
 // CreateTable create files table.
 func CreateTable(db *sql.DB) error {
 	_, err := db.Exec(sqlString[mysqlFileCreateTable])
@@ -48,7 +66,7 @@ func CreateTable(db *sql.DB) error {
 func Insert(db *sql.DB, userID uint32, path, md5 string) error {
 	result, err := db.Exec(sqlString[mysqlFileInsert], userID, md5, path, time.Now())
 	if err != nil {
-		return err
+		return errs.FromDB(err, "file")
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
@@ -78,9 +96,5 @@ func QueryByMD5(db *sql.DB, md5 string) (string, error) {
 // Delete Clear the records of file so file could reupload.
 func DeleteByPath(db *sql.DB, path string) error {
 	_, err := db.Exec(sqlString[mysqlDeleteByPath], path)
-	if err != nil {
-		return errors.New("errDeleteMysql")
-	}
-
-	return nil
+	return err
 }
@@ -6,230 +6,460 @@
 package controller
 
 import (
-	"database/sql"
-	"errors"
-	"fmt"
-	jwt "github.com/appleboy/gin-jwt/v2"
 	"log"
 	"net/http"
-
-	"github.com/abserari/shower/pkgs/userAuth/model/mysql"
+	"time"
+
+	"github.com/abserari/shower/pkgs/module"
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/mail"
+	"github.com/abserari/shower/utils/migrate"
+	"github.com/abserari/shower/utils/oidc"
+	"github.com/abserari/shower/utils/ratelimit"
+	"github.com/abserari/shower/utils/salt"
+	"github.com/abserari/shower/utils/token"
 	"github.com/gin-gonic/gin"
 )
 
 var (
-	// default userAuth
-	name     = "Admin"
-	password = "111111"
-
-	errActive          = errors.New("the userAuth is not activated")
-	errUserIDNotExists = errors.New("Get Admin ID is not exists")
-	errUserIDNotValid  = func(value interface{}) error {
-		return errors.New(fmt.Sprintf("Get Admin ID is not valid. Is %s", value))
-	}
+	errActive               = errs.Inactive("the userAuth is not activated")
+	errMustChangePassword   = errs.Forbidden("the password must be changed first")
+	errPasswordUnchanged    = errs.Validation("the new password is the same as the old one")
+	errPasswordNotConfirmed = errs.Validation("the new password is not confirmed")
+	errUserIDNotExists      = errs.Unauthorized("Get Admin ID is not exists")
+	errRevoked              = errs.Unauthorized("token is revoked")
 )
 
 // Controller external service interface
 type Controller struct {
-	db  *sql.DB
-	JWT *jwt.GinJWTMiddleware
+	repo      model.Repository
+	jwtConf   config.JWT
+	loginConf config.Login
+	JWT       *token.Middleware
+	// policy checks the new passwords.
+	policy *salt.Policy
+
+	// sms verifies the mobile of the admin, it's nil if smservice is not enabled.
+	sms Messenger
+	// limits of sending and checking the codes, by the IP or by the sign of the mobile.
+	sendByIP      *ratelimit.Limiter
+	sendByMobile  *ratelimit.Limiter
+	checkByMobile *ratelimit.Limiter
+	// limit of checking the second factor by admin ID and IP.
+	checkByAdmin *ratelimit.Limiter
+	// roles shows the roles of the admin, it's nil if permission is not enabled.
+	roles module.Roles
+	// granter grants the roles in the token, it's nil if roles doesn't.
+	granter module.Granter
+	// mail sends the links confirming the emails, limited by admin ID.
+	mail        mail.Mailer
+	mailConf    config.Mail
+	sendByAdmin *ratelimit.Limiter
+	// oidc logins the admins by the provider, it's nil if the single sign-on is disabled.
+	oidc     *oidc.Provider
+	oidcConf config.OIDC
+	// apiKeyConf bounds the API keys of the service accounts.
+	apiKeyConf config.APIKey
+	// bootstrap is the admin seeded at the start if there's no admin.
+	bootstrap config.Bootstrap
 }
 
 // New create an external service interface
-func New(db *sql.DB) *Controller {
+func New(repo model.Repository, jwtConf config.JWT, loginConf config.Login, passwordConf config.Password) *Controller {
 	c := &Controller{
-		db: db,
+		repo:          repo,
+		jwtConf:       jwtConf,
+		loginConf:     loginConf,
+		sendByIP:      ratelimit.New(20, time.Hour),
+		sendByMobile:  ratelimit.New(5, time.Hour),
+		checkByMobile: ratelimit.New(5, 15*time.Minute),
+		checkByAdmin:  ratelimit.New(5, 15*time.Minute),
+		sendByAdmin:   ratelimit.New(5, time.Hour),
 	}
 	var err error
 	c.JWT, err = c.newJWTMiddleware()
 	if err != nil {
 		log.Fatal(err)
 	}
+	c.policy, err = salt.NewPolicy(passwordConf)
+	if err != nil {
+		log.Fatal(err)
+	}
 	return c
 }
 
-// RegisterRouter register router. It fatal because there is no service if register failed.
-func (con *Controller) RegisterRouter(r gin.IRouter) {
+// Migrations create the database and tables.
+func (con *Controller) Migrations() []migrate.Migration {
+	return migrate.Of(con.repo)
+}
+
+// RegisterPublicRouter register login, refresh token and logout, they are reachable
+// without token, the latter two check the token themselves, so do the profile
+// and TOTP of the admin itself. An admin could login by the confirmed email,
+// which is confirmed by the token mailed. If smservice is enabled, an admin could
+// login or reset the password by a code sent to the mobile. If the single
+// sign-on is enabled, an admin could login by the OpenID provider. An admin
+// who must change the password could reach nothing else but /me/password.
+func (con *Controller) RegisterPublicRouter(r gin.IRouter) {
 	if r == nil {
 		log.Fatal("[InitRouter]: server is nil")
 	}
-	err := mysql.CreateDatabase(con.db)
-	if err != nil {
-		log.Fatal(err)
+
+	r.POST("/login", con.JWT.LoginHandler)
+	r.POST("/login/totp", con.loginByTOTP)
+	r.POST("/login/email", con.JWT.LoginBy(func(ctx *gin.Context) (interface{}, error) {
+		return con.LoginByEmail(ctx)
+	}))
+	r.GET("/email/confirm", con.confirmEmail)
+	r.POST("/email/confirm", con.confirmEmail)
+	r.GET("/refresh_token", con.JWT.RefreshHandler)
+	r.POST("/logout", con.JWT.LogoutHandler)
+	r.GET("/.well-known/jwks.json", con.jwks)
+
+	if con.oidc != nil {
+		r.GET("/oidc/login", con.oidcLogin)
+		r.GET("/oidc/callback", con.JWT.LoginBy(func(ctx *gin.Context) (interface{}, error) {
+			return con.LoginByOIDC(ctx)
+		}))
 	}
 
-	err = mysql.CreateTable(con.db, &name, &password)
-	if err != nil {
-		log.Fatal(err)
+	r.POST("/me/password", con.JWT.MiddlewareFunc(), con.checkActive(true), con.modifyOwnPassword)
+
+	// the admin enrolls the two-factor authentication of itself without permission.
+	self := r.Group("/totp", con.JWT.MiddlewareFunc(), con.CheckActive())
+	self.POST("/enroll", con.enrollTOTP)
+	self.POST("/confirm", con.confirmTOTP)
+	self.POST("/disable", con.disableTOTP)
+
+	// the admin modifies its own profile without permission.
+	me := r.Group("/me", con.JWT.MiddlewareFunc(), con.CheckActive())
+	me.GET("", con.profile)
+	me.POST("/email", con.modifyOwnEmail)
+	me.POST("/mobile", con.modifyOwnMobile)
+	me.POST("/mobile/confirm", con.confirmOwnMobile)
+	me.GET("/sessions", con.ownSessions)
+	me.POST("/sessions/revoke", con.revokeOwnSession)
+	me.POST("/sessions/revoke_all", con.revokeOwnSessions)
+
+	if con.sms != nil {
+		r.POST("/login/sms/code", con.sendLoginCode)
+		r.POST("/login/sms", con.JWT.LoginBy(func(ctx *gin.Context) (interface{}, error) {
+			return con.LoginBySMS(ctx)
+		}))
+		r.POST("/password/forgot", con.forgotPassword)
+		r.POST("/password/verify", con.verifyPasswordCode)
+		r.POST("/password/reset", con.resetPassword)
+	}
+}
+
+// jwks answers the public keys verifying the token.
+func (con *Controller) jwks(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, con.JWT.Keys.JWKS())
+}
+
+// RegisterRouter register router. It fatal because there is no service if register failed.
+func (con *Controller) RegisterRouter(r gin.IRouter) {
+	if r == nil {
+		log.Fatal("[InitRouter]: server is nil")
 	}
 
 	// userAuth crud API
 	r.POST("/create", con.create)
-	r.POST("/modify/email", con.modifyEmail)
-	r.POST("/modify/mobile", con.modifyMobile)
-	r.POST("/modify/password", con.modifyPassword)
 	r.POST("/modify/active", con.modifyAdminActive)
+	r.GET("/login/history", con.loginHistory)
+	r.GET("/lockouts", con.lockouts)
+	r.POST("/lockouts/clear", con.clearLockout)
+	r.POST("/totp/remove", con.removeTOTP)
+	r.GET("/admin/list", con.listAdmins)
+	r.GET("/admin/search", con.searchAdmin)
+	r.GET("/admin/detail", con.adminDetail)
+	// modify another admin, the admin itself modifies by /me.
+	r.POST("/admin/email", con.modifyEmail)
+	r.POST("/admin/mobile", con.modifyMobile)
+	r.POST("/admin/mobile/confirm", con.confirmMobile)
+	r.POST("/admin/password", con.resetAdminPassword)
+	r.POST("/admin/tenant", con.modifyTenant)
+	r.GET("/admin/sessions", con.adminSessions)
+	r.POST("/admin/sessions/revoke", con.revokeAdminSession)
+
+	// the service accounts and their keys.
+	if con.JWT.APIKey != nil {
+		r.POST("/service/create", con.createService)
+		r.POST("/apikey/create", con.createAPIKey)
+		r.GET("/apikey/list", con.apiKeys)
+		r.POST("/apikey/revoke", con.revokeAPIKey)
+	}
+}
+
+type createRequest struct {
+	Name     string `json:"name"      binding:"required,alphanum,min=5,max=30"`
+	Password string `json:"password"  binding:"omitempty,min=5,max=30"`
+	// MustChangePassword is always true if the password is generated.
+	MustChangePassword bool `json:"must_change_password"`
+	// Tenant is the organization of the admin, carried by the token.
+	Tenant string `json:"tenant"  binding:"printascii,max=64"`
+}
+
+type createResponse struct {
+	Status  int    `json:"status"`
+	AdminID uint32 `json:"admin_id"`
+	// Password is answered only if it's generated.
+	Password string `json:"password,omitempty"`
 }
 
+// create creates an admin, a random password is generated if it's empty,
+// which must be changed at the first login.
 func (con *Controller) create(ctx *gin.Context) {
-	var (
-		admin struct {
-			Name     string `json:"name"      binding:"required,alphanum,min=5,max=30"`
-			Password string `json:"password"  binding:"omitempty,min=5,max=30"`
-		}
-	)
+	var admin createRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	//Default password
+	resp := createResponse{Status: http.StatusOK}
 	if admin.Password == "" {
-		admin.Password = "111111"
+		if admin.Password, err = con.policy.Generate(); err != nil {
+			ctx.Error(errs.Internal(err))
+			return
+		}
+		resp.Password = admin.Password
+		admin.MustChangePassword = true
 	}
 
-	err = mysql.CreateAdmin(con.db, &admin.Name, &admin.Password)
+	if err = con.policy.Check(admin.Password); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	err = con.repo.CreateAdmin(&admin.Name, &admin.Password)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+	a, err := con.repo.AdminByName(admin.Name)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	resp.AdminID = a.ID
+
+	if admin.MustChangePassword {
+		if err = con.repo.SetMustChangePassword(a.ID, true); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+	if admin.Tenant != "" {
+		if err = con.repo.ModifyTenant(a.ID, admin.Tenant); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type modifyEmailRequest struct {
+	AdminID uint32 `json:"admin_id"    binding:"required"`
+	Email   string `json:"email"       binding:"required,email"`
 }
 
+// modifyEmail mails the link confirming the new email of the admin.
 func (con *Controller) modifyEmail(ctx *gin.Context) {
-	var (
-		admin struct {
-			AdminID uint32 `json:"admin_id"    binding:"required"`
-			Email   string `json:"email"       binding:"required,email"`
-		}
-	)
+	var admin modifyEmailRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyEmail(con.db, admin.AdminID, &admin.Email)
+	err = con.requestEmail(admin.AdminID, admin.Email)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type modifyMobileRequest struct {
+	AdminID uint32 `json:"admin_id"     binding:"required"`
+	Mobile  string `json:"mobile"       binding:"required,numeric,len=11"`
+}
+
+// modifyMobile sends a code to the new mobile of the admin, it's not modified until confirmed.
 func (con *Controller) modifyMobile(ctx *gin.Context) {
-	var (
-		admin struct {
-			AdminID uint32 `json:"admin_id"     binding:"required"`
-			Mobile  string `json:"mobile"       binding:"required,numeric,len=11"`
-		}
-	)
+	var admin modifyMobileRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyMobile(con.db, admin.AdminID, &admin.Mobile)
+	err = con.sendMobileCode(ctx.ClientIP(), admin.AdminID, admin.Mobile)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
-func (con *Controller) modifyPassword(ctx *gin.Context) {
-	var (
-		admin struct {
-			AdminID     uint32 `json:"admin_id"       binding:"required"`
-			Password    string `json:"password"       binding:"printascii,min=6,max=30"`
-			NewPassword string `json:"new_password"   binding:"printascii,min=6,max=30"`
-			Confirm     string `json:"confirm"        binding:"printascii,min=6,max=30"`
-		}
-	)
+type confirmMobileRequest struct {
+	AdminID uint32 `json:"admin_id"     binding:"required"`
+	Mobile  string `json:"mobile"       binding:"required,numeric,len=11"`
+	Code    string `json:"code"         binding:"required,numeric"`
+}
+
+// confirmMobile modifies the mobile of the admin by the code sent to it, the
+// sessions of the admin are revoked.
+func (con *Controller) confirmMobile(ctx *gin.Context) {
+	var admin confirmMobileRequest
 
 	err := ctx.ShouldBind(&admin)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	// the session modifying its own mobile is kept.
+	var except string
+	if id, err := con.GetID(ctx); err == nil && id == admin.AdminID {
+		except = currentSession(ctx)
+	}
+
+	err = con.changeMobile(admin.AdminID, admin.Mobile, admin.Code, except)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
 		return
 	}
 
-	if admin.NewPassword == admin.Password {
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type modifyTenantRequest struct {
+	AdminID uint32 `json:"admin_id"  binding:"required"`
+	// Tenant is empty if the admin belongs to no organization.
+	Tenant string `json:"tenant"  binding:"printascii,max=64"`
+}
+
+// modifyTenant sets the organization of an admin, the tokens carrying the
+// former one are revoked.
+func (con *Controller) modifyTenant(ctx *gin.Context) {
+	var admin modifyTenantRequest
+
+	err := ctx.ShouldBind(&admin)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	err = con.repo.ModifyTenant(admin.AdminID, admin.Tenant)
+	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusNotAcceptable, gin.H{"status": http.StatusNotAcceptable})
 		return
 	}
 
-	if admin.NewPassword != admin.Confirm {
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type resetAdminPasswordRequest struct {
+	AdminID  uint32 `json:"admin_id"  binding:"required"`
+	Password string `json:"password"  binding:"printascii,min=6,max=30"`
+	Confirm  string `json:"confirm"   binding:"printascii,min=6,max=30"`
+	// MustChangePassword makes the admin change the password reset at the next login.
+	MustChangePassword bool `json:"must_change_password"`
+}
+
+// resetAdminPassword sets the password of another admin without the current one.
+func (con *Controller) resetAdminPassword(ctx *gin.Context) {
+	var admin resetAdminPasswordRequest
+
+	err := ctx.ShouldBind(&admin)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if admin.Password != admin.Confirm {
+		ctx.Error(errPasswordNotConfirmed)
+		return
+	}
+
+	if err = con.policy.Check(admin.Password); err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusConflict, gin.H{"status": http.StatusConflict})
 		return
 	}
 
-	err = mysql.ModifyPassword(con.db, admin.AdminID, &admin.Password, &admin.NewPassword)
+	err = con.repo.ResetPassword(admin.AdminID, &admin.Password)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
+	if admin.MustChangePassword {
+		if err = con.repo.SetMustChangePassword(admin.AdminID, true); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type modifyAdminActiveRequest struct {
+	CheckID     uint32 `json:"check_id"    binding:"required"`
+	CheckActive bool   `json:"check_active"`
+}
+
 func (con *Controller) modifyAdminActive(ctx *gin.Context) {
-	var (
-		admin struct {
-			CheckID     uint32 `json:"check_id"    binding:"required"`
-			CheckActive bool   `json:"check_active"`
-		}
-	)
+	var admin modifyAdminActiveRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
-		ctx.Error(err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest})
+		ctx.Error(errs.Bind(err))
 		return
 	}
 
-	err = mysql.ModifyAdminActive(con.db, admin.CheckID, admin.CheckActive)
+	err = con.repo.ModifyAdminActive(admin.CheckID, admin.CheckActive)
 	if err != nil {
 		ctx.Error(err)
-		ctx.JSON(http.StatusBadGateway, gin.H{"status": http.StatusBadGateway})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
 }
 
+type loginRequest struct {
+	Name     string `json:"name"      binding:"required,alphanum,min=5,max=30"`
+	Password string `json:"password"  binding:"printascii,min=6,max=30"`
+}
+
 //Login JWT validation
 func (con *Controller) Login(ctx *gin.Context) (uint32, error) {
-	var (
-		admin struct {
-			Name     string `json:"name"      binding:"required,alphanum,min=5,max=30"`
-			Password string `json:"password"  binding:"printascii,min=6,max=30"`
-		}
-	)
+	var admin loginRequest
 
 	err := ctx.ShouldBind(&admin)
 	if err != nil {
+		return 0, errs.Bind(err)
+	}
+
+	ip := ctx.ClientIP()
+	if err = con.throttle(ctx, admin.Name, ip); err != nil {
 		return 0, err
 	}
 
-	ID, err := mysql.Login(con.db, &admin.Name, &admin.Password)
+	ID, err := con.repo.Login(&admin.Name, &admin.Password)
+	if rerr := con.settleLogin(admin.Name, ip, ID, err); rerr != nil {
+		return 0, rerr
+	}
 	if err != nil {
 		return 0, err
 	}
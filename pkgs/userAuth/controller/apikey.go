@@ -0,0 +1,298 @@
+package controller
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// apiKeyPrefix starts the keys, like shower_<id>_<secret>.
+	apiKeyPrefix = "shower"
+	// apiKeyClaim is the claim of the key ID in the request authenticated by it.
+	apiKeyClaim = "api_key"
+	// the last use of a key is recorded once in the interval.
+	touchInterval = time.Minute
+)
+
+var (
+	errAPIKey       = errs.Unauthorized("api key is invalid or expired")
+	errAPIKeyScope  = errs.Forbidden("the api key is not scoped for the path")
+	errNotService   = errs.Validation("the admin is not a service account")
+	errAPIKeyExpire = errs.Validation("the api key expires too late")
+)
+
+// UseAPIKeys authenticates the service accounts by the API keys in the
+// header of conf, the keys couldn't be created without it.
+func (con *Controller) UseAPIKeys(conf config.APIKey) {
+	con.apiKeyConf = conf
+	con.JWT.APIKeyHeader = conf.Header
+	con.JWT.APIKey = con.authenticateKey
+}
+
+// authenticateKey returns the claims of the service account of the key,
+// granted and checked by the same middlewares as the token.
+func (con *Controller) authenticateKey(ctx *gin.Context, raw string) (jwt.MapClaims, error) {
+	parts := strings.SplitN(raw, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return nil, errAPIKey
+	}
+
+	k, err := con.repo.APIKey(parts[1])
+	if err == model.ErrAPIKeyNotFound {
+		return nil, errAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(hashSecret(parts[2])), []byte(k.Hash)) != 1 ||
+		k.Revoked || now.After(k.ExpiresAt) {
+		return nil, errAPIKey
+	}
+
+	if !inScope(k.Scopes, ctx.Request.URL.Path) {
+		return nil, errAPIKeyScope
+	}
+
+	if now.Sub(k.LastUsed) >= touchInterval {
+		if err = con.repo.TouchAPIKey(k.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	claims := jwt.MapClaims{
+		con.JWT.IdentityKey: float64(k.AdminID),
+		apiKeyClaim:         k.ID,
+	}
+	if err = con.grant(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// inScope reports whether a scope is path, or a scope ending with / contains it.
+func inScope(scopes []string, path string) bool {
+	for _, s := range scopes {
+		if s == path || strings.HasSuffix(s, "/") && strings.HasPrefix(path, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+type createServiceRequest struct {
+	Name string `json:"name"  binding:"required,alphanum,min=5,max=30"`
+}
+
+type createServiceResponse struct {
+	Status  int    `json:"status"`
+	AdminID uint32 `json:"admin_id"`
+}
+
+// createService creates a service account, whose roles are assigned by
+// permission like an admin.
+func (con *Controller) createService(ctx *gin.Context) {
+	var req createServiceRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if err = con.repo.CreateServiceAccount(req.Name); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	a, err := con.repo.AdminByName(req.Name)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createServiceResponse{Status: http.StatusOK, AdminID: a.ID})
+}
+
+type createAPIKeyRequest struct {
+	AdminID uint32 `json:"admin_id"  binding:"required"`
+	Name    string `json:"name"      binding:"required,max=64"`
+	// Scopes are the paths the key could call, a scope ending with / matches the paths under it.
+	Scopes []string `json:"scopes"  binding:"required,min=1,max=32,dive,startswith=/,max=255,excludesall= "`
+	// ExpireDays is how long the key is valid, up to api_key.max_expire.
+	ExpireDays int `json:"expire_days"  binding:"required,min=1"`
+}
+
+type apiKeyResponse struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	CreatedAt string   `json:"created_at"`
+	ExpiresAt string   `json:"expires_at"`
+	// LastUsed is empty if the key is never used.
+	LastUsed string `json:"last_used,omitempty"`
+	Expired  bool   `json:"expired"`
+}
+
+type createAPIKeyResponse struct {
+	Status int `json:"status"`
+	// Key is answered only once, it's sent in the header api_key.header.
+	Key    string         `json:"key"`
+	APIKey apiKeyResponse `json:"api_key"`
+}
+
+// createAPIKey creates a key of a service account, only the hash of the
+// secret is kept.
+func (con *Controller) createAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	expire := time.Duration(req.ExpireDays) * 24 * time.Hour
+	if expire > con.apiKeyConf.MaxExpire || expire <= 0 {
+		ctx.Error(errAPIKeyExpire)
+		return
+	}
+
+	a, err := con.repo.AdminByID(req.AdminID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if !a.Service {
+		ctx.Error(errNotService)
+		return
+	}
+
+	id := make([]byte, 8)
+	secret := make([]byte, 32)
+	if _, err = rand.Read(id); err == nil {
+		_, err = rand.Read(secret)
+	}
+	if err != nil {
+		ctx.Error(errs.Internal(err))
+		return
+	}
+
+	now := time.Now()
+	k := &model.APIKey{
+		ID:        hex.EncodeToString(id),
+		AdminID:   a.ID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		CreatedAt: now,
+		ExpiresAt: now.Add(expire),
+	}
+	raw := base64.RawURLEncoding.EncodeToString(secret)
+	k.Hash = hashSecret(raw)
+
+	if err = con.repo.CreateAPIKey(k); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createAPIKeyResponse{
+		Status: http.StatusOK,
+		Key:    apiKeyPrefix + "_" + k.ID + "_" + raw,
+		APIKey: toAPIKeyResponse(k, now),
+	})
+}
+
+type apiKeysRequest struct {
+	AdminID uint32 `form:"admin_id"  binding:"required"`
+}
+
+type apiKeysResponse struct {
+	Status  int              `json:"status"`
+	APIKeys []apiKeyResponse `json:"api_keys"`
+}
+
+// apiKeys answers the keys of a service account not revoked, without the secrets.
+func (con *Controller) apiKeys(ctx *gin.Context) {
+	var req apiKeysRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if _, err = con.repo.AdminByID(req.AdminID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	keys, err := con.repo.APIKeys(req.AdminID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	now := time.Now()
+	resp := apiKeysResponse{Status: http.StatusOK, APIKeys: make([]apiKeyResponse, 0, len(keys))}
+	for _, k := range keys {
+		resp.APIKeys = append(resp.APIKeys, toAPIKeyResponse(k, now))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type revokeAPIKeyRequest struct {
+	AdminID uint32 `json:"admin_id"  binding:"required"`
+	KeyID   string `json:"key_id"    binding:"required"`
+}
+
+// revokeAPIKey revokes a key of a service account, it's rejected at once.
+func (con *Controller) revokeAPIKey(ctx *gin.Context) {
+	var req revokeAPIKeyRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if err = con.repo.RevokeAPIKey(req.AdminID, req.KeyID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+func toAPIKeyResponse(k *model.APIKey, now time.Time) apiKeyResponse {
+	resp := apiKeyResponse{
+		ID:        k.ID,
+		Name:      k.Name,
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt.Format(time.RFC3339),
+		ExpiresAt: k.ExpiresAt.Format(time.RFC3339),
+		Expired:   now.After(k.ExpiresAt),
+	}
+	if !k.LastUsed.IsZero() {
+		resp.LastUsed = k.LastUsed.Format(time.RFC3339)
+	}
+	return resp
+}
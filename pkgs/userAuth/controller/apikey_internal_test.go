@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/pkgs/userAuth/model/memory"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/salt"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	keyID     = "0123456789abcdef"
+	keySecret = "secret"
+	keyPath   = "/api/v1/pet/info/id"
+)
+
+// keyed returns a controller whose service account has a key valid until expire.
+func keyed(t *testing.T, expire time.Time) (*Controller, model.Repository) {
+	t.Helper()
+
+	conf := config.Default()
+	conf.JWT.Key = "test"
+
+	repo := memory.NewRepository(salt.Default())
+	con := New(repo, conf.JWT, conf.Login, conf.Password)
+	con.UseAPIKeys(conf.APIKey)
+
+	if err := repo.CreateServiceAccount("reporter"); err != nil {
+		t.Fatal(err)
+	}
+	a, err := repo.AdminByName("reporter")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.CreateAPIKey(&model.APIKey{
+		ID:        keyID,
+		AdminID:   a.ID,
+		Hash:      hashSecret(keySecret),
+		Scopes:    []string{keyPath},
+		CreatedAt: time.Now(),
+		ExpiresAt: expire,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return con, repo
+}
+
+func keyContext() *gin.Context {
+	ctx := testContext()
+	ctx.Request = httptest.NewRequest(http.MethodGet, keyPath, nil)
+	return ctx
+}
+
+func TestAuthenticateExpiredKey(t *testing.T) {
+	con, _ := keyed(t, time.Now().Add(-time.Second))
+
+	if _, err := con.authenticateKey(keyContext(), apiKeyPrefix+"_"+keyID+"_"+keySecret); err != errAPIKey {
+		t.Fatalf("error %v, want %v", err, errAPIKey)
+	}
+}
+
+func TestAuthenticateKeyByHash(t *testing.T) {
+	con, _ := keyed(t, time.Now().Add(time.Hour))
+
+	// the hash kept in the database isn't the secret.
+	if _, err := con.authenticateKey(keyContext(), apiKeyPrefix+"_"+keyID+"_"+hashSecret(keySecret)); err != errAPIKey {
+		t.Fatalf("error %v, want %v", err, errAPIKey)
+	}
+
+	claims, err := con.authenticateKey(keyContext(), apiKeyPrefix+"_"+keyID+"_"+keySecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims[apiKeyClaim] != keyID {
+		t.Fatalf("claims %v, want the key %s", claims, keyID)
+	}
+}
+
+func TestAuthenticateKeyTouched(t *testing.T) {
+	con, repo := keyed(t, time.Now().Add(time.Hour))
+	raw := apiKeyPrefix + "_" + keyID + "_" + keySecret
+
+	lastUsed := func() time.Time {
+		t.Helper()
+
+		k, err := repo.APIKey(keyID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k.LastUsed
+	}
+
+	if _, err := con.authenticateKey(keyContext(), raw); err != nil {
+		t.Fatal(err)
+	}
+	first := lastUsed()
+	if first.IsZero() {
+		t.Fatal("the use is not recorded")
+	}
+
+	if _, err := con.authenticateKey(keyContext(), raw); err != nil {
+		t.Fatal(err)
+	}
+	if !lastUsed().Equal(first) {
+		t.Fatal("the use is recorded again in a minute")
+	}
+
+	past := time.Now().Add(-touchInterval)
+	if err := repo.TouchAPIKey(keyID, past); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := con.authenticateKey(keyContext(), raw); err != nil {
+		t.Fatal(err)
+	}
+	if !lastUsed().After(past) {
+		t.Fatal("the use isn't recorded after a minute")
+	}
+}
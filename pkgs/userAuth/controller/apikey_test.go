@@ -0,0 +1,97 @@
+package controller_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abserari/shower/pkgs/testkit"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	servicePath   = "/api/v1/userAuth/service/create"
+	createKeyPath = "/api/v1/userAuth/apikey/create"
+	revokeKeyPath = "/api/v1/userAuth/apikey/revoke"
+	roleListPath  = "/api/v1/permission/getallrole"
+	addRolePath   = "/api/v1/permission/addrole"
+	relationPath  = "/api/v1/permission/addrelation"
+	scopeMessage  = "the api key is not scoped for the path"
+)
+
+type createdKey struct {
+	Key    string `json:"key"`
+	APIKey struct {
+		ID string `json:"id"`
+	} `json:"api_key"`
+}
+
+// serviceKey creates a service account having roles, and its key of scopes.
+func serviceKey(t *testing.T, kit *testkit.Kit, name string, scopes []string, roles ...uint32) (uint32, createdKey) {
+	t.Helper()
+
+	var service struct {
+		AdminID uint32 `json:"admin_id"`
+	}
+	kit.Root().Post(servicePath, map[string]string{"name": name}).OK(&service)
+	for _, rid := range roles {
+		kit.Root().Post(relationPath, map[string]interface{}{"admin_id": service.AdminID, "role_id": rid}).OK(nil)
+	}
+
+	var key createdKey
+	kit.Root().Post(createKeyPath, map[string]interface{}{
+		"admin_id":    service.AdminID,
+		"name":        "test",
+		"scopes":      scopes,
+		"expire_days": 1,
+	}).OK(&key)
+	return service.AdminID, key
+}
+
+func TestAPIKeyScope(t *testing.T) {
+	kit := testkit.New(t)
+	role := kit.CreateRole("rolereader", roleListPath)
+	_, key := serviceKey(t, kit, "reporter", []string{"/api/v1/permission/"}, role)
+
+	s := kit.KeySession(key.Key)
+	s.Post(roleListPath, nil).OK(nil)
+
+	// in the scope, but not granted to the roles of the service account.
+	if body := s.Post(addRolePath, map[string]string{"name": "stolen", "intro": "x"}).Error(errs.KindForbidden); body.Message == scopeMessage {
+		t.Fatal("the permission of the service account is not checked")
+	}
+
+	if body := s.Get(mePath).Error(errs.KindForbidden); body.Message != scopeMessage {
+		t.Fatalf("a path out of the scope is answered %q", body.Message)
+	}
+}
+
+func TestAPIKeyRejected(t *testing.T) {
+	kit := testkit.New(t)
+	role := kit.CreateRole("rolereader", roleListPath)
+	id, key := serviceKey(t, kit, "reporter", []string{roleListPath}, role)
+
+	parts := strings.SplitN(key.Key, "_", 3)
+	for _, raw := range []string{
+		parts[0] + "_" + parts[1] + "_" + strings.Repeat("A", len(parts[2])),
+		parts[0] + "_unknown_" + parts[2],
+		"bearer_" + parts[1] + "_" + parts[2],
+	} {
+		kit.KeySession(raw).Post(roleListPath, nil).Error(errs.KindUnauthorized)
+	}
+
+	kit.KeySession(key.Key).Post(roleListPath, nil).OK(nil)
+	kit.Root().Post(revokeKeyPath, map[string]interface{}{"admin_id": id, "key_id": key.APIKey.ID}).OK(nil)
+	kit.KeySession(key.Key).Post(roleListPath, nil).Error(errs.KindUnauthorized)
+}
+
+func TestAPIKeyOfAdmin(t *testing.T) {
+	kit := testkit.New(t)
+	admin := kit.LoginAs("notservice")
+
+	kit.Root().Post(createKeyPath, map[string]interface{}{
+		"admin_id":    admin.AdminID,
+		"name":        "test",
+		"scopes":      []string{"/"},
+		"expire_days": 1,
+	}).Error(errs.KindValidation)
+}
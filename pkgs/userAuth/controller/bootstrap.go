@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"log"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/config"
+)
+
+// UseBootstrap seeds the admin of conf at the start if there's no admin. The
+// config requires its name, a controller used without UseBootstrap seeds nothing.
+func (con *Controller) UseBootstrap(conf config.Bootstrap) {
+	con.bootstrap = conf
+}
+
+// seed creates the bootstrap admin once. Its password is generated and
+// printed if it's not configured, either must be changed at the first login.
+func (con *Controller) seed() error {
+	if con.bootstrap.Name == "" {
+		return nil
+	}
+
+	_, total, err := con.repo.SearchAdmins(&model.AdminQuery{Limit: 1})
+	if err != nil || total > 0 {
+		return err
+	}
+
+	password := con.bootstrap.Password
+	if password == "" {
+		if password, err = con.policy.Generate(); err != nil {
+			return err
+		}
+	} else if err = con.policy.Check(password); err != nil {
+		return err
+	}
+
+	if err = con.repo.CreateAdmin(&con.bootstrap.Name, &password); err != nil {
+		return err
+	}
+
+	a, err := con.repo.AdminByName(con.bootstrap.Name)
+	if err != nil {
+		return err
+	}
+	if err = con.repo.SetMustChangePassword(a.ID, true); err != nil {
+		return err
+	}
+
+	if con.bootstrap.Password != "" {
+		log.Printf("[userAuth]: admin %s is created with the configured password, which must be changed at the first login", a.Name)
+		return nil
+	}
+	log.Printf("[userAuth]: admin %s is created with the one-time password %s, which must be changed at the first login", a.Name, password)
+	return nil
+}
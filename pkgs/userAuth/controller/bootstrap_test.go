@@ -0,0 +1,29 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/abserari/shower/pkgs/testkit"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const ownPasswordPath = "/api/v1/userAuth/me/password"
+
+func TestBootstrapPasswordMustChange(t *testing.T) {
+	const configured, changed = "configured-1", "changed-12"
+	kit := testkit.New(t, func(c *config.Config) {
+		c.Bootstrap.Password = configured
+	})
+
+	s := kit.Login(testkit.RootName, configured)
+	s.Get(mePath).Error(errs.KindForbidden)
+
+	s.Post(ownPasswordPath, map[string]string{
+		"password":     configured,
+		"new_password": changed,
+		"confirm":      changed,
+	}).OK(nil)
+
+	kit.Login(testkit.RootName, changed).Get(mePath).OK(nil)
+}
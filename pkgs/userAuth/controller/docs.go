@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/abserari/shower/utils/openapi"
+	"github.com/abserari/shower/utils/token"
+)
+
+// loginResponse is the token answered by the login, only used by the docs.
+type loginResponse struct {
+	Code   int    `json:"code"`
+	Expire string `json:"expire"`
+	Token  string `json:"token"`
+}
+
+// logoutResponse is answered by the logout, only used by the docs.
+type logoutResponse struct {
+	Code int `json:"code"`
+}
+
+// Operations describes the routes of the module in the OpenAPI document.
+func (con *Controller) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/login", Summary: "Login by name and password", Request: loginRequest{}, Response: loginResponse{}},
+		{Method: http.MethodGet, Path: "/refresh_token", Summary: "Refresh the token", Response: loginResponse{}, Token: true},
+		{Method: http.MethodPost, Path: "/logout", Summary: "Revoke the token", Response: logoutResponse{}, Token: true},
+		{Method: http.MethodPost, Path: "/login/totp", Summary: "Login by the challenge and a TOTP or recovery code", Request: loginByTOTPRequest{}, Response: loginResponse{}},
+		{Method: http.MethodPost, Path: "/totp/enroll", Summary: "Generate a TOTP secret of the admin itself", Response: enrollTOTPResponse{}, Token: true},
+		{Method: http.MethodPost, Path: "/totp/confirm", Summary: "Enable the TOTP by a code for the recovery codes", Request: confirmTOTPRequest{}, Response: confirmTOTPResponse{}, Token: true},
+		{Method: http.MethodPost, Path: "/totp/disable", Summary: "Disable the TOTP of the admin itself by a code", Request: disableTOTPRequest{}, Response: openapi.StatusBody{}, Token: true},
+		{Method: http.MethodPost, Path: "/login/email", Summary: "Login by the confirmed email and password", Request: emailLoginRequest{}, Response: loginResponse{}},
+		{Method: http.MethodGet, Path: "/email/confirm", Summary: "Confirm the pending email by the token of the link mailed", Request: confirmEmailRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/email/confirm", Summary: "Confirm the pending email by the token mailed", Request: confirmEmailRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodGet, Path: "/.well-known/jwks.json", Summary: "Public keys verifying the token", Response: token.JWKSet{}},
+		{Method: http.MethodGet, Path: "/oidc/login", Summary: "Redirect to the OpenID provider to login"},
+		{Method: http.MethodGet, Path: "/oidc/callback", Summary: "Login by the code redirected back from the OpenID provider", Request: oidcCallbackRequest{}, Response: loginResponse{}},
+		{Method: http.MethodPost, Path: "/login/sms/code", Summary: "Send a code to the mobile to login", Request: sendLoginCodeRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/login/sms", Summary: "Login by mobile and code", Request: smsLoginRequest{}, Response: loginResponse{}},
+		{Method: http.MethodPost, Path: "/password/forgot", Summary: "Send a code to the mobile to reset the password", Request: forgotPasswordRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/password/verify", Summary: "Check the code for a reset token", Request: verifyPasswordCodeRequest{}, Response: resetTokenResponse{}},
+		{Method: http.MethodPost, Path: "/password/reset", Summary: "Reset the password by the reset token", Request: resetPasswordRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/create", Summary: "Create an admin, the password is generated if it's empty", Request: createRequest{}, Response: createResponse{}},
+		{Method: http.MethodGet, Path: "/me", Summary: "Get the admin itself with the roles", Response: adminDetailResponse{}, Token: true},
+		{Method: http.MethodPost, Path: "/me/email", Summary: "Mail a link confirming the new email of the admin itself", Request: modifyOwnEmailRequest{}, Response: openapi.StatusBody{}, Token: true},
+		{Method: http.MethodPost, Path: "/me/mobile", Summary: "Send a code to the new mobile of the admin itself", Request: modifyOwnMobileRequest{}, Response: openapi.StatusBody{}, Token: true},
+		{Method: http.MethodPost, Path: "/me/mobile/confirm", Summary: "Modify the mobile of the admin itself by the code sent to it", Request: confirmOwnMobileRequest{}, Response: openapi.StatusBody{}, Token: true},
+		{Method: http.MethodPost, Path: "/me/password", Summary: "Modify the password of the admin itself by the current one, also if it must be changed", Request: modifyOwnPasswordRequest{}, Response: openapi.StatusBody{}, Token: true},
+		{Method: http.MethodGet, Path: "/me/sessions", Summary: "List the sessions of the admin itself", Response: sessionsResponse{}, Token: true},
+		{Method: http.MethodPost, Path: "/me/sessions/revoke", Summary: "Revoke a session of the admin itself", Request: revokeOwnSessionRequest{}, Response: openapi.StatusBody{}, Token: true},
+		{Method: http.MethodPost, Path: "/me/sessions/revoke_all", Summary: "Revoke every session of the admin itself, or the others", Request: revokeOwnSessionsRequest{}, Response: openapi.StatusBody{}, Token: true},
+		{Method: http.MethodPost, Path: "/admin/email", Summary: "Mail a link confirming the new email of an admin", Request: modifyEmailRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/admin/mobile", Summary: "Send a code to the new mobile of an admin", Request: modifyMobileRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/admin/mobile/confirm", Summary: "Modify the mobile of an admin by the code sent to it", Request: confirmMobileRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/admin/password", Summary: "Set the password of an admin", Request: resetAdminPasswordRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/admin/tenant", Summary: "Set the tenant of an admin, revoking the tokens", Request: modifyTenantRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodGet, Path: "/admin/sessions", Summary: "List the sessions of an admin", Request: adminSessionsRequest{}, Response: sessionsResponse{}},
+		{Method: http.MethodPost, Path: "/admin/sessions/revoke", Summary: "Revoke a session of an admin, or all of them", Request: revokeAdminSessionRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/modify/active", Summary: "Activate or deactivate an admin", Request: modifyAdminActiveRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodGet, Path: "/login/history", Summary: "Latest logins by name and password", Request: loginHistoryRequest{}, Response: loginHistoryResponse{}},
+		{Method: http.MethodGet, Path: "/lockouts", Summary: "Names and IPs having failed logins", Response: lockoutsResponse{}},
+		{Method: http.MethodPost, Path: "/lockouts/clear", Summary: "Forget the failed logins of a name or an IP", Request: clearLockoutRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodPost, Path: "/totp/remove", Summary: "Disable the TOTP of an admin", Request: removeTOTPRequest{}, Response: openapi.StatusBody{}},
+		{Method: http.MethodGet, Path: "/admin/list", Summary: "List a page of the admins", Request: listAdminsRequest{}, Response: listAdminsResponse{}},
+		{Method: http.MethodGet, Path: "/admin/search", Summary: "Search the admins by a part of the name, mobile or email", Request: searchAdminsRequest{}, Response: listAdminsResponse{}},
+		{Method: http.MethodGet, Path: "/admin/detail", Summary: "Get an admin with the roles", Request: adminDetailRequest{}, Response: adminDetailResponse{}},
+		{Method: http.MethodPost, Path: "/service/create", Summary: "Create a service account calling the APIs by the API keys", Request: createServiceRequest{}, Response: createServiceResponse{}},
+		{Method: http.MethodPost, Path: "/apikey/create", Summary: "Create a scoped and expiring API key of a service account", Request: createAPIKeyRequest{}, Response: createAPIKeyResponse{}},
+		{Method: http.MethodGet, Path: "/apikey/list", Summary: "List the API keys of a service account", Request: apiKeysRequest{}, Response: apiKeysResponse{}},
+		{Method: http.MethodPost, Path: "/apikey/revoke", Summary: "Revoke an API key of a service account", Request: revokeAPIKeyRequest{}, Response: openapi.StatusBody{}},
+	}
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/mail"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	emailPurpose = "email_confirm"
+	// emailKey is the claim of the email to confirm.
+	emailKey = "email"
+)
+
+var (
+	errNoMailer   = errs.Forbidden("no mailer to confirm the email")
+	errEmailUsed  = errs.Conflict("email already exists")
+	errEmailToken = errs.Unauthorized("confirm token is invalid or expired")
+)
+
+// UseMailer sends the links confirming the email by m, the emails couldn't
+// be modified without a mailer.
+func (con *Controller) UseMailer(m mail.Mailer, conf config.Mail) {
+	con.mail = m
+	con.mailConf = conf
+}
+
+// Mailer returns the mailer set by UseMailer.
+func (con *Controller) Mailer() mail.Mailer {
+	return con.mail
+}
+
+// requestEmail keeps email pending for the admin, and sends the link
+// confirming it to email.
+func (con *Controller) requestEmail(id uint32, email string) error {
+	if con.mail == nil {
+		return errNoMailer
+	}
+
+	a, err := con.repo.AdminByEmail(email)
+	if err == nil && a.ID != id {
+		return errEmailUsed
+	}
+	if err != nil && err != model.ErrNotFound {
+		return err
+	}
+
+	if !con.sendByAdmin.Allow(strconv.FormatUint(uint64(id), 10)) {
+		return errRateLimits
+	}
+
+	if err = con.repo.SetPendingEmail(id, &email); err != nil {
+		return err
+	}
+
+	claims, expire, err := con.JWT.NewClaims(id, con.mailConf.Expire)
+	if err != nil {
+		return errs.Internal(err)
+	}
+	claims[purposeKey] = emailPurpose
+	claims[emailKey] = email
+
+	token, err := con.JWT.Keys.Sign(claims)
+	if err != nil {
+		return errs.Internal(err)
+	}
+
+	link, err := url.Parse(con.mailConf.ConfirmURL)
+	if err != nil {
+		return errs.Internal(err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	err = con.mail.Send(&mail.Message{
+		To:      email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf("Open the link to confirm %s as the email of your account:\n\n%s\n\n"+
+			"Or confirm by the token:\n\n%s\n\nIt expires at %s, ignore this mail if you didn't ask for it.\n",
+			email, link, token, expire.Format(time.RFC1123)),
+	})
+	if err != nil {
+		return errs.Internal(err)
+	}
+
+	return nil
+}
+
+type confirmEmailRequest struct {
+	Token string `json:"token"  form:"token"  binding:"required"`
+}
+
+// confirmEmail replaces the email by the pending one of the token, the
+// token is used once.
+func (con *Controller) confirmEmail(ctx *gin.Context) {
+	var req confirmEmailRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	claims, id, err := con.parsePurposeToken(req.Token, emailPurpose, errEmailToken)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	email, ok := claims[emailKey].(string)
+	if !ok {
+		ctx.Error(errEmailToken)
+		return
+	}
+
+	if err = con.repo.ConfirmEmail(id, email); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err = con.revoke(claims); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type emailLoginRequest struct {
+	Email    string `json:"email"     binding:"required,email"`
+	Password string `json:"password"  binding:"printascii,min=6,max=30"`
+}
+
+// LoginByEmail returns the admin ID if the confirmed email and password match,
+// the failures count for the name of the admin as the login by name.
+func (con *Controller) LoginByEmail(ctx *gin.Context) (uint32, error) {
+	var req emailLoginRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		return 0, errs.Bind(err)
+	}
+
+	// an unknown email is locked by itself.
+	name := req.Email
+	a, err := con.repo.AdminByEmail(req.Email)
+	if err == nil {
+		name = a.Name
+	} else if err != model.ErrNotFound {
+		return 0, err
+	}
+
+	ip := ctx.ClientIP()
+	if err = con.throttle(ctx, name, ip); err != nil {
+		return 0, err
+	}
+
+	var id uint32
+	if a != nil {
+		id, err = con.repo.Login(&a.Name, &req.Password)
+	} else {
+		err = model.ErrLoginFailed
+	}
+	if rerr := con.settleLogin(name, ip, id, err); rerr != nil {
+		return 0, rerr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
@@ -0,0 +1,288 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// the keys of the lockouts.
+	nameKeyPrefix = "name:"
+	ipKeyPrefix   = "ip:"
+	totpKeyPrefix = "totp:"
+
+	defaultHistoryLimit = 50
+)
+
+var (
+	errLocked   = errs.Locked("too many failed logins, it's locked for a while")
+	errSlowDown = errs.RateLimited("too many failed logins, try again later")
+)
+
+// the times to reserve an attempt against the concurrent ones.
+const reserveRetries = 8
+
+// throttle reserves the login of name from ip before checking the password,
+// it's rejected if either is locked or has to wait after the last failure.
+// The reserved attempts are settled by settleLogin.
+func (con *Controller) throttle(ctx *gin.Context, name, ip string) error {
+	reserved := make([]string, 0, 2)
+
+	for _, k := range []struct {
+		key        string
+		delayAfter int
+		lockAfter  int
+	}{
+		{nameKeyPrefix + name, con.loginConf.DelayAfter, con.loginConf.LockAfter},
+		{ipKeyPrefix + ip, con.loginConf.IPDelayAfter, con.loginConf.IPLockAfter},
+	} {
+		if err := con.reserve(ctx, k.key, k.delayAfter, k.lockAfter); err != nil {
+			for _, key := range reserved {
+				if rerr := con.repo.ReleaseLockout(key); rerr != nil {
+					return rerr
+				}
+			}
+			return err
+		}
+		reserved = append(reserved, k.key)
+	}
+
+	return nil
+}
+
+// reserve counts an attempt of key as a failure until it's settled. The
+// lockout is compared and swapped, the concurrent attempts can't pass on
+// the same failures.
+func (con *Controller) reserve(ctx *gin.Context, key string, delayAfter, lockAfter int) error {
+	for i := 0; i < reserveRetries; i++ {
+		now := time.Now()
+		forget := now.Add(-con.loginConf.LockDuration)
+
+		l, err := con.repo.Lockout(key)
+		if err != nil {
+			return err
+		}
+
+		if l != nil && !l.LastFailure.Before(forget) {
+			if now.Before(l.LockedUntil) {
+				retryAfter(ctx, l.LockedUntil.Sub(now))
+				return errLocked
+			}
+			// the attempts being checked may lock it.
+			if l.Failures >= lockAfter {
+				retryAfter(ctx, l.LastFailure.Add(con.loginConf.LockDuration).Sub(now))
+				return errLocked
+			}
+			if next := l.LastFailure.Add(con.delay(l.Failures, delayAfter)); now.Before(next) {
+				retryAfter(ctx, next.Sub(now))
+				return errSlowDown
+			}
+		}
+
+		ok, err := con.repo.ReserveLockout(key, l, now, forget)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	retryAfter(ctx, con.loginConf.Delay)
+	return errSlowDown
+}
+
+// delay is how long to wait after the failures, it doubles from delayAfter failures.
+func (con *Controller) delay(failures, delayAfter int) time.Duration {
+	n := failures - delayAfter
+	if n < 0 {
+		return 0
+	}
+
+	d := con.loginConf.Delay
+	for ; n > 0 && d < con.loginConf.MaxDelay; n-- {
+		d *= 2
+	}
+	if d > con.loginConf.MaxDelay {
+		d = con.loginConf.MaxDelay
+	}
+	return d
+}
+
+// recordLogin adds the login to the history, a success forgets the failures
+// of the name.
+func (con *Controller) recordLogin(name, ip string, id uint32, success bool) error {
+	err := con.repo.AddLoginAttempt(&model.LoginAttempt{
+		Name:    name,
+		IP:      ip,
+		AdminID: id,
+		Success: success,
+		At:      time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if success {
+		return con.repo.ClearLockout(nameKeyPrefix + name)
+	}
+	return nil
+}
+
+// settleLogin settles the login reserved by throttle, err is the result of
+// checking the password. A success forgets the failures of the name and
+// uncounts the IP, a failure keeps the counts and locks either having
+// too many. Neither is counted if the password couldn't be checked.
+func (con *Controller) settleLogin(name, ip string, id uint32, err error) error {
+	switch err {
+	case nil, model.ErrLoginFailed:
+	default:
+		if rerr := con.repo.ReleaseLockout(nameKeyPrefix + name); rerr != nil {
+			return rerr
+		}
+		return con.repo.ReleaseLockout(ipKeyPrefix + ip)
+	}
+
+	if rerr := con.recordLogin(name, ip, id, err == nil); rerr != nil {
+		return rerr
+	}
+	if err == nil {
+		return con.repo.ReleaseLockout(ipKeyPrefix + ip)
+	}
+
+	until := time.Now().Add(con.loginConf.LockDuration)
+	if rerr := con.repo.LockLockout(nameKeyPrefix+name, con.loginConf.LockAfter, until); rerr != nil {
+		return rerr
+	}
+	return con.repo.LockLockout(ipKeyPrefix+ip, con.loginConf.IPLockAfter, until)
+}
+
+func retryAfter(ctx *gin.Context, d time.Duration) {
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+}
+
+type loginHistoryRequest struct {
+	Name  string `form:"name"`
+	Limit int    `form:"limit"  binding:"omitempty,min=1,max=500"`
+}
+
+type loginAttemptResponse struct {
+	ID      uint64 `json:"id"`
+	Name    string `json:"name"`
+	IP      string `json:"ip"`
+	AdminID uint32 `json:"admin_id"`
+	Success bool   `json:"success"`
+	At      string `json:"at"`
+}
+
+type loginHistoryResponse struct {
+	Status   int                    `json:"status"`
+	Attempts []loginAttemptResponse `json:"attempts"`
+}
+
+// loginHistory answers the latest logins of the name, or of everyone.
+func (con *Controller) loginHistory(ctx *gin.Context) {
+	var req loginHistoryRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultHistoryLimit
+	}
+
+	attempts, err := con.repo.LoginAttempts(req.Name, req.Limit)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	resp := loginHistoryResponse{Status: http.StatusOK, Attempts: make([]loginAttemptResponse, 0, len(attempts))}
+	for _, a := range attempts {
+		resp.Attempts = append(resp.Attempts, loginAttemptResponse{
+			ID:      a.ID,
+			Name:    a.Name,
+			IP:      a.IP,
+			AdminID: a.AdminID,
+			Success: a.Success,
+			At:      a.At.Format(time.RFC3339),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type lockoutResponse struct {
+	Key         string `json:"key"`
+	Failures    int    `json:"failures"`
+	LastFailure string `json:"last_failure"`
+	LockedUntil string `json:"locked_until,omitempty"`
+	Locked      bool   `json:"locked"`
+}
+
+type lockoutsResponse struct {
+	Status   int               `json:"status"`
+	Lockouts []lockoutResponse `json:"lockouts"`
+}
+
+// lockouts answers the names, IPs and second factors having recent failures.
+func (con *Controller) lockouts(ctx *gin.Context) {
+	lockouts, err := con.repo.Lockouts()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	now := time.Now()
+	forget := now.Add(-con.loginConf.LockDuration)
+
+	resp := lockoutsResponse{Status: http.StatusOK, Lockouts: make([]lockoutResponse, 0, len(lockouts))}
+	for _, l := range lockouts {
+		if l.LastFailure.Before(forget) {
+			continue
+		}
+
+		r := lockoutResponse{
+			Key:         l.Key,
+			Failures:    l.Failures,
+			LastFailure: l.LastFailure.Format(time.RFC3339),
+			Locked:      now.Before(l.LockedUntil),
+		}
+		if !l.LockedUntil.IsZero() {
+			r.LockedUntil = l.LockedUntil.Format(time.RFC3339)
+		}
+		resp.Lockouts = append(resp.Lockouts, r)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type clearLockoutRequest struct {
+	Key string `json:"key"  binding:"required"`
+}
+
+// clearLockout forgets the failures of a name or an IP, by the key in the lockouts.
+func (con *Controller) clearLockout(ctx *gin.Context) {
+	var req clearLockoutRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if err = con.repo.ClearLockout(req.Key); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
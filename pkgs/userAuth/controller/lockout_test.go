@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/pkgs/userAuth/model/memory"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/salt"
+	"github.com/gin-gonic/gin"
+)
+
+const victim, attacker = "victim", "192.0.2.1"
+
+// locking returns a controller whose logins are changed by configure.
+func locking(configure func(c *config.Login)) (*Controller, model.Repository) {
+	conf := config.Default()
+	conf.JWT.Key = "test"
+	configure(&conf.Login)
+
+	repo := memory.NewRepository(salt.Default())
+	return New(repo, conf.JWT, conf.Login, conf.Password), repo
+}
+
+func testContext() *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	return ctx
+}
+
+func TestThrottleConcurrentLogins(t *testing.T) {
+	con, _ := locking(func(c *config.Login) {})
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		passed int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := con.throttle(testContext(), victim, attacker); err == nil {
+				mu.Lock()
+				passed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if passed != con.loginConf.DelayAfter {
+		t.Fatalf("%d logins checked the password at once, want %d", passed, con.loginConf.DelayAfter)
+	}
+}
+
+func TestSettleLoginLocks(t *testing.T) {
+	con, _ := locking(func(c *config.Login) {
+		c.DelayAfter, c.LockAfter = 100, 3
+	})
+
+	for i := 0; i < con.loginConf.LockAfter; i++ {
+		if err := con.throttle(testContext(), victim, attacker); err != nil {
+			t.Fatalf("failure %d: %v", i, err)
+		}
+		if err := con.settleLogin(victim, attacker, 0, model.ErrLoginFailed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := testContext()
+	if err := con.throttle(ctx, victim, attacker); err != errLocked {
+		t.Fatalf("error %v, want %v", err, errLocked)
+	}
+	if ctx.Writer.Header().Get("Retry-After") == "" {
+		t.Fatal("no Retry-After")
+	}
+}
+
+func TestSettleLoginSucceeds(t *testing.T) {
+	con, repo := locking(func(c *config.Login) {
+		c.DelayAfter = 100
+	})
+
+	for _, err := range []error{model.ErrLoginFailed, model.ErrLoginFailed, nil} {
+		if terr := con.throttle(testContext(), victim, attacker); terr != nil {
+			t.Fatal(terr)
+		}
+		if serr := con.settleLogin(victim, attacker, 1, err); serr != nil {
+			t.Fatal(serr)
+		}
+	}
+
+	if l, _ := repo.Lockout(nameKeyPrefix + victim); l != nil {
+		t.Fatalf("the name has %d failures after a success", l.Failures)
+	}
+	l, err := repo.Lockout(ipKeyPrefix + attacker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l == nil || l.Failures != 2 {
+		t.Fatalf("the IP has %+v, want 2 failures", l)
+	}
+}
+
+func TestSettleLoginUncheckedPassword(t *testing.T) {
+	con, repo := locking(func(c *config.Login) {})
+
+	if err := con.throttle(testContext(), victim, attacker); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.settleLogin(victim, attacker, 0, model.ErrNotFound); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{nameKeyPrefix + victim, ipKeyPrefix + attacker} {
+		if l, _ := repo.Lockout(key); l != nil && l.Failures != 0 {
+			t.Fatalf("%s has %d failures", key, l.Failures)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/abserari/shower/utils/errs"
+	"github.com/gin-gonic/gin"
+)
+
+// profile answers the admin itself with the roles.
+func (con *Controller) profile(ctx *gin.Context) {
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	con.detail(ctx, id)
+}
+
+type modifyOwnEmailRequest struct {
+	Email string `json:"email"  binding:"required,email"`
+}
+
+// modifyOwnEmail mails the link confirming the new email, it's pending until confirmed.
+func (con *Controller) modifyOwnEmail(ctx *gin.Context) {
+	var admin modifyOwnEmailRequest
+
+	err := ctx.ShouldBind(&admin)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	err = con.requestEmail(id, admin.Email)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type modifyOwnMobileRequest struct {
+	Mobile string `json:"mobile"  binding:"required,numeric,len=11"`
+}
+
+// modifyOwnMobile sends a code to the new mobile, it's not modified until confirmed.
+func (con *Controller) modifyOwnMobile(ctx *gin.Context) {
+	var admin modifyOwnMobileRequest
+
+	err := ctx.ShouldBind(&admin)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	err = con.sendMobileCode(ctx.ClientIP(), id, admin.Mobile)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type confirmOwnMobileRequest struct {
+	Mobile string `json:"mobile"  binding:"required,numeric,len=11"`
+	Code   string `json:"code"    binding:"required,numeric"`
+}
+
+// confirmOwnMobile modifies the mobile by the code sent to it, the other
+// sessions of the admin are revoked.
+func (con *Controller) confirmOwnMobile(ctx *gin.Context) {
+	var admin confirmOwnMobileRequest
+
+	err := ctx.ShouldBind(&admin)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	err = con.changeMobile(id, admin.Mobile, admin.Code, currentSession(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type modifyOwnPasswordRequest struct {
+	Password    string `json:"password"      binding:"printascii,min=6,max=30"`
+	NewPassword string `json:"new_password"  binding:"printascii,min=6,max=30"`
+	Confirm     string `json:"confirm"       binding:"printascii,min=6,max=30"`
+}
+
+// modifyOwnPassword checks the current password, the tokens of the admin
+// including this one are revoked after.
+func (con *Controller) modifyOwnPassword(ctx *gin.Context) {
+	var admin modifyOwnPasswordRequest
+
+	err := ctx.ShouldBind(&admin)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if admin.NewPassword == admin.Password {
+		ctx.Error(errPasswordUnchanged)
+		return
+	}
+
+	if admin.NewPassword != admin.Confirm {
+		ctx.Error(errPasswordNotConfirmed)
+		return
+	}
+
+	if err = con.policy.Check(admin.NewPassword); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	err = con.repo.ModifyPassword(id, &admin.Password, &admin.NewPassword)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
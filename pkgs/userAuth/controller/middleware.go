@@ -1,98 +1,152 @@
 package controller
 
 import (
-	"github.com/abserari/shower/pkgs/userAuth/model/mysql"
-	jwt "github.com/appleboy/gin-jwt/v2"
+	"math"
+	"time"
 
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/token"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
-	"net/http"
-	"time"
 )
 
+// GetID returns the admin ID of the typed claims of the request.
 func (con *Controller) GetID(ctx *gin.Context) (uint32, error) {
-	id, ok := ctx.Get("userID")
+	claims, ok := token.ClaimsOf(ctx)
 	if !ok {
 		return 0, errUserIDNotExists
 	}
 
-	v, ok := id.(float64)
-	if !ok {
-		return 0, errUserIDNotValid(id)
-	}
-
-	return uint32(v), nil
+	return claims.AdminID, nil
 }
 
-//CheckActive middleware that checks the active
+//CheckActive middleware that checks the active, and the admin needn't change the password
 func (con *Controller) CheckActive() func(ctx *gin.Context) {
+	return con.checkActive(false)
+}
+
+// checkActive checks the admin is active, the admin who must change the
+// password passes only if changing.
+func (con *Controller) checkActive(changing bool) func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
-		a, err := con.GetID(ctx)
+		id, err := con.GetID(ctx)
 		if err != nil {
-			_ = ctx.AbortWithError(http.StatusBadRequest, err)
+			ctx.Error(err)
+			ctx.Abort()
 			return
 		}
 
-		active, err := mysql.IsActive(con.db, a)
-		if err != nil {
-			_ = ctx.AbortWithError(http.StatusConflict, err)
-			return
+		a, err := con.repo.AdminByID(id)
+		if err == model.ErrNotFound {
+			err = errActive
 		}
-
-		if !active {
-			_ = ctx.AbortWithError(http.StatusLocked, errActive)
-			ctx.JSON(http.StatusLocked, gin.H{"status": http.StatusLocked})
+		if err == nil && !a.Active {
+			err = errActive
+		}
+		if err == nil && a.MustChangePassword && !changing {
+			err = errMustChangePassword
+		}
+		if err != nil {
+			ctx.Error(err)
+			ctx.Abort()
 			return
 		}
 	}
 }
 
-func (con *Controller) newJWTMiddleware() (*jwt.GinJWTMiddleware, error) {
-	return jwt.New(&jwt.GinJWTMiddleware{
-		Realm:       "test-pet",
-		Key:         []byte("moli-tech-cats-member"),
-		Timeout:     140*time.Hour,
-		MaxRefresh:  140*time.Hour,
+func (con *Controller) newJWTMiddleware() (*token.Middleware, error) {
+	keys, err := token.Load(con.jwtConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token.Middleware{
+		Realm:       con.jwtConf.Realm,
+		Keys:        keys,
+		Timeout:     con.jwtConf.Timeout,
+		MaxRefresh:  con.jwtConf.MaxRefresh,
 		IdentityKey: "userID",
-		// use data as userID here.
-		PayloadFunc: func(data interface{}) jwt.MapClaims {
-			return jwt.MapClaims{
-				"userID": data,
-			}
-		},
-		// just get the ID
-		IdentityHandler: func(c *gin.Context) interface{} {
-			claims := jwt.ExtractClaims(c)
-			return claims["userID"]
-		},
 		Authenticator: func(ctx *gin.Context) (interface{}, error) {
 			return con.Login(ctx)
 		},
-		// no need to check userAuth valid every time.
-		Authorizator: func(data interface{}, c *gin.Context) bool {
-			return true
-		},
-		Unauthorized: func(c *gin.Context, code int, message string) {
-			c.JSON(code, gin.H{
-				"code":    code,
-				"message": message,
-			})
-		},
-		// TokenLookup is a string in the form of "<source>:<name>" that is used
-		// to extract token from the request.
-		// Optional. Default value "header:Authorization".
-		// Possible values:
-		// - "header:<name>"
-		// - "query:<name>"
-		// - "cookie:<name>"
-		// - "param:<name>"
-		TokenLookup: "header: Authorization, query: token, cookie: JWT",
-		// TokenLookup: "query:token",
-		// TokenLookup: "cookie:token",
-
-		// TokenHeadName is a string in the header. Default value is "Bearer"
-		TokenHeadName: "Bearer",
-
-		// TimeFunc provides the current time. You can override it to use another time value. This is useful for testing or if your server uses a different time zone than your tokens.
-		TimeFunc: time.Now,
-	})
+		Validate:  con.validate,
+		Logout:    con.logout,
+		Challenge: con.challenge,
+		Issue:     con.issue,
+		TimeFunc:  time.Now,
+	}, nil
+}
+
+// grant sets the tenant of the admin and the roles granted by permission to
+// claims before signing.
+func (con *Controller) grant(claims jwt.MapClaims) error {
+	id := identityOf(claims)
+
+	a, err := con.repo.AdminByID(id)
+	if err != nil {
+		return err
+	}
+
+	c := token.Claims{AdminID: id, Tenant: a.Tenant}
+	if con.granter != nil {
+		g, err := con.granter.Grants(id)
+		if err != nil {
+			return err
+		}
+		c.Roles, c.PermissionsVersion = g.Roles, g.Version
+	}
+
+	c.Grant(claims)
+	return nil
+}
+
+// identityOf is the admin ID of claims, it's uint32 on login, and float64 once parsed.
+func identityOf(claims jwt.MapClaims) uint32 {
+	if v, ok := claims["userID"].(float64); ok {
+		return uint32(v)
+	}
+	id, _ := claims["userID"].(uint32)
+	return id
+}
+
+// validate rejects the token issued for a purpose, like resetting the password,
+// and the revoked one, or the one of a revoked session.
+func (con *Controller) validate(claims jwt.MapClaims) error {
+	if _, ok := claims[purposeKey]; ok {
+		return errPurpose
+	}
+	if err := con.checkRevoked(claims); err != nil {
+		return err
+	}
+	return con.checkSession(claims)
+}
+
+// checkRevoked rejects the token logged out, or issued before the admin is
+// deactivated or the password is modified.
+func (con *Controller) checkRevoked(claims jwt.MapClaims) error {
+	jti, _ := claims["jti"].(string)
+	id, _ := claims["userID"].(float64)
+
+	revoked, err := con.repo.IsRevoked(jti, uint32(id), issuedAt(claims))
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return errRevoked
+	}
+	return nil
+}
+
+// issuedAt is orig_iat in milliseconds.
+func issuedAt(claims jwt.MapClaims) time.Time {
+	iat, _ := claims["orig_iat"].(float64)
+	return time.Unix(0, int64(math.Round(iat*1000))*int64(time.Millisecond))
+}
+
+// revoke revokes the token until it expires.
+func (con *Controller) revoke(claims jwt.MapClaims) error {
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+
+	return con.repo.RevokeToken(jti, time.Unix(int64(exp), 0))
 }
@@ -0,0 +1,85 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/abserari/shower/pkgs/testkit"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	ownMobilePath        = "/api/v1/userAuth/me/mobile"
+	confirmOwnMobilePath = "/api/v1/userAuth/me/mobile/confirm"
+)
+
+// mobileOf returns the mobile in the profile of s.
+func mobileOf(s *testkit.Session) string {
+	var p struct {
+		Admin struct {
+			Mobile string `json:"mobile"`
+		} `json:"admin"`
+	}
+	s.Get(mePath).OK(&p)
+	return p.Admin.Mobile
+}
+
+func TestModifyOwnMobile(t *testing.T) {
+	kit := testkit.New(t)
+	kit.CreateAdmin("mobilist", "mobilist1")
+	s := kit.Login("mobilist", "mobilist1")
+	other := kit.Login("mobilist", "mobilist1")
+
+	// the mobile isn't modified until the code sent to it is confirmed.
+	s.Post(ownMobilePath, map[string]string{"mobile": victimMobile}).OK(nil)
+	if mobile := mobileOf(s); mobile != "" {
+		t.Fatalf("mobile %s before confirmed", mobile)
+	}
+	code := sentCode(t, kit, victimMobile)
+
+	// a wrong code, or the code for another mobile, sets nothing.
+	s.Post(confirmOwnMobilePath, map[string]string{"mobile": victimMobile, "code": "000000"}).Error(errs.KindUnauthorized)
+	s.Post(confirmOwnMobilePath, map[string]string{"mobile": attackerMobile, "code": code}).Error(errs.KindUnauthorized)
+	s.Post(confirmOwnMobilePath, map[string]string{"mobile": victimMobile, "code": code}).OK(nil)
+	if mobile := mobileOf(s); mobile != victimMobile {
+		t.Fatalf("mobile %q, want %s", mobile, victimMobile)
+	}
+
+	// the other sessions are revoked, the code is used once.
+	other.Get(mePath).Error(errs.KindUnauthorized)
+	s.Post(confirmOwnMobilePath, map[string]string{"mobile": victimMobile, "code": code}).Error(errs.KindUnauthorized)
+}
+
+func TestModifyOwnMobileUsed(t *testing.T) {
+	kit := testkit.New(t)
+	adminOfMobile(t, kit, "owner", victimMobile)
+
+	s := kit.LoginAs("thief")
+	before := sentCode(t, kit, victimMobile)
+	s.Post(ownMobilePath, map[string]string{"mobile": victimMobile}).Error(errs.KindConflict)
+	if code, _ := kit.Code(victimMobile); code != before {
+		t.Fatal("a code is sent to the mobile of another admin")
+	}
+}
+
+func TestModifyMobileByAdmin(t *testing.T) {
+	kit := testkit.New(t)
+	target := kit.LoginAs("target")
+
+	kit.Root().Post(mobilePath, map[string]interface{}{"admin_id": target.AdminID, "mobile": victimMobile}).OK(nil)
+	kit.Root().Post(confirmMobilePath, map[string]interface{}{
+		"admin_id": target.AdminID,
+		"mobile":   victimMobile,
+		"code":     "000000",
+	}).Error(errs.KindUnauthorized)
+	target.Get(mePath).OK(nil)
+
+	kit.Root().Post(confirmMobilePath, map[string]interface{}{
+		"admin_id": target.AdminID,
+		"mobile":   victimMobile,
+		"code":     sentCode(t, kit, victimMobile),
+	}).OK(nil)
+
+	// the sessions of the admin are revoked by the mobile modified.
+	target.Get(mePath).Error(errs.KindUnauthorized)
+	kit.Root().Get(mePath).OK(nil)
+}
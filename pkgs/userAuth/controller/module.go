@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abserari/shower/pkgs/module"
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/pkgs/userAuth/model/memory"
+	"github.com/abserari/shower/pkgs/userAuth/model/mysql"
+	"github.com/abserari/shower/utils/mail"
+	"github.com/abserari/shower/utils/oidc"
+	"github.com/abserari/shower/utils/salt"
+	"github.com/gin-gonic/gin"
+)
+
+// ModuleName is the name of userAuth in config.
+const ModuleName = "userAuth"
+
+// messenger is the module sending the codes by SMS, it's optional.
+const messenger = "smservice"
+
+func init() {
+	module.Register(ModuleName, func(env *module.Env) (module.Module, error) {
+		hasher, err := salt.New(env.Config.Password)
+		if err != nil {
+			return nil, err
+		}
+
+		var repo model.Repository
+		if env.Memory() {
+			repo = memory.NewRepository(hasher)
+		} else {
+			repo = mysql.NewRepository(env.DB, hasher)
+		}
+
+		con := New(repo, env.Config.JWT, env.Config.Login, env.Config.Password)
+
+		mailer, err := mail.New(env.Config.Mail)
+		if err != nil {
+			return nil, err
+		}
+		con.UseMailer(mailer, env.Config.Mail)
+		con.UseAPIKeys(env.Config.APIKey)
+		con.UseBootstrap(env.Config.Bootstrap)
+
+		if env.Config.OIDC.Issuer != "" {
+			con.UseOIDC(oidc.New(env.Config.OIDC), env.Config.OIDC)
+		}
+
+		if _, ok := env.Config.Module(messenger); ok {
+			m, err := env.Dependency(messenger)
+			if err != nil {
+				return nil, err
+			}
+
+			sms, ok := m.(Messenger)
+			if !ok {
+				return nil, fmt.Errorf("userAuth: %s is not a messenger", messenger)
+			}
+			con.sms = sms
+		}
+
+		return con, nil
+	})
+}
+
+// Name returns the name of the module.
+func (con *Controller) Name() string { return ModuleName }
+
+// Dependencies is smservice if it's enabled.
+func (con *Controller) Dependencies() []string {
+	if con.sms == nil {
+		return nil
+	}
+	return []string{messenger}
+}
+
+// Start seeds the bootstrap admin if there's no admin.
+func (con *Controller) Start(ctx context.Context) error { return con.seed() }
+
+// Shutdown has nothing to stop.
+func (con *Controller) Shutdown(ctx context.Context) error { return nil }
+
+// Middlewares provides jwt which checks the token and active which checks the userAuth active.
+func (con *Controller) Middlewares() map[string]gin.HandlerFunc {
+	return map[string]gin.HandlerFunc{
+		"jwt":    con.JWT.MiddlewareFunc(),
+		"active": con.CheckActive(),
+	}
+}
@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/oidc"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcPurpose  = "oidc_login"
+	oidcDuration = 10 * time.Minute
+	// oidcCookie keeps the state token in the browser until the callback.
+	oidcCookie = "oidc_state"
+
+	// the claims of the state token.
+	stateKey    = "state"
+	nonceKey    = "nonce"
+	verifierKey = "verifier"
+
+	// the bounds of the names of the admins created.
+	minNameLength = 5
+	maxNameLength = 30
+)
+
+var (
+	errOIDCState   = errs.Unauthorized("oidc state is invalid or expired")
+	errOIDCDenied  = errs.Unauthorized("oidc login is denied by the provider")
+	errOIDCUnknown = errs.Forbidden("no admin of the identity")
+	errOIDCNoName  = errs.Conflict("no name is available for the identity")
+)
+
+// UseOIDC logins the admins by the provider of conf, the single sign-on is
+// disabled without it.
+func (con *Controller) UseOIDC(p *oidc.Provider, conf config.OIDC) {
+	con.oidc = p
+	con.oidcConf = conf
+}
+
+// oidcLogin redirects to the provider, the state, nonce and code verifier
+// are kept in a cookie by a signed token until the callback.
+func (con *Controller) oidcLogin(ctx *gin.Context) {
+	var values [3]string
+	for i := range values {
+		v, err := oidc.RandomString()
+		if err != nil {
+			ctx.Error(errs.Internal(err))
+			return
+		}
+		values[i] = v
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	authURL, err := con.oidc.AuthCodeURL(ctx.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		ctx.Error(errs.Internal(err))
+		return
+	}
+
+	claims, _, err := con.JWT.NewClaims(uint32(0), oidcDuration)
+	if err != nil {
+		ctx.Error(errs.Internal(err))
+		return
+	}
+	claims[purposeKey] = oidcPurpose
+	claims[stateKey] = state
+	claims[nonceKey] = nonce
+	claims[verifierKey] = verifier
+
+	token, err := con.JWT.Keys.Sign(claims)
+	if err != nil {
+		ctx.Error(errs.Internal(err))
+		return
+	}
+
+	con.setStateCookie(ctx, token, int(oidcDuration/time.Second))
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// setStateCookie sets the state token for the callback only, it's removed if maxAge is negative.
+func (con *Controller) setStateCookie(ctx *gin.Context, value string, maxAge int) {
+	path, secure := "/", false
+	if u, err := url.Parse(con.oidcConf.RedirectURL); err == nil {
+		path, secure = u.Path, u.Scheme == "https"
+	}
+
+	// Lax sends the cookie on the redirect back from the provider.
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     oidcCookie,
+		Value:    url.QueryEscape(value),
+		MaxAge:   maxAge,
+		Path:     path,
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+type oidcCallbackRequest struct {
+	Code  string `form:"code"`
+	State string `form:"state"  binding:"required"`
+	// Error is answered by the provider instead of the code.
+	Error string `form:"error"`
+}
+
+// LoginByOIDC returns the admin ID of the identity redirected back from the
+// provider. The subject is matched by the link to the admin, then by the
+// email verified if MatchEmail, or a new admin is created if CreateAdmins.
+func (con *Controller) LoginByOIDC(ctx *gin.Context) (uint32, error) {
+	var req oidcCallbackRequest
+
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		return 0, errs.Bind(err)
+	}
+
+	raw, err := ctx.Cookie(oidcCookie)
+	if err != nil {
+		return 0, errOIDCState
+	}
+	claims, _, err := con.parsePurposeToken(raw, oidcPurpose, errOIDCState)
+	if err != nil {
+		return 0, err
+	}
+	if state, _ := claims[stateKey].(string); state == "" || state != req.State {
+		return 0, errOIDCState
+	}
+
+	// the state is used once whether the login succeeds or not.
+	con.setStateCookie(ctx, "", -1)
+	if err = con.revoke(claims); err != nil {
+		return 0, err
+	}
+
+	if req.Error != "" || req.Code == "" {
+		return 0, errOIDCDenied
+	}
+
+	nonce, _ := claims[nonceKey].(string)
+	verifier, _ := claims[verifierKey].(string)
+	identity, err := con.oidc.Exchange(ctx.Request.Context(), req.Code, verifier, nonce)
+	if err != nil {
+		return 0, errs.Wrap(errs.KindUnauthorized, errOIDCDenied.Message, err)
+	}
+
+	a, err := con.adminOfIdentity(identity)
+	if err != nil {
+		return 0, err
+	}
+
+	active, err := con.repo.IsActive(a.ID)
+	if err != nil {
+		return 0, err
+	}
+	if !active {
+		return 0, errActive
+	}
+
+	if err = con.recordLogin(a.Name, ctx.ClientIP(), a.ID, true); err != nil {
+		return 0, err
+	}
+
+	return a.ID, nil
+}
+
+// adminOfIdentity returns the admin linked to the identity, the admin
+// matched or created is linked for the next login.
+func (con *Controller) adminOfIdentity(identity *oidc.Claims) (*model.Admin, error) {
+	a, err := con.repo.AdminByIdentity(identity.Issuer, identity.Subject)
+	if err != model.ErrNotFound {
+		return a, err
+	}
+
+	// the email is trusted only if the provider verified it.
+	verified := identity.EmailVerified && identity.Email != ""
+
+	if verified && con.oidcConf.MatchEmail {
+		a, err = con.repo.AdminByEmail(identity.Email)
+		if err != nil && err != model.ErrNotFound {
+			return nil, err
+		}
+	}
+
+	// a service account never logins.
+	if a != nil && a.Service {
+		return nil, errOIDCUnknown
+	}
+	if a == nil {
+		if !con.oidcConf.CreateAdmins {
+			return nil, errOIDCUnknown
+		}
+		if a, err = con.createAdminOf(identity, verified); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = con.repo.LinkIdentity(identity.Issuer, identity.Subject, a.ID); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// createAdminOf creates an admin named after the identity, whose random
+// password is unknown to anyone. The email verified is set if it's not used.
+func (con *Controller) createAdminOf(identity *oidc.Claims, verified bool) (*model.Admin, error) {
+	password, err := oidc.RandomString()
+	if err != nil {
+		return nil, errs.Internal(err)
+	}
+
+	base := nameOf(identity)
+	for i := 1; i < 100; i++ {
+		name := base
+		if i > 1 {
+			suffix := strconv.Itoa(i)
+			name = truncate(base, maxNameLength-len(suffix)) + suffix
+		}
+
+		err = con.repo.CreateAdmin(&name, &password)
+		if errs.Is(err, errs.KindConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		a, err := con.repo.AdminByName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if verified {
+			if _, err = con.repo.AdminByEmail(identity.Email); err == model.ErrNotFound {
+				err = con.repo.ModifyEmail(a.ID, &identity.Email)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	}
+
+	return nil, errOIDCNoName
+}
+
+// nameOf is the alphanumeric name of the identity by the preferred username,
+// the email or the name, it's padded to the minimum length.
+func nameOf(identity *oidc.Claims) string {
+	candidates := []string{
+		identity.PreferredUsername,
+		strings.SplitN(identity.Email, "@", 2)[0],
+		identity.Name,
+	}
+
+	var name string
+	for _, c := range candidates {
+		name = strings.Map(func(r rune) rune {
+			if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return r
+			}
+			return -1
+		}, c)
+		if name != "" {
+			break
+		}
+	}
+
+	if len(name) < minNameLength {
+		name = "oidc" + name
+	}
+	if len(name) < minNameLength {
+		name += strings.Repeat("0", minNameLength-len(name))
+	}
+	return truncate(name, maxNameLength)
+}
@@ -0,0 +1,174 @@
+package controller_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/abserari/shower/pkgs/testkit"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/oidc/oidctest"
+)
+
+const (
+	oidcLoginPath    = "/api/v1/userAuth/oidc/login"
+	oidcCallbackPath = "/api/v1/userAuth/oidc/callback"
+	emailPath        = "/api/v1/userAuth/admin/email"
+	confirmEmailPath = "/api/v1/userAuth/email/confirm"
+	mePath           = "/api/v1/userAuth/me"
+)
+
+// oidcKit is a kit logging in by idp, the admins of the identities unknown are created if create.
+func oidcKit(t *testing.T, user oidctest.User, create bool) (*testkit.Kit, *oidctest.Server) {
+	t.Helper()
+
+	idp, err := oidctest.NewServer(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(idp.Close)
+
+	kit := testkit.New(t, func(c *config.Config) {
+		c.OIDC = idp.Config("http://shower.test" + oidcCallbackPath)
+		c.OIDC.CreateAdmins = create
+	})
+	return kit, idp
+}
+
+// oidcAuthorize starts the login and returns the callback redirected back
+// by idp, with the session carrying the state cookie.
+func oidcAuthorize(t *testing.T, kit *testkit.Kit, idp *oidctest.Server) (string, *testkit.Session) {
+	t.Helper()
+
+	resp := kit.Anonymous().Get(oidcLoginPath)
+	if resp.Status != http.StatusFound {
+		t.Fatalf("status %d, want %d: %s", resp.Status, http.StatusFound, resp.Body)
+	}
+
+	back, err := idp.Authorize(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(back)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	browser := kit.Anonymous()
+	browser.Cookies = (&http.Response{Header: resp.Header}).Cookies()
+	return u.RequestURI(), browser
+}
+
+// oidcLogin logs in by idp, it fails the test if the login fails.
+func oidcLogin(t *testing.T, kit *testkit.Kit, idp *oidctest.Server) *testkit.Session {
+	t.Helper()
+
+	callback, browser := oidcAuthorize(t, kit, idp)
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	browser.Get(callback).OK(&login)
+	return kit.Session(login.Token)
+}
+
+type profile struct {
+	Admin struct {
+		ID    uint32 `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"admin"`
+}
+
+// confirmEmail sets the email of the admin by the link mailed.
+func confirmEmail(t *testing.T, kit *testkit.Kit, id uint32, email string) {
+	t.Helper()
+
+	kit.Root().Post(emailPath, map[string]interface{}{"admin_id": id, "email": email}).OK(nil)
+
+	mails := kit.Mails()
+	if len(mails) == 0 {
+		t.Fatal("no mail is sent")
+	}
+	for _, line := range strings.Split(mails[len(mails)-1].Body, "\n") {
+		if link, err := url.Parse(line); err == nil && link.Query().Get("token") != "" {
+			kit.Anonymous().Post(confirmEmailPath, map[string]string{"token": link.Query().Get("token")}).OK(nil)
+			return
+		}
+	}
+	t.Fatal("no link in the mail")
+}
+
+func TestOIDCCreateAdmin(t *testing.T) {
+	kit, idp := oidcKit(t, oidctest.User{
+		Subject:           "new-subject",
+		Email:             "newcomer@example.com",
+		EmailVerified:     true,
+		PreferredUsername: "newcomer",
+	}, true)
+
+	s := oidcLogin(t, kit, idp)
+
+	var p profile
+	s.Get(mePath).OK(&p)
+	if p.Admin.ID != s.AdminID || p.Admin.Name != "newcomer" || p.Admin.Email != "newcomer@example.com" {
+		t.Fatalf("admin created %+v", p.Admin)
+	}
+
+	// the identity is linked, whatever the email is then.
+	idp.SetUser(oidctest.User{Subject: "new-subject", Email: "changed@example.com", EmailVerified: true})
+	if again := oidcLogin(t, kit, idp); again.AdminID != s.AdminID {
+		t.Fatalf("login as %d, want %d", again.AdminID, s.AdminID)
+	}
+}
+
+func TestOIDCLinkAdmin(t *testing.T) {
+	kit, idp := oidcKit(t, oidctest.User{
+		Subject:       "linked-subject",
+		Email:         "linked@example.com",
+		EmailVerified: false,
+	}, false)
+
+	existing := kit.LoginAs("linked")
+	confirmEmail(t, kit, existing.AdminID, "linked@example.com")
+
+	// the email isn't trusted unless the provider verified it.
+	callback, browser := oidcAuthorize(t, kit, idp)
+	browser.Get(callback).Error(errs.KindForbidden)
+
+	idp.SetUser(oidctest.User{Subject: "linked-subject", Email: "linked@example.com", EmailVerified: true})
+	if s := oidcLogin(t, kit, idp); s.AdminID != existing.AdminID {
+		t.Fatalf("login as %d, want %d", s.AdminID, existing.AdminID)
+	}
+
+	// the subject logs in as the admin linked after the email is gone.
+	idp.SetUser(oidctest.User{Subject: "linked-subject"})
+	if s := oidcLogin(t, kit, idp); s.AdminID != existing.AdminID {
+		t.Fatalf("login as %d, want %d", s.AdminID, existing.AdminID)
+	}
+
+	// another subject of no email is unknown, and no admin is created.
+	idp.SetUser(oidctest.User{Subject: "unknown-subject"})
+	callback, browser = oidcAuthorize(t, kit, idp)
+	browser.Get(callback).Error(errs.KindForbidden)
+}
+
+func TestOIDCState(t *testing.T) {
+	kit, idp := oidcKit(t, oidctest.User{Subject: "state-subject", PreferredUsername: "stateful"}, true)
+
+	// the callback of a login in the browser of another.
+	callback, _ := oidcAuthorize(t, kit, idp)
+	_, browser := oidcAuthorize(t, kit, idp)
+	browser.Get(callback).Error(errs.KindUnauthorized)
+
+	// no state cookie.
+	callback, _ = oidcAuthorize(t, kit, idp)
+	kit.Anonymous().Get(callback).Error(errs.KindUnauthorized)
+
+	// the state is used once.
+	callback, browser = oidcAuthorize(t, kit, idp)
+	browser.Get(callback).OK(nil)
+	browser.Get(callback).Error(errs.KindUnauthorized)
+}
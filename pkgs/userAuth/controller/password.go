@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/abserari/shower/utils/errs"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// purposeKey is the claim of a token not for access.
+	purposeKey    = "purpose"
+	resetPurpose  = "password_reset"
+	resetDuration = 10 * time.Minute
+)
+
+var (
+	errPurpose    = errs.Unauthorized("token is not for access")
+	errResetToken = errs.Unauthorized("reset token is invalid or expired")
+)
+
+type forgotPasswordRequest struct {
+	Mobile string `json:"mobile"  binding:"required,numeric,len=11"`
+}
+
+// forgotPassword sends a code to the mobile of the admin, it answers the same
+// whether the admin exists or not.
+func (con *Controller) forgotPassword(ctx *gin.Context) {
+	var req forgotPasswordRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if err = con.sendCode(ctx.ClientIP(), req.Mobile, resetSign(req.Mobile)); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type verifyPasswordCodeRequest struct {
+	Mobile string `json:"mobile"  binding:"required,numeric,len=11"`
+	Code   string `json:"code"    binding:"required,numeric"`
+}
+
+type resetTokenResponse struct {
+	Status int    `json:"status"`
+	Token  string `json:"token"`
+	Expire string `json:"expire"`
+}
+
+// verifyPasswordCode answers a reset token if the code is right.
+func (con *Controller) verifyPasswordCode(ctx *gin.Context) {
+	var req verifyPasswordCodeRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	a, err := con.checkCode(req.Mobile, req.Code, resetSign(req.Mobile))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	token, expire, err := con.resetToken(a.ID)
+	if err != nil {
+		ctx.Error(errs.Internal(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resetTokenResponse{
+		Status: http.StatusOK,
+		Token:  token,
+		Expire: expire.Format(time.RFC3339),
+	})
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"     binding:"required"`
+	Password string `json:"password"  binding:"printascii,min=6,max=30"`
+	Confirm  string `json:"confirm"   binding:"printascii,min=6,max=30"`
+}
+
+// resetPassword sets the password by the reset token, which is used once.
+// The other tokens of the admin are revoked too.
+func (con *Controller) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if req.Password != req.Confirm {
+		ctx.Error(errPasswordNotConfirmed)
+		return
+	}
+
+	if err = con.policy.Check(req.Password); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	claims, id, err := con.parsePurposeToken(req.Token, resetPurpose, errResetToken)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err = con.repo.ResetPassword(id, &req.Password); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err = con.revoke(claims); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+// resetToken signs a token resetting the password of the admin, it's
+// rejected by the middleware.
+func (con *Controller) resetToken(id uint32) (string, time.Time, error) {
+	claims, expire, err := con.JWT.NewClaims(id, resetDuration)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	claims[purposeKey] = resetPurpose
+
+	token, err := con.JWT.Keys.Sign(claims)
+	return token, expire, err
+}
+
+// parsePurposeToken returns the claims and the admin ID of a token of the
+// purpose not revoked, invalid is returned if it's not.
+func (con *Controller) parsePurposeToken(raw, purpose string, invalid error) (jwt.MapClaims, uint32, error) {
+	token, err := con.JWT.Keys.Parse(raw)
+	if err != nil {
+		return nil, 0, invalid
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims[purposeKey] != purpose {
+		return nil, 0, invalid
+	}
+
+	id, ok := claims["userID"].(float64)
+	if !ok {
+		return nil, 0, invalid
+	}
+
+	if err = con.checkRevoked(claims); err != nil {
+		if errs.Is(err, errs.KindUnauthorized) {
+			return nil, 0, invalid
+		}
+		return nil, 0, err
+	}
+
+	return claims, uint32(id), nil
+}
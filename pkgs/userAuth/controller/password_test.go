@@ -0,0 +1,114 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/abserari/shower/pkgs/testkit"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	mobilePath        = "/api/v1/userAuth/admin/mobile"
+	confirmMobilePath = "/api/v1/userAuth/admin/mobile/confirm"
+	forgotPath        = "/api/v1/userAuth/password/forgot"
+	verifyPath        = "/api/v1/userAuth/password/verify"
+	resetPath         = "/api/v1/userAuth/password/reset"
+
+	publicSendPath  = "/api/v1/message/send"
+	publicCheckPath = "/api/v1/message/check"
+
+	attackerMobile = "13800000001"
+	victimMobile   = "13800000002"
+)
+
+// adminOfMobile creates an admin whose mobile is mobile, and returns its ID.
+func adminOfMobile(t *testing.T, kit *testkit.Kit, name, mobile string) uint32 {
+	t.Helper()
+
+	id := kit.LoginAs(name).AdminID
+	kit.Root().Post(mobilePath, map[string]interface{}{"admin_id": id, "mobile": mobile}).OK(nil)
+	kit.Root().Post(confirmMobilePath, map[string]interface{}{
+		"admin_id": id,
+		"mobile":   mobile,
+		"code":     sentCode(t, kit, mobile),
+	}).OK(nil)
+	return id
+}
+
+// sentCode returns the code sent to mobile, it fails the test if there's none.
+func sentCode(t *testing.T, kit *testkit.Kit, mobile string) string {
+	t.Helper()
+
+	code, ok := kit.Code(mobile)
+	if !ok {
+		t.Fatalf("no code is sent to %s", mobile)
+	}
+	return code
+}
+
+func TestVerifyPasswordCodeOfAnotherMobile(t *testing.T) {
+	kit := testkit.New(t)
+	adminOfMobile(t, kit, "attacker", attackerMobile)
+	adminOfMobile(t, kit, "victim", victimMobile)
+
+	// the sign of the victim couldn't be sent to the attacker by the public API.
+	confirmed := sentCode(t, kit, attackerMobile)
+	kit.Anonymous().Post(publicSendPath, map[string]string{
+		"mobile": attackerMobile,
+		"sign":   "userAuth:reset:" + victimMobile,
+	}).Error(errs.KindForbidden)
+	if code, _ := kit.Code(attackerMobile); code != confirmed {
+		t.Fatal("the code of the reserved sign is sent")
+	}
+
+	// a code sent to the attacker doesn't verify the victim.
+	kit.Anonymous().Post(forgotPath, map[string]string{"mobile": attackerMobile}).OK(nil)
+	code := sentCode(t, kit, attackerMobile)
+	kit.Anonymous().Post(verifyPath, map[string]string{"mobile": victimMobile, "code": code}).Error(errs.KindUnauthorized)
+
+	// the public API couldn't check the code of the reserved sign.
+	kit.Anonymous().Post(forgotPath, map[string]string{"mobile": victimMobile}).OK(nil)
+	code = sentCode(t, kit, victimMobile)
+	kit.Anonymous().Post(publicCheckPath, map[string]string{
+		"mobile": victimMobile,
+		"code":   code,
+		"sign":   "userAuth:reset:" + victimMobile,
+	}).Error(errs.KindForbidden)
+
+	var reset struct {
+		Token string `json:"token"`
+	}
+	kit.Anonymous().Post(verifyPath, map[string]string{"mobile": victimMobile, "code": code}).OK(&reset)
+	if reset.Token == "" {
+		t.Fatal("no reset token")
+	}
+}
+
+func TestResetPasswordOnce(t *testing.T) {
+	kit := testkit.New(t)
+	adminOfMobile(t, kit, "forgetful", victimMobile)
+
+	kit.Anonymous().Post(forgotPath, map[string]string{"mobile": victimMobile}).OK(nil)
+
+	var reset struct {
+		Token string `json:"token"`
+	}
+	kit.Anonymous().Post(verifyPath, map[string]string{
+		"mobile": victimMobile,
+		"code":   sentCode(t, kit, victimMobile),
+	}).OK(&reset)
+
+	kit.Anonymous().Post(resetPath, map[string]string{
+		"token":    reset.Token,
+		"password": "reset-once1",
+		"confirm":  "reset-once1",
+	}).OK(nil)
+	kit.Login("forgetful", "reset-once1")
+
+	kit.Anonymous().Post(resetPath, map[string]string{
+		"token":    reset.Token,
+		"password": "reset-twice2",
+		"confirm":  "reset-twice2",
+	}).Error(errs.KindUnauthorized)
+	kit.Login("forgetful", "reset-once1")
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/abserari/shower/pkgs/module"
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultPageSize = 20
+
+// UseRoles shows the roles in the detail of the admin, and grants them in
+// the token if r is a module.Granter.
+func (con *Controller) UseRoles(r module.Roles) {
+	con.roles = r
+	con.granter, _ = r.(module.Granter)
+}
+
+type listAdminsRequest struct {
+	Active *bool  `form:"active"`
+	Sort   string `form:"sort"  binding:"omitempty,oneof=id name created_at active"`
+	Desc   bool   `form:"desc"`
+	Page   int    `form:"page"  binding:"omitempty,min=1"`
+	Size   int    `form:"size"  binding:"omitempty,min=1,max=100"`
+}
+
+type searchAdminsRequest struct {
+	listAdminsRequest
+	// Keyword is a part of the name, mobile or email.
+	Keyword string `form:"keyword"  binding:"required,max=128"`
+}
+
+type adminResponse struct {
+	ID     uint32 `json:"id"`
+	Name   string `json:"name"`
+	Mobile string `json:"mobile"`
+	Email  string `json:"email"`
+	// PendingEmail is the new email not yet confirmed.
+	PendingEmail string `json:"pending_email,omitempty"`
+	Active       bool   `json:"active"`
+	CreatedAt    string `json:"created_at"`
+	// Service accounts call the APIs by the API keys.
+	Service bool `json:"service"`
+	// MustChangePassword is true until the admin changes its own password.
+	MustChangePassword bool `json:"must_change_password"`
+	// Tenant is the organization of the admin.
+	Tenant string `json:"tenant"`
+}
+
+type listAdminsResponse struct {
+	Status int             `json:"status"`
+	Admins []adminResponse `json:"admins"`
+	Total  int             `json:"total"`
+	Page   int             `json:"page"`
+	Size   int             `json:"size"`
+}
+
+// listAdmins answers a page of the admins.
+func (con *Controller) listAdmins(ctx *gin.Context) {
+	var req listAdminsRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	con.searchAdmins(ctx, &req, "")
+}
+
+// searchAdmin answers a page of the admins whose name, mobile or email contains the keyword.
+func (con *Controller) searchAdmin(ctx *gin.Context) {
+	var req searchAdminsRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	con.searchAdmins(ctx, &req.listAdminsRequest, req.Keyword)
+}
+
+func (con *Controller) searchAdmins(ctx *gin.Context, req *listAdminsRequest, keyword string) {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Size == 0 {
+		req.Size = defaultPageSize
+	}
+
+	admins, total, err := con.repo.SearchAdmins(&model.AdminQuery{
+		Keyword: keyword,
+		Active:  req.Active,
+		Sort:    req.Sort,
+		Desc:    req.Desc,
+		Offset:  (req.Page - 1) * req.Size,
+		Limit:   req.Size,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	resp := listAdminsResponse{
+		Status: http.StatusOK,
+		Admins: make([]adminResponse, 0, len(admins)),
+		Total:  total,
+		Page:   req.Page,
+		Size:   req.Size,
+	}
+	for _, a := range admins {
+		resp.Admins = append(resp.Admins, toAdminResponse(a))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type adminDetailRequest struct {
+	ID uint32 `form:"id"  binding:"required"`
+}
+
+type adminDetailResponse struct {
+	Status int           `json:"status"`
+	Admin  adminResponse `json:"admin"`
+	// Roles are empty if permission is not enabled.
+	Roles     []module.Role `json:"roles"`
+	TwoFactor bool          `json:"two_factor"`
+}
+
+// adminDetail answers the admin with the roles.
+func (con *Controller) adminDetail(ctx *gin.Context) {
+	var req adminDetailRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	con.detail(ctx, req.ID)
+}
+
+// detail answers the admin by ID with the roles.
+func (con *Controller) detail(ctx *gin.Context, id uint32) {
+	a, err := con.repo.AdminByID(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	roles := []module.Role{}
+	if con.roles != nil {
+		if roles, err = con.roles.AdminRoles(a.ID); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+
+	t, err := con.repo.TOTP(a.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, adminDetailResponse{
+		Status:    http.StatusOK,
+		Admin:     toAdminResponse(a),
+		Roles:     roles,
+		TwoFactor: t != nil && t.Confirmed,
+	})
+}
+
+func toAdminResponse(a *model.Admin) adminResponse {
+	return adminResponse{
+		ID:                 a.ID,
+		Name:               a.Name,
+		Mobile:             a.Mobile,
+		Email:              a.Email,
+		PendingEmail:       a.PendingEmail,
+		Active:             a.Active,
+		CreatedAt:          a.CreatedAt,
+		Service:            a.Service,
+		MustChangePassword: a.MustChangePassword,
+		Tenant:             a.Tenant,
+	}
+}
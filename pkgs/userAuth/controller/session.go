@@ -0,0 +1,343 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/token"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// sessionKey is the claim of the session ID, it's kept by refresh.
+	sessionKey = "sid"
+
+	// the sizes of the columns.
+	maxDeviceLength    = 128
+	maxUserAgentLength = 512
+)
+
+var errSessionRevoked = errs.Unauthorized("session is revoked")
+
+// browsers and systems are matched in order against the user agent to tell the device.
+var (
+	browsers = []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	}
+	systems = []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// issue grants the claims of a token answered by the login or refresh, then
+// records the session of it, or updates the session of a refreshed token.
+func (con *Controller) issue(ctx *gin.Context, claims jwt.MapClaims) error {
+	if err := con.grant(claims); err != nil {
+		return err
+	}
+
+	ip := ctx.ClientIP()
+	userAgent := truncate(ctx.Request.UserAgent(), maxUserAgentLength)
+	now := time.Now()
+
+	if sid, ok := claims[sessionKey].(string); ok {
+		return con.repo.TouchSession(sid, ip, userAgent, now)
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return errs.Internal(err)
+	}
+	sid := hex.EncodeToString(b)
+
+	id := identityOf(claims)
+	createdAt := issuedAt(claims)
+	err := con.repo.CreateSession(&model.Session{
+		ID:        sid,
+		AdminID:   id,
+		Device:    device(userAgent),
+		IP:        ip,
+		UserAgent: userAgent,
+		CreatedAt: createdAt,
+		LastSeen:  now,
+		// the last token refreshed is valid until then.
+		ExpiresAt: createdAt.Add(con.jwtConf.MaxRefresh + con.jwtConf.Timeout),
+	})
+	if err != nil {
+		return err
+	}
+
+	claims[sessionKey] = sid
+	return nil
+}
+
+// checkSession rejects the token of a revoked session, a token issued
+// without session is accepted.
+func (con *Controller) checkSession(claims jwt.MapClaims) error {
+	sid, ok := claims[sessionKey].(string)
+	if !ok {
+		return nil
+	}
+	id, _ := claims["userID"].(float64)
+
+	s, err := con.repo.Session(sid)
+	if err == model.ErrSessionNotFound {
+		return errSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if s.Revoked || s.AdminID != uint32(id) {
+		return errSessionRevoked
+	}
+	return nil
+}
+
+// logout revokes the token and the session of it.
+func (con *Controller) logout(claims jwt.MapClaims) error {
+	if err := con.revoke(claims); err != nil {
+		return err
+	}
+
+	sid, ok := claims[sessionKey].(string)
+	if !ok {
+		return nil
+	}
+	id, _ := claims["userID"].(float64)
+
+	err := con.repo.RevokeSession(uint32(id), sid)
+	if err == model.ErrSessionNotFound {
+		return nil
+	}
+	return err
+}
+
+// device describes the browser and system of the user agent, like Chrome on Windows.
+func device(userAgent string) string {
+	var browser, system string
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range systems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	case userAgent != "":
+		return truncate(userAgent, maxDeviceLength)
+	}
+	return "unknown"
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// not to cut a rune of UTF-8.
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
+
+type sessionResponse struct {
+	ID        string `json:"id"`
+	Device    string `json:"device"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	CreatedAt string `json:"created_at"`
+	LastSeen  string `json:"last_seen"`
+	ExpiresAt string `json:"expires_at"`
+	// Current is the session of the request.
+	Current bool `json:"current"`
+}
+
+type sessionsResponse struct {
+	Status   int               `json:"status"`
+	Sessions []sessionResponse `json:"sessions"`
+}
+
+// sessions answers the sessions of the admin, current is the session of the request.
+func (con *Controller) sessions(ctx *gin.Context, id uint32, current string) {
+	sessions, err := con.repo.Sessions(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	resp := sessionsResponse{Status: http.StatusOK, Sessions: make([]sessionResponse, 0, len(sessions))}
+	for _, s := range sessions {
+		resp.Sessions = append(resp.Sessions, sessionResponse{
+			ID:        s.ID,
+			Device:    s.Device,
+			IP:        s.IP,
+			UserAgent: s.UserAgent,
+			CreatedAt: s.CreatedAt.Format(time.RFC3339),
+			LastSeen:  s.LastSeen.Format(time.RFC3339),
+			ExpiresAt: s.ExpiresAt.Format(time.RFC3339),
+			Current:   current != "" && s.ID == current,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// currentSession is the session ID of the request, it's empty if the token has no session.
+func currentSession(ctx *gin.Context) string {
+	sid, _ := token.ExtractClaims(ctx)[sessionKey].(string)
+	return sid
+}
+
+// ownSessions answers the sessions of the admin itself.
+func (con *Controller) ownSessions(ctx *gin.Context) {
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	con.sessions(ctx, id, currentSession(ctx))
+}
+
+type revokeOwnSessionRequest struct {
+	SessionID string `json:"session_id"  binding:"required"`
+}
+
+// revokeOwnSession revokes a session of the admin itself, the tokens of it are rejected then.
+func (con *Controller) revokeOwnSession(ctx *gin.Context) {
+	var req revokeOwnSessionRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err = con.repo.RevokeSession(id, req.SessionID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type revokeOwnSessionsRequest struct {
+	// Others keeps the current session.
+	Others bool `json:"others"`
+}
+
+// revokeOwnSessions revokes every session of the admin itself.
+func (con *Controller) revokeOwnSessions(ctx *gin.Context) {
+	var req revokeOwnSessionsRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var except string
+	if req.Others {
+		except = currentSession(ctx)
+	}
+
+	if err = con.repo.RevokeSessions(id, except); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type adminSessionsRequest struct {
+	AdminID uint32 `form:"admin_id"  binding:"required"`
+}
+
+// adminSessions answers the sessions of an admin.
+func (con *Controller) adminSessions(ctx *gin.Context) {
+	var req adminSessionsRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if _, err = con.repo.AdminByID(req.AdminID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	con.sessions(ctx, req.AdminID, currentSession(ctx))
+}
+
+type revokeAdminSessionRequest struct {
+	AdminID uint32 `json:"admin_id"  binding:"required"`
+	// SessionID is empty to revoke every session of the admin.
+	SessionID string `json:"session_id"`
+}
+
+// revokeAdminSession revokes a session of an admin, or all of them.
+func (con *Controller) revokeAdminSession(ctx *gin.Context) {
+	var req revokeAdminSessionRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if req.SessionID == "" {
+		if _, err = con.repo.AdminByID(req.AdminID); err == nil {
+			err = con.repo.RevokeSessions(req.AdminID, "")
+		}
+	} else {
+		err = con.repo.RevokeSession(req.AdminID, req.SessionID)
+	}
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
@@ -0,0 +1,97 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/abserari/shower/pkgs/testkit"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	refreshPath            = "/api/v1/userAuth/refresh_token"
+	ownSessionsPath        = "/api/v1/userAuth/me/sessions"
+	revokeOwnSessionPath   = "/api/v1/userAuth/me/sessions/revoke"
+	revokeOwnSessionsPath  = "/api/v1/userAuth/me/sessions/revoke_all"
+	revokeAdminSessionPath = "/api/v1/userAuth/admin/sessions/revoke"
+)
+
+// twoSessions logs in an admin twice, and returns both sessions.
+func twoSessions(kit *testkit.Kit) (*testkit.Session, *testkit.Session) {
+	const name, password = "traveler", "traveler-1"
+
+	kit.CreateAdmin(name, password)
+	return kit.Login(name, password), kit.Login(name, password)
+}
+
+// sessionOf returns the ID of the session of s, listed by itself.
+func sessionOf(t *testing.T, s *testkit.Session) string {
+	t.Helper()
+
+	var resp struct {
+		Sessions []struct {
+			ID      string `json:"id"`
+			Current bool   `json:"current"`
+		} `json:"sessions"`
+	}
+	s.Get(ownSessionsPath).OK(&resp)
+	for _, session := range resp.Sessions {
+		if session.Current {
+			return session.ID
+		}
+	}
+	t.Fatal("no current session is listed")
+	return ""
+}
+
+// rejected checks the token of s is rejected by the middleware and the refresh.
+func rejected(s *testkit.Session) {
+	s.Get(mePath).Error(errs.KindUnauthorized)
+	s.Get(refreshPath).Error(errs.KindUnauthorized)
+}
+
+// accepted checks the token of s passes the middleware and the refresh.
+func accepted(s *testkit.Session) {
+	s.Get(mePath).OK(nil)
+	s.Get(refreshPath).OK(nil)
+}
+
+func TestRevokeOwnSession(t *testing.T) {
+	kit := testkit.New(t)
+	laptop, phone := twoSessions(kit)
+
+	laptop.Post(revokeOwnSessionPath, map[string]string{"session_id": sessionOf(t, phone)}).OK(nil)
+
+	rejected(phone)
+	accepted(laptop)
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	kit := testkit.New(t)
+	laptop, phone := twoSessions(kit)
+
+	laptop.Post(revokeOwnSessionsPath, map[string]bool{"others": true}).OK(nil)
+
+	rejected(phone)
+	accepted(laptop)
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	kit := testkit.New(t)
+	laptop, phone := twoSessions(kit)
+
+	laptop.Post(revokeOwnSessionsPath, map[string]bool{"others": false}).OK(nil)
+
+	rejected(phone)
+	rejected(laptop)
+}
+
+func TestRevokeAdminSessions(t *testing.T) {
+	kit := testkit.New(t)
+	laptop, phone := twoSessions(kit)
+
+	kit.Root().Post(revokeAdminSessionPath, map[string]interface{}{"admin_id": laptop.AdminID}).OK(nil)
+
+	rejected(phone)
+	rejected(laptop)
+	kit.Root().Get(mePath).OK(nil)
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errCode        = errs.Unauthorized("invalid mobile or code")
+	errRateLimits  = errs.RateLimited("too many requests, try again later")
+	errNoMessenger = errs.Forbidden("no messenger to confirm the mobile")
+	errMobileUsed  = errs.Conflict("mobile already exists")
+)
+
+// Messenger sends a code to the mobile and checks it, it's smservice.
+type Messenger interface {
+	SendCode(mobile, sign string) error
+	// CheckCode fails if the code of sign is sent to another mobile.
+	CheckCode(mobile, code, sign string) error
+}
+
+// loginSign and resetSign are the signs of the codes sent to mobile to login
+// and to reset the password, a code of one purpose doesn't pass the other.
+// They are reserved for the module so the public API of smservice couldn't
+// send or check them.
+func loginSign(mobile string) string {
+	return ModuleName + ":login:" + mobile
+}
+
+func resetSign(mobile string) string {
+	return ModuleName + ":reset:" + mobile
+}
+
+// mobileSign is the sign of the code confirming the new mobile of the admin by ID.
+func mobileSign(id uint32) string {
+	return ModuleName + ":mobile:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// sendCode sends a code of sign if mobile is of an active admin. The limits
+// apply to every mobile and nothing tells whether the admin exists.
+func (con *Controller) sendCode(ip, mobile, sign string) error {
+	if !con.sendByIP.Allow(ip) || !con.sendByMobile.Allow(sign) {
+		return errRateLimits
+	}
+
+	a, err := con.repo.AdminByMobile(mobile)
+	if errs.Is(err, errs.KindNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !a.Active || a.Service {
+		return nil
+	}
+
+	// like the resend interval, failing to send doesn't answer differently.
+	if err = con.sms.SendCode(mobile, sign); err != nil {
+		log.Printf("[userAuth]: send code to admin %d: %v", a.ID, err)
+	}
+	return nil
+}
+
+// checkCode returns the active admin of mobile if code of sign is right.
+func (con *Controller) checkCode(mobile, code, sign string) (*model.Admin, error) {
+	if !con.checkByMobile.Allow(sign) {
+		return nil, errRateLimits
+	}
+
+	if err := con.sms.CheckCode(mobile, code, sign); err != nil {
+		return nil, errCode
+	}
+	con.checkByMobile.Reset(sign)
+
+	a, err := con.repo.AdminByMobile(mobile)
+	if errs.Is(err, errs.KindNotFound) {
+		return nil, errCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if a.Service {
+		return nil, errCode
+	}
+	if !a.Active {
+		return nil, errActive
+	}
+
+	return a, nil
+}
+
+// sendMobileCode sends a code to the new mobile of the admin, the mobile is
+// not modified until the code is confirmed by changeMobile.
+func (con *Controller) sendMobileCode(ip string, id uint32, mobile string) error {
+	if con.sms == nil {
+		return errNoMessenger
+	}
+
+	a, err := con.repo.AdminByMobile(mobile)
+	if err == nil && a.ID != id {
+		return errMobileUsed
+	}
+	if err != nil && !errs.Is(err, errs.KindNotFound) {
+		return err
+	}
+
+	sign := mobileSign(id)
+	if !con.sendByIP.Allow(ip) || !con.sendByMobile.Allow(sign) {
+		return errRateLimits
+	}
+
+	return con.sms.SendCode(mobile, sign)
+}
+
+// changeMobile modifies the mobile of the admin if code is the one sent to
+// it, the sessions of the admin except the session except are revoked,
+// since the mobile logins and resets the password.
+func (con *Controller) changeMobile(id uint32, mobile, code, except string) error {
+	if con.sms == nil {
+		return errNoMessenger
+	}
+
+	sign := mobileSign(id)
+	if !con.checkByMobile.Allow(sign) {
+		return errRateLimits
+	}
+	if err := con.sms.CheckCode(mobile, code, sign); err != nil {
+		return errCode
+	}
+	con.checkByMobile.Reset(sign)
+
+	if err := con.repo.ModifyMobile(id, &mobile); err != nil {
+		return err
+	}
+	return con.repo.RevokeSessions(id, except)
+}
+
+type sendLoginCodeRequest struct {
+	Mobile string `json:"mobile"  binding:"required,numeric,len=11"`
+}
+
+// sendLoginCode sends a code to login, it answers the same whether the admin exists or not.
+func (con *Controller) sendLoginCode(ctx *gin.Context) {
+	var req sendLoginCodeRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if err = con.sendCode(ctx.ClientIP(), req.Mobile, loginSign(req.Mobile)); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type smsLoginRequest struct {
+	Mobile string `json:"mobile"  binding:"required,numeric,len=11"`
+	Code   string `json:"code"    binding:"required,numeric"`
+}
+
+// LoginBySMS returns the ID of the active admin of the mobile if the code is right.
+func (con *Controller) LoginBySMS(ctx *gin.Context) (uint32, error) {
+	var req smsLoginRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		return 0, errs.Bind(err)
+	}
+
+	a, err := con.checkCode(req.Mobile, req.Code, loginSign(req.Mobile))
+	if err != nil {
+		return 0, err
+	}
+
+	return a.ID, nil
+}
@@ -0,0 +1,70 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/abserari/shower/pkgs/testkit"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	sendLoginCodePath = "/api/v1/userAuth/login/sms/code"
+	smsLoginPath      = "/api/v1/userAuth/login/sms"
+)
+
+func TestSMSLoginOfAnotherMobile(t *testing.T) {
+	kit := testkit.New(t)
+	adminOfMobile(t, kit, "attacker", attackerMobile)
+	victim := adminOfMobile(t, kit, "victim", victimMobile)
+
+	confirmed := sentCode(t, kit, attackerMobile)
+	kit.Anonymous().Post(publicSendPath, map[string]string{
+		"mobile": attackerMobile,
+		"sign":   "userAuth:login:" + victimMobile,
+	}).Error(errs.KindForbidden)
+	if code, _ := kit.Code(attackerMobile); code != confirmed {
+		t.Fatal("the code of the reserved sign is sent")
+	}
+
+	kit.Anonymous().Post(sendLoginCodePath, map[string]string{"mobile": attackerMobile}).OK(nil)
+	code := sentCode(t, kit, attackerMobile)
+	kit.Anonymous().Post(smsLoginPath, map[string]string{"mobile": victimMobile, "code": code}).Error(errs.KindUnauthorized)
+
+	kit.Anonymous().Post(sendLoginCodePath, map[string]string{"mobile": victimMobile}).OK(nil)
+	code = sentCode(t, kit, victimMobile)
+	kit.Anonymous().Post(publicCheckPath, map[string]string{
+		"mobile": victimMobile,
+		"code":   code,
+		"sign":   "userAuth:login:" + victimMobile,
+	}).Error(errs.KindForbidden)
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	kit.Anonymous().Post(smsLoginPath, map[string]string{"mobile": victimMobile, "code": code}).OK(&login)
+
+	s := kit.Session(login.Token)
+	if s.AdminID != victim {
+		t.Fatalf("login as %d, want %d", s.AdminID, victim)
+	}
+	s.Get("/api/v1/userAuth/me").OK(nil)
+}
+
+func TestSMSCodeOfAnotherPurpose(t *testing.T) {
+	kit := testkit.New(t)
+	adminOfMobile(t, kit, "purposeful", victimMobile)
+
+	// a login code doesn't reset the password.
+	kit.Anonymous().Post(sendLoginCodePath, map[string]string{"mobile": victimMobile}).OK(nil)
+	code := sentCode(t, kit, victimMobile)
+	kit.Anonymous().Post(verifyPath, map[string]string{"mobile": victimMobile, "code": code}).Error(errs.KindUnauthorized)
+
+	// a reset code doesn't login.
+	kit.Anonymous().Post(forgotPath, map[string]string{"mobile": victimMobile}).OK(nil)
+	reset := sentCode(t, kit, victimMobile)
+	kit.Anonymous().Post(smsLoginPath, map[string]string{"mobile": victimMobile, "code": reset}).Error(errs.KindUnauthorized)
+
+	// each code still passes its own purpose.
+	kit.Anonymous().Post(smsLoginPath, map[string]string{"mobile": victimMobile, "code": code}).OK(nil)
+	kit.Anonymous().Post(verifyPath, map[string]string{"mobile": victimMobile, "code": reset}).OK(nil)
+}
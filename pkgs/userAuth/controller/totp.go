@@ -0,0 +1,394 @@
+package controller
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/token"
+	"github.com/abserari/shower/utils/totp"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	challengePurpose  = "login_challenge"
+	challengeDuration = 5 * time.Minute
+	// secondFactorKey is the claim of a token issued after the second factor.
+	secondFactorKey = "mfa"
+
+	recoveryCodeCount = 10
+)
+
+var (
+	errTOTPEnabled   = errs.Conflict("two-factor authentication is already enabled")
+	errTOTPEnrolling = errs.Validation("enroll the two-factor authentication first")
+	errTOTPCode      = errs.Unauthorized("invalid two-factor code")
+	errChallenge     = errs.Unauthorized("login challenge is invalid or expired")
+
+	recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// SecondFactor reports whether the token of the request is issued after the second factor.
+func (con *Controller) SecondFactor(ctx *gin.Context) bool {
+	passed, _ := token.ExtractClaims(ctx)[secondFactorKey].(bool)
+	return passed
+}
+
+type challengeResponse struct {
+	Code      int    `json:"code"`
+	Challenge string `json:"challenge"`
+	Expire    string `json:"expire"`
+}
+
+// challenge answers a login challenge instead of the token if the admin
+// enabled the two-factor authentication.
+func (con *Controller) challenge(ctx *gin.Context, identity interface{}) (bool, error) {
+	id, _ := identity.(uint32)
+
+	t, err := con.repo.TOTP(id)
+	if err != nil {
+		return false, err
+	}
+	if t == nil || !t.Confirmed {
+		return false, nil
+	}
+
+	claims, expire, err := con.JWT.NewClaims(id, challengeDuration)
+	if err != nil {
+		return false, errs.Internal(err)
+	}
+	claims[purposeKey] = challengePurpose
+
+	challenge, err := con.JWT.Keys.Sign(claims)
+	if err != nil {
+		return false, errs.Internal(err)
+	}
+
+	ctx.JSON(http.StatusOK, challengeResponse{
+		Code:      http.StatusOK,
+		Challenge: challenge,
+		Expire:    expire.Format(time.RFC3339),
+	})
+	return true, nil
+}
+
+type loginByTOTPRequest struct {
+	Challenge string `json:"challenge"  binding:"required"`
+	// Code is the TOTP code or a recovery code.
+	Code string `json:"code"  binding:"required,max=32"`
+}
+
+// loginByTOTP answers the token of the login challenge and the second factor,
+// the challenge is used once.
+func (con *Controller) loginByTOTP(ctx *gin.Context) {
+	var req loginByTOTPRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	challenge, id, err := con.parsePurposeToken(req.Challenge, challengePurpose, errChallenge)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	active, err := con.repo.IsActive(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if !active {
+		ctx.Error(errActive)
+		return
+	}
+
+	if err = con.checkSecondFactor(ctx, id, req.Code); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err = con.revoke(challenge); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	claims, expire, err := con.JWT.NewClaims(id, con.JWT.Timeout)
+	if err != nil {
+		ctx.Error(errs.Internal(err))
+		return
+	}
+	claims[secondFactorKey] = true
+
+	if err = con.issue(ctx, claims); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	signed, err := con.JWT.Keys.Sign(claims)
+	if err != nil {
+		ctx.Error(errs.Internal(err))
+		return
+	}
+
+	token.LoginResponse(ctx, signed, expire)
+}
+
+// checkSecondFactor checks the TOTP code or a recovery code of the admin, both
+// are used once. The failures are limited by the admin and the IP, so the
+// failures of another client, who knows the password, don't lock out the admin
+// soon, and by the admin in the lockouts, so the clients of many IPs can't
+// guess the code. The failures are added to the login history.
+func (con *Controller) checkSecondFactor(ctx *gin.Context, id uint32, code string) error {
+	ip := ctx.ClientIP()
+	key := strconv.FormatUint(uint64(id), 10) + "@" + ip
+	if !con.checkByAdmin.Allow(key) {
+		return errRateLimits
+	}
+
+	t, err := con.repo.TOTP(id)
+	if err != nil {
+		return err
+	}
+	if t == nil || !t.Confirmed {
+		return errTOTPCode
+	}
+
+	lockKey := totpKeyPrefix + strconv.FormatUint(uint64(id), 10)
+	lockAfter := con.loginConf.TOTPLockAfter
+	if err = con.reserve(ctx, lockKey, lockAfter, lockAfter); err != nil {
+		return err
+	}
+
+	var used bool
+	if step, ok := totp.Validate(t.Secret, code, time.Now()); ok {
+		used, err = con.repo.UseTOTPStep(id, step)
+	} else {
+		used, err = con.repo.UseRecoveryCode(id, hashRecoveryCode(code))
+	}
+	if err != nil {
+		if rerr := con.repo.ReleaseLockout(lockKey); rerr != nil {
+			return rerr
+		}
+		return err
+	}
+
+	if !used {
+		a, err := con.repo.AdminByID(id)
+		if err != nil {
+			return err
+		}
+		if err = con.recordLogin(a.Name, ip, 0, false); err != nil {
+			return err
+		}
+		if err = con.repo.LockLockout(lockKey, lockAfter, time.Now().Add(con.loginConf.LockDuration)); err != nil {
+			return err
+		}
+		return errTOTPCode
+	}
+
+	con.checkByAdmin.Reset(key)
+	return con.repo.ClearLockout(lockKey)
+}
+
+type enrollTOTPResponse struct {
+	Status int    `json:"status"`
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// enrollTOTP generates a secret of the admin, it's enabled after confirmed by a code.
+func (con *Controller) enrollTOTP(ctx *gin.Context) {
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	t, err := con.repo.TOTP(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if t != nil && t.Confirmed {
+		ctx.Error(errTOTPEnabled)
+		return
+	}
+
+	a, err := con.repo.AdminByID(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		ctx.Error(errs.Internal(err))
+		return
+	}
+
+	if err = con.repo.SaveTOTP(&model.TOTP{AdminID: id, Secret: secret}); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollTOTPResponse{
+		Status: http.StatusOK,
+		Secret: secret,
+		URI:    totp.URI(con.jwtConf.Realm, a.Name, secret),
+	})
+}
+
+type confirmTOTPRequest struct {
+	Code string `json:"code"  binding:"required,numeric,len=6"`
+}
+
+type confirmTOTPResponse struct {
+	Status        int      `json:"status"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTP enables the two-factor authentication by the first code, and
+// answers the recovery codes, which are shown only once.
+func (con *Controller) confirmTOTP(ctx *gin.Context) {
+	var req confirmTOTPRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	t, err := con.repo.TOTP(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if t == nil || t.Confirmed {
+		ctx.Error(errTOTPEnrolling)
+		return
+	}
+
+	step, ok := totp.Validate(t.Secret, req.Code, time.Now())
+	if !ok {
+		ctx.Error(errTOTPCode)
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		ctx.Error(errs.Internal(err))
+		return
+	}
+
+	t.Confirmed = true
+	t.LastStep = step
+	t.RecoveryCodes = hashes
+	if err = con.repo.SaveTOTP(t); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmTOTPResponse{Status: http.StatusOK, RecoveryCodes: codes})
+}
+
+type disableTOTPRequest struct {
+	Code string `json:"code"  binding:"required,max=32"`
+}
+
+// disableTOTP disables the two-factor authentication of the admin by a code.
+func (con *Controller) disableTOTP(ctx *gin.Context) {
+	var req disableTOTPRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	id, err := con.GetID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err = con.checkSecondFactor(ctx, id, req.Code); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err = con.repo.DeleteTOTP(id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+type removeTOTPRequest struct {
+	AdminID uint32 `json:"admin_id"  binding:"required"`
+}
+
+// removeTOTP disables the two-factor authentication of another admin who lost it.
+func (con *Controller) removeTOTP(ctx *gin.Context) {
+	var req removeTOTPRequest
+
+	err := ctx.ShouldBind(&req)
+	if err != nil {
+		ctx.Error(errs.Bind(err))
+		return
+	}
+
+	if err = con.repo.DeleteTOTP(req.AdminID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": http.StatusOK})
+}
+
+// newRecoveryCodes returns the codes like abcd-efgh and their hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	b := make([]byte, 5*recoveryCodeCount)
+	if _, err := rand.Read(b); err != nil {
+		return nil, nil, err
+	}
+
+	for i := range codes {
+		s := strings.ToLower(recoveryEncoding.EncodeToString(b[i*5 : i*5+5]))
+		codes[i] = s[:4] + "-" + s[4:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores the case, spaces and dashes of the code.
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/pkgs/userAuth/model/memory"
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/salt"
+	"github.com/abserari/shower/utils/totp"
+	"github.com/gin-gonic/gin"
+)
+
+// enrolled returns a controller of an admin whose TOTP of secret is confirmed.
+func enrolled(t *testing.T) (con *Controller, id uint32, secret string) {
+	t.Helper()
+
+	conf := config.Default()
+	conf.JWT.Key = "test"
+
+	repo := memory.NewRepository(salt.Default())
+	con = New(repo, conf.JWT, conf.Login, conf.Password)
+
+	name, password := "enrolled", "enrolled1"
+	if err := repo.CreateAdmin(&name, &password); err != nil {
+		t.Fatal(err)
+	}
+	a, err := repo.AdminByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret, err = totp.NewSecret(); err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.SaveTOTP(&model.TOTP{AdminID: a.ID, Secret: secret, Confirmed: true}); err != nil {
+		t.Fatal(err)
+	}
+	return con, a.ID, secret
+}
+
+// from returns a context of a request from ip.
+func from(ip string) *gin.Context {
+	ctx := testContext()
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	ctx.Request.RemoteAddr = ip + ":1234"
+	return ctx
+}
+
+func TestCheckSecondFactorLimitedByIP(t *testing.T) {
+	con, id, secret := enrolled(t)
+
+	const attacker, admin = "192.0.2.1", "192.0.2.2"
+	for i := 0; i < 5; i++ {
+		if err := con.checkSecondFactor(from(attacker), id, "not a code"); err != errTOTPCode {
+			t.Fatalf("failure %d: error %v, want %v", i, err, errTOTPCode)
+		}
+	}
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = con.checkSecondFactor(from(attacker), id, code); err != errRateLimits {
+		t.Fatalf("error %v, want %v", err, errRateLimits)
+	}
+
+	// the failures of another IP don't lock out the admin.
+	if err = con.checkSecondFactor(from(admin), id, code); err != nil {
+		t.Fatal(err)
+	}
+	if err = con.checkSecondFactor(from(admin), id, code); err != errTOTPCode {
+		t.Fatalf("the code is used twice: %v", err)
+	}
+}
+
+func TestCheckSecondFactorLimitedByAdmin(t *testing.T) {
+	con, id, secret := enrolled(t)
+	con.loginConf.TOTPLockAfter = 3
+
+	for i := 0; i < con.loginConf.TOTPLockAfter; i++ {
+		ip := "192.0.2." + strconv.Itoa(i+1)
+		if err := con.checkSecondFactor(from(ip), id, "not a code"); err != errTOTPCode {
+			t.Fatalf("failure %d: error %v, want %v", i, err, errTOTPCode)
+		}
+	}
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = con.checkSecondFactor(from("198.51.100.1"), id, code); err != errLocked {
+		t.Fatalf("error %v, want %v", err, errLocked)
+	}
+
+	attempts, err := con.repo.LoginAttempts("enrolled", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != con.loginConf.TOTPLockAfter {
+		t.Fatalf("%d attempts in the history, want %d", len(attempts), con.loginConf.TOTPLockAfter)
+	}
+	for _, a := range attempts {
+		if a.Success {
+			t.Fatalf("a failed code is recorded as %+v", a)
+		}
+	}
+
+	// unlocked by the admins, the code passes.
+	if err = con.repo.ClearLockout(totpKeyPrefix + strconv.FormatUint(uint64(id), 10)); err != nil {
+		t.Fatal(err)
+	}
+	if err = con.checkSecondFactor(from("198.51.100.1"), id, code); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,518 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/salt"
+)
+
+var (
+	errDuplicate = func(key string) error {
+		return errs.Conflict(key + " already exists")
+	}
+)
+
+type admin struct {
+	id       uint32
+	name     string
+	password string
+	mobile   string
+	email    string
+	// the email not yet confirmed.
+	pendingEmail string
+	active       bool
+	createdAt    time.Time
+	// service accounts never login.
+	service bool
+	// the admin could only change its own password.
+	mustChangePassword bool
+	tenant             string
+}
+
+// Repository stores the admins in memory, it's used in development and tests.
+type Repository struct {
+	mu     sync.RWMutex
+	hasher salt.Hasher
+	nextID uint32
+	admins map[uint32]*admin
+	// expire time of the revoked tokens by jti.
+	revokedTokens map[string]time.Time
+	// the tokens issued until the unix milliseconds are revoked, by admin ID.
+	revokedBefore map[uint32]int64
+	// the latest login attempts, in the order of ID.
+	attempts []*model.LoginAttempt
+	lockouts map[string]*model.Lockout
+	totps    map[uint32]*model.TOTP
+	sessions map[string]*model.Session
+	// the admins linked by the identities of the providers.
+	identities map[identity]uint32
+	apiKeys    map[string]*model.APIKey
+}
+
+// NewRepository creates a Repository hashing the passwords by h.
+func NewRepository(h salt.Hasher) *Repository {
+	return &Repository{
+		hasher:        h,
+		nextID:        1000,
+		admins:        make(map[uint32]*admin),
+		revokedTokens: make(map[string]time.Time),
+		revokedBefore: make(map[uint32]int64),
+		lockouts:      make(map[string]*model.Lockout),
+		totps:         make(map[uint32]*model.TOTP),
+		sessions:      make(map[string]*model.Session),
+		identities:    make(map[identity]uint32),
+		apiKeys:       make(map[string]*model.APIKey),
+	}
+}
+
+// used reports whether another admin has the same value of key.
+func (r *Repository) used(id uint32, value string, key func(a *admin) string) bool {
+	for _, a := range r.admins {
+		if a.id != id && key(a) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateAdmin create an administrative userAuth
+func (r *Repository) CreateAdmin(name, password *string) error {
+	hash, err := r.hasher.Hash(*password)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.used(0, *name, func(a *admin) string { return a.name }) {
+		return errDuplicate("admin")
+	}
+
+	r.admins[r.nextID] = &admin{
+		id:        r.nextID,
+		name:      *name,
+		password:  hash,
+		active:    true,
+		createdAt: time.Now(),
+	}
+	r.nextID++
+
+	return nil
+}
+
+// CreateServiceAccount creates an admin without password, who couldn't login.
+func (r *Repository) CreateServiceAccount(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.used(0, name, func(a *admin) string { return a.name }) {
+		return errDuplicate("admin")
+	}
+
+	r.admins[r.nextID] = &admin{
+		id:        r.nextID,
+		name:      name,
+		active:    true,
+		createdAt: time.Now(),
+		service:   true,
+	}
+	r.nextID++
+
+	return nil
+}
+
+// Login the administrative userAuth logins, the password is hashed again if
+// the hash is outdated.
+func (r *Repository) Login(name, password *string) (uint32, error) {
+	var (
+		id   uint32
+		hash string
+	)
+
+	r.mu.RLock()
+	for _, a := range r.admins {
+		if a.name == *name && !a.service {
+			id, hash = a.id, a.password
+			break
+		}
+	}
+	r.mu.RUnlock()
+
+	if id == 0 {
+		return 0, model.ErrLoginFailed
+	}
+
+	ok, rehash := r.hasher.Verify(hash, *password)
+	if !ok {
+		return 0, model.ErrLoginFailed
+	}
+
+	if rehash {
+		newHash, err := r.hasher.Hash(*password)
+		if err != nil {
+			return 0, errs.Internal(err)
+		}
+
+		r.mu.Lock()
+		// unless it's modified since read.
+		if a, ok := r.admins[id]; ok && a.password == hash {
+			a.password = newHash
+		}
+		r.mu.Unlock()
+	}
+
+	return id, nil
+}
+
+// ModifyEmail the administrative userAuth updates email
+func (r *Repository) ModifyEmail(id uint32, email *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.admins[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	if r.used(id, *email, func(a *admin) string { return a.email }) {
+		return errDuplicate("email")
+	}
+
+	a.email = *email
+	return nil
+}
+
+// SetPendingEmail the administrative userAuth sets the email to confirm
+func (r *Repository) SetPendingEmail(id uint32, email *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.admins[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	a.pendingEmail = *email
+	return nil
+}
+
+// ConfirmEmail the administrative userAuth replaces email by the pending one
+func (r *Repository) ConfirmEmail(id uint32, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.admins[id]
+	if !ok || a.pendingEmail == "" || a.pendingEmail != email {
+		return model.ErrNotPending
+	}
+
+	if r.used(id, email, func(a *admin) string { return a.email }) {
+		return errDuplicate("email")
+	}
+
+	a.email, a.pendingEmail = email, ""
+	return nil
+}
+
+// ModifyMobile the administrative userAuth updates mobile
+func (r *Repository) ModifyMobile(id uint32, mobile *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.admins[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	if r.used(id, *mobile, func(a *admin) string { return a.mobile }) {
+		return errDuplicate("mobile")
+	}
+
+	a.mobile = *mobile
+	return nil
+}
+
+// ModifyPassword the administrative userAuth updates password
+func (r *Repository) ModifyPassword(id uint32, password, newPassword *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.admins[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	if ok, _ := r.hasher.Verify(a.password, *password); !ok {
+		return model.ErrWrongPassword
+	}
+
+	hash, err := r.hasher.Hash(*newPassword)
+	if err != nil {
+		return err
+	}
+
+	a.password, a.mustChangePassword = hash, false
+	r.revokeBefore(id, time.Now())
+	return nil
+}
+
+// ResetPassword the administrative userAuth sets password without the current one
+func (r *Repository) ResetPassword(id uint32, password *string) error {
+	hash, err := r.hasher.Hash(*password)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.admins[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	a.password, a.mustChangePassword = hash, false
+	r.revokeBefore(id, time.Now())
+	return nil
+}
+
+// SetMustChangePassword sets whether the admin must change the password first
+func (r *Repository) SetMustChangePassword(id uint32, must bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.admins[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	a.mustChangePassword = must
+	return nil
+}
+
+// ModifyTenant the administrative userAuth updates tenant
+func (r *Repository) ModifyTenant(id uint32, tenant string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.admins[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	a.tenant = tenant
+	r.revokeBefore(id, time.Now())
+	return nil
+}
+
+// ModifyAdminActive the administrative userAuth updates active
+func (r *Repository) ModifyAdminActive(id uint32, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.admins[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	a.active = active
+	if !active {
+		r.revokeBefore(id, time.Now())
+	}
+	return nil
+}
+
+// IsActive return userAuth.Active, it's false if the admin doesn't exist.
+func (r *Repository) IsActive(id uint32) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.admins[id]
+	return ok && a.active, nil
+}
+
+// AdminByName return the admin of name.
+func (r *Repository) AdminByName(name string) (*model.Admin, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, a := range r.admins {
+		if a.name == name {
+			return a.model(), nil
+		}
+	}
+	return nil, model.ErrNotFound
+}
+
+// AdminByMobile return the admin of mobile.
+func (r *Repository) AdminByMobile(mobile string) (*model.Admin, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, a := range r.admins {
+		if a.mobile != "" && a.mobile == mobile {
+			return a.model(), nil
+		}
+	}
+	return nil, model.ErrNotFound
+}
+
+// AdminByEmail return the admin of email.
+func (r *Repository) AdminByEmail(email string) (*model.Admin, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, a := range r.admins {
+		if a.email != "" && a.email == email {
+			return a.model(), nil
+		}
+	}
+	return nil, model.ErrNotFound
+}
+
+// AdminByID return the admin of id.
+func (r *Repository) AdminByID(id uint32) (*model.Admin, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.admins[id]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+	return a.model(), nil
+}
+
+// Admins return all the admins by ID.
+func (r *Repository) Admins() ([]*model.Admin, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	admins := make([]*model.Admin, 0, len(r.admins))
+	for _, a := range r.admins {
+		admins = append(admins, a.model())
+	}
+	sort.Slice(admins, func(i, j int) bool { return admins[i].ID < admins[j].ID })
+
+	return admins, nil
+}
+
+// SearchAdmins return a page of the admins matching q and the count of all matched.
+func (r *Repository) SearchAdmins(q *model.AdminQuery) ([]*model.Admin, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*admin
+	for _, a := range r.admins {
+		if q.Active != nil && a.active != *q.Active {
+			continue
+		}
+		if q.Keyword != "" && !strings.Contains(a.name, q.Keyword) &&
+			!strings.Contains(a.mobile, q.Keyword) && !strings.Contains(a.email, q.Keyword) {
+			continue
+		}
+		matched = append(matched, a)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if q.Desc {
+			a, b = b, a
+		}
+
+		switch {
+		case q.Sort == model.SortByName && a.name != b.name:
+			return a.name < b.name
+		case q.Sort == model.SortByCreatedAt && !a.createdAt.Equal(b.createdAt):
+			return a.createdAt.Before(b.createdAt)
+		case q.Sort == model.SortByActive && a.active != b.active:
+			return !a.active
+		case q.Sort == model.SortByID || q.Sort == "":
+			return a.id < b.id
+		}
+		// the admins of the same are in ascending ID as MySQL does.
+		return matched[i].id < matched[j].id
+	})
+
+	admins := []*model.Admin{}
+	for i := q.Offset; i < len(matched) && len(admins) < q.Limit; i++ {
+		admins = append(admins, matched[i].model())
+	}
+
+	return admins, len(matched), nil
+}
+
+func (a *admin) model() *model.Admin {
+	return &model.Admin{
+		ID:                 a.id,
+		Name:               a.name,
+		Mobile:             a.mobile,
+		Email:              a.email,
+		PendingEmail:       a.pendingEmail,
+		Active:             a.active,
+		CreatedAt:          a.createdAt.Format(time.RFC3339),
+		Service:            a.service,
+		MustChangePassword: a.mustChangePassword,
+		Tenant:             a.tenant,
+	}
+}
+
+// RevokeToken revokes the token jti, and forgets the tokens already expired.
+func (r *Repository) RevokeToken(jti string, expire time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for k, t := range r.revokedTokens {
+		if t.Before(now) {
+			delete(r.revokedTokens, k)
+		}
+	}
+
+	r.revokedTokens[jti] = expire
+	return nil
+}
+
+// RevokeTokens revokes the tokens of the admin issued until at, in
+// milliseconds, and the sessions created until then.
+func (r *Repository) RevokeTokens(id uint32, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revokeBefore(id, at)
+	return nil
+}
+
+// revokeBefore revokes the tokens of the admin issued until at, and the
+// sessions created until then. r.mu must be locked.
+func (r *Repository) revokeBefore(id uint32, at time.Time) {
+	r.revokedBefore[id] = millis(at)
+
+	for _, s := range r.sessions {
+		if s.AdminID == id && !s.CreatedAt.After(at) {
+			s.Revoked = true
+		}
+	}
+}
+
+// IsRevoked reports whether the token jti of the admin issued at is revoked.
+func (r *Repository) IsRevoked(jti string, id uint32, issuedAt time.Time) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if millis(issuedAt) <= r.revokedBefore[id] {
+		return true, nil
+	}
+
+	_, ok := r.revokedTokens[jti]
+	return ok, nil
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
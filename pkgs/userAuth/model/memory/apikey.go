@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+// CreateAPIKey records a key of a service account.
+func (r *Repository) CreateAPIKey(k *model.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.apiKeys[k.ID]; ok {
+		return errs.Conflict("api key already exists")
+	}
+
+	r.apiKeys[k.ID] = copyAPIKey(k)
+	return nil
+}
+
+// APIKey returns ErrAPIKeyNotFound if there's no such key.
+func (r *Repository) APIKey(id string) (*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	k, ok := r.apiKeys[id]
+	if !ok {
+		return nil, model.ErrAPIKeyNotFound
+	}
+	return copyAPIKey(k), nil
+}
+
+// APIKeys lists the keys of the admin not revoked, the latest created first.
+func (r *Repository) APIKeys(adminID uint32) ([]*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := []*model.APIKey{}
+	for _, k := range r.apiKeys {
+		if k.AdminID == adminID && !k.Revoked {
+			keys = append(keys, copyAPIKey(k))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+
+	return keys, nil
+}
+
+// TouchAPIKey records when the key is used.
+func (r *Repository) TouchAPIKey(id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if k, ok := r.apiKeys[id]; ok {
+		k.LastUsed = at
+	}
+	return nil
+}
+
+// RevokeAPIKey returns ErrAPIKeyNotFound if the admin has no such key.
+func (r *Repository) RevokeAPIKey(adminID uint32, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.apiKeys[id]
+	if !ok || k.AdminID != adminID || k.Revoked {
+		return model.ErrAPIKeyNotFound
+	}
+
+	k.Revoked = true
+	return nil
+}
+
+func copyAPIKey(k *model.APIKey) *model.APIKey {
+	copied := *k
+	copied.Scopes = append([]string(nil), k.Scopes...)
+	return &copied
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+// identity is the subject of an issuer.
+type identity struct {
+	issuer, subject string
+}
+
+// LinkIdentity links the subject of the issuer to the admin.
+func (r *Repository) LinkIdentity(issuer, subject string, id uint32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := identity{issuer, subject}
+	if _, ok := r.identities[key]; ok {
+		return errs.Conflict("identity already exists")
+	}
+	if _, ok := r.admins[id]; !ok {
+		return model.ErrNotFound
+	}
+
+	r.identities[key] = id
+	return nil
+}
+
+// AdminByIdentity returns the admin linked to the subject of the issuer.
+func (r *Repository) AdminByIdentity(issuer, subject string) (*model.Admin, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.identities[identity{issuer, subject}]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+	a, ok := r.admins[id]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+	return a.model(), nil
+}
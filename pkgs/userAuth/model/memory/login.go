@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+)
+
+// maxAttempts is how many login attempts are kept in memory.
+const maxAttempts = 10000
+
+// AddLoginAttempt records a login in the history, the oldest is dropped if full.
+func (r *Repository) AddLoginAttempt(a *model.LoginAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *a
+	copied.ID = 1
+	if n := len(r.attempts); n > 0 {
+		copied.ID = r.attempts[n-1].ID + 1
+	}
+
+	r.attempts = append(r.attempts, &copied)
+	if len(r.attempts) > maxAttempts {
+		r.attempts = r.attempts[len(r.attempts)-maxAttempts:]
+	}
+	return nil
+}
+
+// LoginAttempts lists the latest attempts of name, of every name if it's empty.
+func (r *Repository) LoginAttempts(name string, limit int) ([]*model.LoginAttempt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var attempts []*model.LoginAttempt
+	for i := len(r.attempts) - 1; i >= 0 && len(attempts) < limit; i-- {
+		if name == "" || r.attempts[i].Name == name {
+			copied := *r.attempts[i]
+			attempts = append(attempts, &copied)
+		}
+	}
+	return attempts, nil
+}
+
+// Lockout returns nil if key has no failure.
+func (r *Repository) Lockout(key string) (*model.Lockout, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	l, ok := r.lockouts[key]
+	if !ok {
+		return nil, nil
+	}
+	copied := *l
+	return &copied, nil
+}
+
+// ReserveLockout counts a failure of key at, if its lockout is still l.
+func (r *Repository) ReserveLockout(key string, l *model.Lockout, at, forget time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, v := range r.lockouts {
+		if v.LastFailure.Before(forget) {
+			delete(r.lockouts, k)
+		}
+	}
+
+	current, ok := r.lockouts[key]
+	if l != nil && l.LastFailure.Before(forget) {
+		l = nil
+	}
+	switch {
+	case l == nil && ok:
+		return false, nil
+	case l == nil:
+		r.lockouts[key] = &model.Lockout{Key: key, Failures: 1, LastFailure: at}
+		return true, nil
+	case !ok || current.Failures != l.Failures || !current.LastFailure.Equal(l.LastFailure):
+		return false, nil
+	}
+
+	current.Failures++
+	current.LastFailure = at
+	return true, nil
+}
+
+// LockLockout locks key until then if it has lockAfter failures at least.
+func (r *Repository) LockLockout(key string, lockAfter int, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if l, ok := r.lockouts[key]; ok && l.Failures >= lockAfter {
+		l.LockedUntil = until
+	}
+	return nil
+}
+
+// ReleaseLockout uncounts a failure of key reserved by an attempt succeeded.
+func (r *Repository) ReleaseLockout(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if l, ok := r.lockouts[key]; ok && l.Failures > 0 {
+		l.Failures--
+	}
+	return nil
+}
+
+// ClearLockout forgets the failures of key.
+func (r *Repository) ClearLockout(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.lockouts, key)
+	return nil
+}
+
+// Lockouts lists the lockouts by key.
+func (r *Repository) Lockouts() ([]*model.Lockout, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lockouts := make([]*model.Lockout, 0, len(r.lockouts))
+	for _, l := range r.lockouts {
+		copied := *l
+		lockouts = append(lockouts, &copied)
+	}
+	sort.Slice(lockouts, func(i, j int) bool { return lockouts[i].Key < lockouts[j].Key })
+
+	return lockouts, nil
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abserari/shower/utils/salt"
+)
+
+func TestReserveLockout(t *testing.T) {
+	r := NewRepository(salt.Default())
+
+	const key = "name:victim"
+	now := time.Now()
+	forget := now.Add(-time.Hour)
+
+	if ok, err := r.ReserveLockout(key, nil, now, forget); err != nil || !ok {
+		t.Fatalf("first reservation: %v, %v", ok, err)
+	}
+	if ok, _ := r.ReserveLockout(key, nil, now, forget); ok {
+		t.Fatal("reserved without the lockout counted meanwhile")
+	}
+
+	l, err := r.Lockout(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := r.ReserveLockout(key, l, now.Add(time.Second), forget); !ok {
+		t.Fatal("not reserved on the current lockout")
+	}
+	if ok, _ := r.ReserveLockout(key, l, now.Add(time.Second), forget); ok {
+		t.Fatal("reserved twice on the same lockout")
+	}
+
+	if err = r.LockLockout(key, 3, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if l, _ = r.Lockout(key); l.Failures != 2 || !l.LockedUntil.IsZero() {
+		t.Fatalf("lockout %+v, want 2 failures unlocked", l)
+	}
+
+	if err = r.ReleaseLockout(key); err != nil {
+		t.Fatal(err)
+	}
+	if l, _ = r.Lockout(key); l.Failures != 1 {
+		t.Fatalf("%d failures after a release, want 1", l.Failures)
+	}
+
+	// a forgotten lockout is counted from the start.
+	if ok, _ := r.ReserveLockout(key, l, now.Add(2*time.Hour), now.Add(time.Hour)); !ok {
+		t.Fatal("not reserved on a forgotten lockout")
+	}
+	if l, _ = r.Lockout(key); l.Failures != 1 {
+		t.Fatalf("%d failures after forgetting, want 1", l.Failures)
+	}
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+)
+
+// CreateSession records a session, and forgets the sessions expired.
+func (r *Repository) CreateSession(s *model.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for k, v := range r.sessions {
+		if v.ExpiresAt.Before(now) {
+			delete(r.sessions, k)
+		}
+	}
+
+	copied := *s
+	r.sessions[s.ID] = &copied
+	return nil
+}
+
+// TouchSession updates the IP, user agent and last seen of the session.
+func (r *Repository) TouchSession(id, ip, userAgent string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.sessions[id]; ok {
+		s.IP, s.UserAgent, s.LastSeen = ip, userAgent, at
+	}
+	return nil
+}
+
+// Session returns ErrSessionNotFound if there's no such session.
+func (r *Repository) Session(id string) (*model.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, model.ErrSessionNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+// Sessions lists the sessions of the admin neither revoked nor expired, the latest seen first.
+func (r *Repository) Sessions(adminID uint32) ([]*model.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	sessions := []*model.Session{}
+	for _, s := range r.sessions {
+		if s.AdminID == adminID && !s.Revoked && !s.ExpiresAt.Before(now) {
+			copied := *s
+			sessions = append(sessions, &copied)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeen.After(sessions[j].LastSeen) })
+
+	return sessions, nil
+}
+
+// RevokeSession returns ErrSessionNotFound if the admin has no such session.
+func (r *Repository) RevokeSession(adminID uint32, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[id]
+	if !ok || s.AdminID != adminID || s.Revoked {
+		return model.ErrSessionNotFound
+	}
+
+	s.Revoked = true
+	return nil
+}
+
+// RevokeSessions revokes every session of the admin except the session except.
+func (r *Repository) RevokeSessions(adminID uint32, except string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.sessions {
+		if s.AdminID == adminID && s.ID != except {
+			s.Revoked = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"github.com/abserari/shower/pkgs/userAuth/model"
+)
+
+// TOTP returns nil if the admin has not enrolled.
+func (r *Repository) TOTP(id uint32) (*model.TOTP, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.totps[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyTOTP(t), nil
+}
+
+// SaveTOTP creates or replaces the TOTP of the admin.
+func (r *Repository) SaveTOTP(t *model.TOTP) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.totps[t.AdminID] = copyTOTP(t)
+	return nil
+}
+
+// UseTOTPStep records step as used, it reports false if the step or a later one is used.
+func (r *Repository) UseTOTPStep(id uint32, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.totps[id]
+	if !ok || t.LastStep >= step {
+		return false, nil
+	}
+
+	t.LastStep = step
+	return true, nil
+}
+
+// UseRecoveryCode removes the recovery code by hash, it reports false if there's no such code.
+func (r *Repository) UseRecoveryCode(id uint32, hash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.totps[id]
+	if !ok {
+		return false, nil
+	}
+
+	for i, code := range t.RecoveryCodes {
+		if code == hash {
+			t.RecoveryCodes = append(t.RecoveryCodes[:i:i], t.RecoveryCodes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteTOTP disables the two-factor authentication of the admin.
+func (r *Repository) DeleteTOTP(id uint32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.totps, id)
+	return nil
+}
+
+func copyTOTP(t *model.TOTP) *model.TOTP {
+	copied := *t
+	copied.RecoveryCodes = append([]string(nil), t.RecoveryCodes...)
+	return &copied
+}
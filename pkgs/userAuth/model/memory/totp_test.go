@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/salt"
+)
+
+func TestUseTOTPStep(t *testing.T) {
+	repo := NewRepository(salt.Default())
+
+	if ok, err := repo.UseTOTPStep(1, 100); err != nil || ok {
+		t.Fatalf("the step of an admin without TOTP is used: %t, %v", ok, err)
+	}
+
+	if err := repo.SaveTOTP(&model.TOTP{AdminID: 1, Secret: "secret", Confirmed: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		step int64
+		ok   bool
+	}{
+		{100, true},
+		{100, false}, // reused
+		{99, false},  // earlier than the used one
+		{101, true},
+		{100, false},
+	} {
+		ok, err := repo.UseTOTPStep(1, c.step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != c.ok {
+			t.Errorf("step %d: used %t, want %t", c.step, ok, c.ok)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package model
+
+import (
+	"time"
+
+	"github.com/abserari/shower/utils/errs"
+)
+
+var (
+	// ErrNotFound is returned if there's no such admin.
+	ErrNotFound = errs.NotFound("admin not found")
+	// ErrLoginFailed doesn't tell whether the name exists.
+	ErrLoginFailed = errs.Unauthorized("invalid username or password")
+	// ErrWrongPassword is the current password is wrong when modify it.
+	ErrWrongPassword = errs.Forbidden("the password is wrong")
+	// ErrSessionNotFound is returned if there's no such session.
+	ErrSessionNotFound = errs.NotFound("session not found")
+	// ErrNotPending is the email to confirm is not the pending one of the admin.
+	ErrNotPending = errs.NotFound("the email is not pending")
+	// ErrAPIKeyNotFound is returned if there's no such key.
+	ErrAPIKeyNotFound = errs.NotFound("api key not found")
+)
+
+// Admin is an administrative user, without the password.
+type Admin struct {
+	ID     uint32
+	Name   string
+	Mobile string
+	Email  string
+	// PendingEmail is the new email not yet confirmed.
+	PendingEmail string
+	Active       bool
+	CreatedAt    string
+	// Service accounts call the APIs by the API keys, they never login.
+	Service bool
+	// MustChangePassword limits the admin to change its own password, which clears it.
+	MustChangePassword bool
+	// Tenant is the organization of the admin, it's empty if there's none.
+	Tenant string
+}
+
+// The orders of the admins searched.
+const (
+	SortByID        = "id"
+	SortByName      = "name"
+	SortByCreatedAt = "created_at"
+	SortByActive    = "active"
+)
+
+// AdminQuery filters, sorts and pages the admins.
+type AdminQuery struct {
+	// Keyword is a part of the name, mobile or email, empty matches every admin.
+	Keyword string
+	// Active matches the admins of the flag if it's not nil.
+	Active *bool
+	// Sort is one of SortByID, SortByName, SortByCreatedAt and SortByActive,
+	// the admins of the same are sorted by ID.
+	Sort   string
+	Desc   bool
+	Offset int
+	Limit  int
+}
+
+// LoginAttempt is a login by name and password in the history.
+type LoginAttempt struct {
+	ID   uint64
+	Name string
+	IP   string
+	// AdminID is 0 if the login failed.
+	AdminID uint32
+	Success bool
+	At      time.Time
+}
+
+// Lockout counts the failed logins of a name or an IP, by key like
+// name:Admin or ip:127.0.0.1.
+type Lockout struct {
+	Key         string
+	Failures    int
+	LastFailure time.Time
+	// LockedUntil is zero if it's never locked.
+	LockedUntil time.Time
+}
+
+// TOTP is the two-factor authentication of an admin.
+type TOTP struct {
+	AdminID uint32
+	Secret  string
+	// Confirmed is false until the first code is checked.
+	Confirmed bool
+	// LastStep is the step of the last code used, a code is used once.
+	LastStep int64
+	// RecoveryCodes are the SHA-256 in hex of the unused recovery codes.
+	RecoveryCodes []string
+}
+
+// Session is a login of an admin on a device, the tokens refreshed from the
+// login are in the same session.
+type Session struct {
+	ID        string
+	AdminID   uint32
+	Device    string
+	IP        string
+	UserAgent string
+	CreatedAt time.Time
+	// LastSeen is when the token of the session is issued last time.
+	LastSeen time.Time
+	// ExpiresAt is when the session could not be refreshed any more.
+	ExpiresAt time.Time
+	Revoked   bool
+}
+
+// APIKey authenticates a service account, only the hash of the secret is kept.
+type APIKey struct {
+	ID      string
+	AdminID uint32
+	Name    string
+	// Hash is the SHA-256 in hex of the secret.
+	Hash string
+	// Scopes are the paths the key could call, a scope ending with / matches
+	// the paths under it.
+	Scopes    []string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	// LastUsed is zero if it's never used.
+	LastUsed time.Time
+	Revoked  bool
+}
+
+// Repository stores the administrative users, the passwords are salted hash.
+// Modifying the password or deactivating revokes the tokens of the admin.
+type Repository interface {
+	// CreateAdmin create an administrative userAuth
+	CreateAdmin(name, password *string) error
+	// CreateServiceAccount creates an admin without password, who couldn't login.
+	CreateServiceAccount(name string) error
+	// Login returns the admin ID if name and password match, a service account never logins.
+	Login(name, password *string) (uint32, error)
+	ModifyEmail(id uint32, email *string) error
+	ModifyMobile(id uint32, mobile *string) error
+	// SetPendingEmail keeps the new email of the admin until it's confirmed.
+	SetPendingEmail(id uint32, email *string) error
+	// ConfirmEmail replaces the email by the pending one if it's email,
+	// ErrNotPending is returned if it's not.
+	ConfirmEmail(id uint32, email string) error
+	// ModifyPassword checks the current password before update, the password
+	// needn't be changed then.
+	ModifyPassword(id uint32, password, newPassword *string) error
+	// ResetPassword sets the password without the current one, the password
+	// needn't be changed then.
+	ResetPassword(id uint32, password *string) error
+	// SetMustChangePassword sets whether the admin must change the password first.
+	SetMustChangePassword(id uint32, must bool) error
+	// ModifyTenant sets the organization of the admin, the tokens carrying
+	// the former one are revoked.
+	ModifyTenant(id uint32, tenant string) error
+	ModifyAdminActive(id uint32, active bool) error
+	IsActive(id uint32) (bool, error)
+	// AdminByName returns ErrNotFound if there's no such admin.
+	AdminByName(name string) (*Admin, error)
+	// AdminByMobile returns ErrNotFound if there's no such admin.
+	AdminByMobile(mobile string) (*Admin, error)
+	// AdminByEmail returns ErrNotFound if there's no such admin, the pending
+	// emails are not matched.
+	AdminByEmail(email string) (*Admin, error)
+	// AdminByID returns ErrNotFound if there's no such admin.
+	AdminByID(id uint32) (*Admin, error)
+	// Admins lists every admin by ID.
+	Admins() ([]*Admin, error)
+	// SearchAdmins returns a page of the admins matching q, and the count of all matched.
+	SearchAdmins(q *AdminQuery) ([]*Admin, int, error)
+
+	// RevokeToken revokes the token jti, it's forgotten after expire.
+	RevokeToken(jti string, expire time.Time) error
+	// RevokeTokens revokes the tokens of the admin issued until at, in
+	// milliseconds, and the sessions created until then.
+	RevokeTokens(id uint32, at time.Time) error
+	// IsRevoked reports whether the token jti of the admin issued at is revoked.
+	IsRevoked(jti string, id uint32, issuedAt time.Time) (bool, error)
+
+	// AddLoginAttempt records a login in the history.
+	AddLoginAttempt(a *LoginAttempt) error
+	// LoginAttempts lists the latest attempts of name, of every name if it's empty.
+	LoginAttempts(name string, limit int) ([]*LoginAttempt, error)
+	// Lockout returns nil if key has no failure.
+	Lockout(key string) (*Lockout, error)
+	// ReserveLockout counts a failure of key at, if its lockout is still l,
+	// nil if it had none. It reports false if another attempt counted
+	// meanwhile. The lockouts whose last failure is before forget are forgotten.
+	ReserveLockout(key string, l *Lockout, at, forget time.Time) (bool, error)
+	// LockLockout locks key until then if it has lockAfter failures at least.
+	LockLockout(key string, lockAfter int, until time.Time) error
+	// ReleaseLockout uncounts a failure of key reserved by an attempt succeeded.
+	ReleaseLockout(key string) error
+	// ClearLockout forgets the failures of key.
+	ClearLockout(key string) error
+	// Lockouts lists the lockouts by key.
+	Lockouts() ([]*Lockout, error)
+
+	// TOTP returns nil if the admin has not enrolled.
+	TOTP(id uint32) (*TOTP, error)
+	// SaveTOTP creates or replaces the TOTP of the admin.
+	SaveTOTP(t *TOTP) error
+	// UseTOTPStep records step as used, it reports false if the step or a later one is used.
+	UseTOTPStep(id uint32, step int64) (bool, error)
+	// UseRecoveryCode removes the recovery code by hash, it reports false if there's no such code.
+	UseRecoveryCode(id uint32, hash string) (bool, error)
+	// DeleteTOTP disables the two-factor authentication of the admin.
+	DeleteTOTP(id uint32) error
+
+	// CreateSession records a session, and forgets the sessions expired.
+	CreateSession(s *Session) error
+	// TouchSession updates the IP, user agent and last seen of the session.
+	TouchSession(id, ip, userAgent string, at time.Time) error
+	// Session returns ErrSessionNotFound if there's no such session.
+	Session(id string) (*Session, error)
+	// Sessions lists the sessions of the admin neither revoked nor expired, the latest seen first.
+	Sessions(adminID uint32) ([]*Session, error)
+	// RevokeSession returns ErrSessionNotFound if the admin has no such session.
+	RevokeSession(adminID uint32, id string) error
+	// RevokeSessions revokes every session of the admin except the session except.
+	RevokeSessions(adminID uint32, except string) error
+
+	// LinkIdentity links the subject of the issuer to the admin.
+	LinkIdentity(issuer, subject string, id uint32) error
+	// AdminByIdentity returns ErrNotFound if no admin is linked to the subject of the issuer.
+	AdminByIdentity(issuer, subject string) (*Admin, error)
+
+	// CreateAPIKey records a key of a service account.
+	CreateAPIKey(k *APIKey) error
+	// APIKey returns ErrAPIKeyNotFound if there's no such key.
+	APIKey(id string) (*APIKey, error)
+	// APIKeys lists the keys of the admin not revoked, the latest created first.
+	APIKeys(adminID uint32) ([]*APIKey, error)
+	// TouchAPIKey records when the key is used.
+	TouchAPIKey(id string, at time.Time) error
+	// RevokeAPIKey returns ErrAPIKeyNotFound if the admin has no such key.
+	RevokeAPIKey(adminID uint32, id string) error
+}
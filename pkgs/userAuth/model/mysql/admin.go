@@ -11,6 +11,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+	"github.com/abserari/shower/utils/migrate"
 	"github.com/abserari/shower/utils/salt"
 )
 
@@ -25,6 +28,30 @@ const (
 	mysqlUserModifyPassword
 	mysqlUserModifyActive
 	mysqlUserGetIsActive
+	mysqlUserDropTable
+	mysqlUserDeleteByName
+	mysqlUserGetByName
+	mysqlUserList
+	mysqlUserGetByMobile
+	mysqlUserGetByID
+	mysqlUserCount
+	mysqlUserSearch
+	mysqlUserRehash
+	mysqlUserAddPendingEmail
+	mysqlUserDropPendingEmail
+	mysqlUserSetPendingEmail
+	mysqlUserConfirmEmail
+	mysqlUserGetByEmail
+	mysqlUserAddService
+	mysqlUserDropService
+	mysqlUserInsertService
+	mysqlUserAddMustChangePassword
+	mysqlUserDropMustChangePassword
+	mysqlUserSetMustChangePassword
+	mysqlUserGetPasswords
+	mysqlUserAddTenant
+	mysqlUserDropTenant
+	mysqlUserModifyTenant
 )
 
 const (
@@ -34,7 +61,6 @@ const (
 
 var (
 	errInvalidMysql = errors.New("affected 0 rows")
-	errLoginFailed  = errors.New("invalid username or password")
 
 	adminSQLString = []string{
 		fmt.Sprintf(`CREATE DATABASE IF NOT EXISTS %s ;`, DBName),
@@ -49,16 +75,180 @@ var (
 			PRIMARY KEY (admin_id)
 		) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`, DBName, TableName),
 		fmt.Sprintf(`INSERT INTO %s.%s (name,password,active)  VALUES (?,?,?)`, DBName, TableName),
-		fmt.Sprintf(`SELECT admin_id,password FROM %s.%s WHERE name = ? LOCK IN SHARE MODE`, DBName, TableName),
+		fmt.Sprintf(`SELECT admin_id,password FROM %s.%s WHERE name = ? AND service = FALSE LOCK IN SHARE MODE`, DBName, TableName),
 		fmt.Sprintf(`UPDATE %s.%s SET email=? WHERE admin_id = ? LIMIT 1`, DBName, TableName),
 		fmt.Sprintf(`UPDATE %s.%s SET mobile=? WHERE admin_id = ? LIMIT 1`, DBName, TableName),
 		fmt.Sprintf(`SELECT password FROM %s.%s WHERE admin_id = ?  LOCK IN SHARE MODE`, DBName, TableName),
-		fmt.Sprintf(`UPDATE %s.%s SET password = ? WHERE admin_id = ? LIMIT 1`, DBName, TableName),
+		fmt.Sprintf(`UPDATE %s.%s SET password = ?, must_change_password = FALSE WHERE admin_id = ? LIMIT 1`, DBName, TableName),
 		fmt.Sprintf(`UPDATE %s.%s SET active = ? WHERE admin_id = ? LIMIT 1`, DBName, TableName),
 		fmt.Sprintf(`SELECT active FROM %s.%s WHERE admin_id = ? LOCK IN SHARE MODE`, DBName, TableName),
+		fmt.Sprintf(`DROP TABLE IF EXISTS %s.%s`, DBName, TableName),
+		fmt.Sprintf(`DELETE FROM %s.%s WHERE name = ? LIMIT 1`, DBName, TableName),
+		fmt.Sprintf(`SELECT admin_id,name,mobile,email,pending_email,active,created_at,service,must_change_password,tenant FROM %s.%s WHERE name = ? LOCK IN SHARE MODE`, DBName, TableName),
+		fmt.Sprintf(`SELECT admin_id,name,mobile,email,pending_email,active,created_at,service,must_change_password,tenant FROM %s.%s ORDER BY admin_id`, DBName, TableName),
+		fmt.Sprintf(`SELECT admin_id,name,mobile,email,pending_email,active,created_at,service,must_change_password,tenant FROM %s.%s WHERE mobile = ? LOCK IN SHARE MODE`, DBName, TableName),
+		fmt.Sprintf(`SELECT admin_id,name,mobile,email,pending_email,active,created_at,service,must_change_password,tenant FROM %s.%s WHERE admin_id = ? LOCK IN SHARE MODE`, DBName, TableName),
+		// the condition and order are filled by SearchAdmins.
+		fmt.Sprintf(`SELECT COUNT(*) FROM %s.%s WHERE %%s`, DBName, TableName),
+		fmt.Sprintf(`SELECT admin_id,name,mobile,email,pending_email,active,created_at,service,must_change_password,tenant FROM %s.%s WHERE %%s ORDER BY %%s LIMIT ? OFFSET ?`, DBName, TableName),
+		fmt.Sprintf(`UPDATE %s.%s SET password = ? WHERE admin_id = ? AND password = ? LIMIT 1`, DBName, TableName),
+		fmt.Sprintf(`ALTER TABLE %s.%s ADD COLUMN pending_email VARCHAR(128) DEFAULT NULL`, DBName, TableName),
+		fmt.Sprintf(`ALTER TABLE %s.%s DROP COLUMN pending_email`, DBName, TableName),
+		fmt.Sprintf(`UPDATE %s.%s SET pending_email = ? WHERE admin_id = ? LIMIT 1`, DBName, TableName),
+		fmt.Sprintf(`UPDATE %s.%s SET email = pending_email, pending_email = NULL WHERE admin_id = ? AND pending_email = ? LIMIT 1`, DBName, TableName),
+		fmt.Sprintf(`SELECT admin_id,name,mobile,email,pending_email,active,created_at,service,must_change_password,tenant FROM %s.%s WHERE email = ? LOCK IN SHARE MODE`, DBName, TableName),
+		fmt.Sprintf(`ALTER TABLE %s.%s ADD COLUMN service BOOLEAN NOT NULL DEFAULT FALSE`, DBName, TableName),
+		fmt.Sprintf(`ALTER TABLE %s.%s DROP COLUMN service`, DBName, TableName),
+		fmt.Sprintf(`INSERT INTO %s.%s (name,password,active,service) VALUES (?,'',TRUE,TRUE)`, DBName, TableName),
+		fmt.Sprintf(`ALTER TABLE %s.%s ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT FALSE`, DBName, TableName),
+		fmt.Sprintf(`ALTER TABLE %s.%s DROP COLUMN must_change_password`, DBName, TableName),
+		fmt.Sprintf(`UPDATE %s.%s SET must_change_password = ? WHERE admin_id = ? LIMIT 1`, DBName, TableName),
+		fmt.Sprintf(`SELECT admin_id,password FROM %s.%s WHERE service = FALSE`, DBName, TableName),
+		fmt.Sprintf(`ALTER TABLE %s.%s ADD COLUMN tenant VARCHAR(64) NOT NULL DEFAULT ''`, DBName, TableName),
+		fmt.Sprintf(`ALTER TABLE %s.%s DROP COLUMN tenant`, DBName, TableName),
+		fmt.Sprintf(`UPDATE %s.%s SET tenant = ? WHERE admin_id = ? LIMIT 1`, DBName, TableName),
 	}
+
+	// sortColumns are the columns of the orders of AdminQuery.
+	sortColumns = map[string]string{
+		model.SortByID:        "admin_id",
+		model.SortByName:      "name",
+		model.SortByCreatedAt: "created_at",
+		model.SortByActive:    "active",
+	}
+
+	likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
 )
 
+// legacyPassword is the fixed password of the admins seeded or created by
+// default before, they must change it.
+const legacyPassword = "111111"
+
+// Migrations of userAuth table, the passwords are checked by h.
+func Migrations(h salt.Hasher) []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version:     1,
+			Description: "create userAuth table",
+			Up:          []string{adminSQLString[mysqlUserCreateDatabase], adminSQLString[mysqlUserCreateTable]},
+			Down:        []string{adminSQLString[mysqlUserDropTable]},
+		},
+		{
+			// the bootstrap admin is seeded at the start instead.
+			Version:     2,
+			Description: "seed the default userAuth",
+		},
+		{
+			Version:     3,
+			Description: "create the tables of the revoked tokens",
+			Up: []string{
+				revocationSQLString[mysqlRevokedTokenCreateTable],
+				revocationSQLString[mysqlRevokedAdminCreateTable],
+			},
+			Down: []string{
+				revocationSQLString[mysqlRevokedAdminDropTable],
+				revocationSQLString[mysqlRevokedTokenDropTable],
+			},
+		},
+		{
+			Version:     4,
+			Description: "create the tables of the login history and lockouts",
+			Up: []string{
+				loginSQLString[mysqlLoginAttemptCreateTable],
+				loginSQLString[mysqlLockoutCreateTable],
+			},
+			Down: []string{
+				loginSQLString[mysqlLockoutDropTable],
+				loginSQLString[mysqlLoginAttemptDropTable],
+			},
+		},
+		{
+			Version:     5,
+			Description: "create the table of the two-factor authentication",
+			Up:          []string{totpSQLString[mysqlTOTPCreateTable]},
+			Down:        []string{totpSQLString[mysqlTOTPDropTable]},
+		},
+		{
+			Version:     6,
+			Description: "add the email pending confirmation",
+			Up:          []string{adminSQLString[mysqlUserAddPendingEmail]},
+			Down:        []string{adminSQLString[mysqlUserDropPendingEmail]},
+		},
+		{
+			Version:     7,
+			Description: "create the table of the sessions",
+			Up:          []string{sessionSQLString[mysqlSessionCreateTable]},
+			Down:        []string{sessionSQLString[mysqlSessionDropTable]},
+		},
+		{
+			Version:     8,
+			Description: "create the table of the identities of the providers",
+			Up:          []string{identitySQLString[mysqlIdentityCreateTable]},
+			Down:        []string{identitySQLString[mysqlIdentityDropTable]},
+		},
+		{
+			Version:     9,
+			Description: "add the service accounts and the table of the API keys",
+			Up: []string{
+				adminSQLString[mysqlUserAddService],
+				apiKeySQLString[mysqlAPIKeyCreateTable],
+			},
+			Down: []string{
+				apiKeySQLString[mysqlAPIKeyDropTable],
+				adminSQLString[mysqlUserDropService],
+			},
+		},
+		{
+			Version:     10,
+			Description: "add the password must be changed, the admins of the default password must change it",
+			Up:          []string{adminSQLString[mysqlUserAddMustChangePassword]},
+			Down:        []string{adminSQLString[mysqlUserDropMustChangePassword]},
+			UpFunc: func(db *sql.DB) error {
+				return flagLegacyPasswords(db, h)
+			},
+		},
+		{
+			Version:     11,
+			Description: "add the tenant of the admins",
+			Up:          []string{adminSQLString[mysqlUserAddTenant]},
+			Down:        []string{adminSQLString[mysqlUserDropTenant]},
+		},
+	}
+}
+
+// flagLegacyPasswords sets the admins of legacyPassword must change it.
+func flagLegacyPasswords(db *sql.DB, h salt.Hasher) error {
+	rows, err := db.Query(adminSQLString[mysqlUserGetPasswords])
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var ids []uint32
+	for rows.Next() {
+		var (
+			id   uint32
+			hash string
+		)
+		if err = rows.Scan(&id, &hash); err != nil {
+			return err
+		}
+		if ok, _ := h.Verify(hash, legacyPassword); ok {
+			ids = append(ids, id)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err = SetMustChangePassword(db, id, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CreateDatabase create userAuth table.
 func CreateDatabase(db *sql.DB) error {
 	_, err := db.Exec(adminSQLString[mysqlUserCreateDatabase])
@@ -70,72 +260,118 @@ func CreateDatabase(db *sql.DB) error {
 }
 
 // CreateTable create userAuth table.
-func CreateTable(db *sql.DB, name, password *string) error {
+func CreateTable(db *sql.DB) error {
 	_, err := db.Exec(adminSQLString[mysqlUserCreateTable])
-	if err != nil {
-		return err
-	}
 
-	//
-	err = CreateAdmin(db, name, password)
-	if err != nil {
-		// don't error when create userAuth userAuth twice.
-		if strings.Contains(err.Error(), "Duplicate entry") {
-			return nil
-		}
-		return err
-	}
-	return nil
+	return err
 }
 
 //CreateAdmin create an administrative userAuth
-func CreateAdmin(db *sql.DB, name, password *string) error {
-	hash, err := salt.Generate(password)
+func CreateAdmin(db *sql.DB, h salt.Hasher, name, password *string) error {
+	hash, err := h.Hash(*password)
 	if err != nil {
 		return err
 	}
 
 	result, err := db.Exec(adminSQLString[mysqlUserInsert], name, hash, true)
 	if err != nil {
-		return err
+		return errs.FromDB(err, "admin")
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidMysql
+		return errs.Internal(errInvalidMysql)
 	}
 
 	return nil
 }
 
-//Login the administrative userAuth logins
-func Login(db *sql.DB, name, password *string) (uint32, error) {
+// CreateServiceAccount creates an admin without password, who couldn't login.
+func CreateServiceAccount(db *sql.DB, name string) error {
+	result, err := db.Exec(adminSQLString[mysqlUserInsertService], name)
+	if err != nil {
+		return errs.FromDB(err, "admin")
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errs.Internal(errInvalidMysql)
+	}
+
+	return nil
+}
+
+//Login the administrative userAuth logins, the password is hashed again if
+// the hash is outdated for h.
+func Login(db *sql.DB, h salt.Hasher, name, password *string) (uint32, error) {
 	var (
 		id  uint32
 		pwd string
 	)
 
 	err := db.QueryRow(adminSQLString[mysqlUserLogin], name).Scan(&id, &pwd)
+	if err == sql.ErrNoRows {
+		return 0, model.ErrLoginFailed
+	}
 	if err != nil {
-		return 0, err
+		return 0, errs.FromDB(err, "admin")
 	}
 
-	if !salt.Compare([]byte(pwd), password) {
-		return 0, errLoginFailed
+	ok, rehash := h.Verify(pwd, *password)
+	if !ok {
+		return 0, model.ErrLoginFailed
+	}
+
+	if rehash {
+		if err = rehashPassword(db, h, id, pwd, *password); err != nil {
+			return 0, err
+		}
 	}
 
 	return id, nil
 }
 
+// rehashPassword replaces the hash of the admin unless it's modified since read.
+func rehashPassword(db *sql.DB, h salt.Hasher, id uint32, hash, password string) error {
+	newHash, err := h.Hash(password)
+	if err != nil {
+		return errs.Internal(err)
+	}
+
+	_, err = db.Exec(adminSQLString[mysqlUserRehash], newHash, id, hash)
+	return errs.FromDB(err, "admin")
+}
+
 // ModifyEmail the administrative userAuth updates email
 func ModifyEmail(db *sql.DB, id uint32, email *string) error {
 
 	result, err := db.Exec(adminSQLString[mysqlUserModifyEmail], email, id)
 	if err != nil {
-		return err
+		return errs.FromDB(err, "email")
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+// SetPendingEmail the administrative userAuth sets the email to confirm
+func SetPendingEmail(db *sql.DB, id uint32, email *string) error {
+	// no row is affected if the same email is pending again.
+	_, err := db.Exec(adminSQLString[mysqlUserSetPendingEmail], email, id)
+
+	return errs.FromDB(err, "admin")
+}
+
+// ConfirmEmail the administrative userAuth replaces email by the pending one
+func ConfirmEmail(db *sql.DB, id uint32, email string) error {
+	result, err := db.Exec(adminSQLString[mysqlUserConfirmEmail], id, email)
+	if err != nil {
+		return errs.FromDB(err, "email")
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidMysql
+		return model.ErrNotPending
 	}
 
 	return nil
@@ -146,61 +382,260 @@ func ModifyMobile(db *sql.DB, id uint32, mobile *string) error {
 
 	result, err := db.Exec(adminSQLString[mysqlUserModifyMobile], mobile, id)
 	if err != nil {
-		return err
+		return errs.FromDB(err, "mobile")
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidMysql
+		return model.ErrNotFound
 	}
 
 	return nil
 }
 
 // ModifyPassword the administrative userAuth updates password
-func ModifyPassword(db *sql.DB, id uint32, password, newPassword *string) error {
+func ModifyPassword(db *sql.DB, h salt.Hasher, id uint32, password, newPassword *string) error {
 	var (
 		pwd string
 	)
 
 	err := db.QueryRow(adminSQLString[mysqlUserGetPassword], id).Scan(&pwd)
 	if err != nil {
-		return err
+		return errs.FromDB(err, "admin")
 	}
 
-	if !salt.Compare([]byte(pwd), password) {
-		return errLoginFailed
+	if ok, _ := h.Verify(pwd, *password); !ok {
+		return model.ErrWrongPassword
 	}
 
-	hash, err := salt.Generate(newPassword)
+	hash, err := h.Hash(*newPassword)
 	if err != nil {
 		return err
 	}
 
 	_, err = db.Exec(adminSQLString[mysqlUserModifyPassword], hash, id)
 
-	return err
+	return errs.FromDB(err, "admin")
+}
+
+// ResetPassword the administrative userAuth sets password without the current one
+func ResetPassword(db *sql.DB, h salt.Hasher, id uint32, password *string) error {
+	hash, err := h.Hash(*password)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(adminSQLString[mysqlUserModifyPassword], hash, id)
+	if err != nil {
+		return errs.FromDB(err, "admin")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+// SetMustChangePassword sets whether the admin must change the password first
+func SetMustChangePassword(db *sql.DB, id uint32, must bool) error {
+	result, err := db.Exec(adminSQLString[mysqlUserSetMustChangePassword], must, id)
+	if err != nil {
+		return errs.FromDB(err, "admin")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		// no row is affected if it's set already.
+		if _, err = AdminByID(db, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ModifyTenant the administrative userAuth updates tenant
+func ModifyTenant(db *sql.DB, id uint32, tenant string) error {
+	result, err := db.Exec(adminSQLString[mysqlUserModifyTenant], tenant, id)
+	if err != nil {
+		return errs.FromDB(err, "admin")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		// no row is affected if it's the same.
+		if _, err = AdminByID(db, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 //ModifyAdminActive the administrative userAuth updates active
 func ModifyAdminActive(db *sql.DB, id uint32, active bool) error {
 	result, err := db.Exec(adminSQLString[mysqlUserModifyActive], active, id)
 	if err != nil {
-		return err
+		return errs.FromDB(err, "admin")
 	}
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return errInvalidMysql
+		return model.ErrNotFound
 	}
 
 	return nil
 
 }
 
-//IsActive return userAuth.Active and nil if query success.
+//IsActive return userAuth.Active and nil if query success, an unknown userAuth is inactive.
 func IsActive(db *sql.DB, id uint32) (bool, error) {
 	var (
 		isActive bool
 	)
 
-	db.QueryRow(adminSQLString[mysqlUserGetIsActive], id).Scan(&isActive)
+	err := db.QueryRow(adminSQLString[mysqlUserGetIsActive], id).Scan(&isActive)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, errs.FromDB(err, "admin")
+	}
+
 	return isActive, nil
 }
+
+// AdminByName return the admin of name.
+func AdminByName(db *sql.DB, name string) (*model.Admin, error) {
+	a, err := scanAdmin(db.QueryRow(adminSQLString[mysqlUserGetByName], name))
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, errs.FromDB(err, "admin")
+	}
+
+	return a, nil
+}
+
+// AdminByMobile return the admin of mobile.
+func AdminByMobile(db *sql.DB, mobile string) (*model.Admin, error) {
+	a, err := scanAdmin(db.QueryRow(adminSQLString[mysqlUserGetByMobile], mobile))
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, errs.FromDB(err, "admin")
+	}
+
+	return a, nil
+}
+
+// AdminByEmail return the admin of email.
+func AdminByEmail(db *sql.DB, email string) (*model.Admin, error) {
+	a, err := scanAdmin(db.QueryRow(adminSQLString[mysqlUserGetByEmail], email))
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, errs.FromDB(err, "admin")
+	}
+
+	return a, nil
+}
+
+// AdminByID return the admin of id.
+func AdminByID(db *sql.DB, id uint32) (*model.Admin, error) {
+	a, err := scanAdmin(db.QueryRow(adminSQLString[mysqlUserGetByID], id))
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, errs.FromDB(err, "admin")
+	}
+
+	return a, nil
+}
+
+// Admins return all the admins.
+func Admins(db *sql.DB) ([]*model.Admin, error) {
+	rows, err := db.Query(adminSQLString[mysqlUserList])
+	if err != nil {
+		return nil, errs.FromDB(err, "admin")
+	}
+	defer rows.Close()
+
+	var admins []*model.Admin
+	for rows.Next() {
+		a, err := scanAdmin(rows)
+		if err != nil {
+			return nil, errs.FromDB(err, "admin")
+		}
+		admins = append(admins, a)
+	}
+
+	return admins, errs.FromDB(rows.Err(), "admin")
+}
+
+// SearchAdmins return a page of the admins matching q and the count of all matched.
+func SearchAdmins(db *sql.DB, q *model.AdminQuery) ([]*model.Admin, int, error) {
+	var (
+		conditions = []string{"TRUE"}
+		args       []interface{}
+	)
+
+	if q.Keyword != "" {
+		like := "%" + likeEscaper.Replace(q.Keyword) + "%"
+		conditions = append(conditions, "(name LIKE ? OR mobile LIKE ? OR email LIKE ?)")
+		args = append(args, like, like, like)
+	}
+	if q.Active != nil {
+		conditions = append(conditions, "active = ?")
+		args = append(args, *q.Active)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	column, ok := sortColumns[q.Sort]
+	if !ok {
+		column = sortColumns[model.SortByID]
+	}
+	order := column
+	if q.Desc {
+		order += " DESC"
+	}
+	if column != sortColumns[model.SortByID] {
+		order += ",admin_id"
+	}
+
+	var total int
+	err := db.QueryRow(fmt.Sprintf(adminSQLString[mysqlUserCount], where), args...).Scan(&total)
+	if err != nil {
+		return nil, 0, errs.FromDB(err, "admin")
+	}
+
+	rows, err := db.Query(fmt.Sprintf(adminSQLString[mysqlUserSearch], where, order), append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, errs.FromDB(err, "admin")
+	}
+	defer rows.Close()
+
+	admins := []*model.Admin{}
+	for rows.Next() {
+		a, err := scanAdmin(rows)
+		if err != nil {
+			return nil, 0, errs.FromDB(err, "admin")
+		}
+		admins = append(admins, a)
+	}
+
+	return admins, total, errs.FromDB(rows.Err(), "admin")
+}
+
+func scanAdmin(row interface{ Scan(dest ...interface{}) error }) (*model.Admin, error) {
+	var (
+		a                      model.Admin
+		mobile, email, pending sql.NullString
+	)
+
+	if err := row.Scan(&a.ID, &a.Name, &mobile, &email, &pending, &a.Active, &a.CreatedAt, &a.Service, &a.MustChangePassword, &a.Tenant); err != nil {
+		return nil, err
+	}
+	a.Mobile = mobile.String
+	a.Email = email.String
+	a.PendingEmail = pending.String
+
+	return &a, nil
+}
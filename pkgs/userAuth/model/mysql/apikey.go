@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	mysqlAPIKeyCreateTable = iota
+	mysqlAPIKeyInsert
+	mysqlAPIKeyGet
+	mysqlAPIKeyList
+	mysqlAPIKeyTouch
+	mysqlAPIKeyRevoke
+	mysqlAPIKeyDropTable
+)
+
+const APIKeyTable = "api_key"
+
+var (
+	apiKeySQLString = []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+			id         VARCHAR(32) NOT NULL,
+			admin_id   BIGINT UNSIGNED NOT NULL,
+			name       VARCHAR(64) NOT NULL,
+			hash       CHAR(64) NOT NULL COMMENT 'SHA-256 in hex of the secret',
+			scopes     TEXT NOT NULL COMMENT 'separated by spaces',
+			created_at BIGINT NOT NULL COMMENT 'unix milliseconds',
+			expires_at BIGINT NOT NULL COMMENT 'unix milliseconds',
+			last_used  BIGINT NOT NULL DEFAULT 0 COMMENT 'unix milliseconds',
+			revoked    BOOLEAN NOT NULL DEFAULT FALSE,
+			PRIMARY KEY (id),
+			INDEX (admin_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`, DBName, APIKeyTable),
+		fmt.Sprintf(`INSERT INTO %s.%s (id,admin_id,name,hash,scopes,created_at,expires_at,last_used,revoked) VALUES (?,?,?,?,?,?,?,?,?)`, DBName, APIKeyTable),
+		fmt.Sprintf(`SELECT id,admin_id,name,hash,scopes,created_at,expires_at,last_used,revoked FROM %s.%s WHERE id = ?`, DBName, APIKeyTable),
+		fmt.Sprintf(`SELECT id,admin_id,name,hash,scopes,created_at,expires_at,last_used,revoked FROM %s.%s WHERE admin_id = ? AND revoked = FALSE ORDER BY created_at DESC`, DBName, APIKeyTable),
+		fmt.Sprintf(`UPDATE %s.%s SET last_used = ? WHERE id = ? LIMIT 1`, DBName, APIKeyTable),
+		fmt.Sprintf(`UPDATE %s.%s SET revoked = TRUE WHERE admin_id = ? AND id = ? AND revoked = FALSE LIMIT 1`, DBName, APIKeyTable),
+		fmt.Sprintf(`DROP TABLE IF EXISTS %s.%s`, DBName, APIKeyTable),
+	}
+)
+
+// CreateAPIKey records a key of a service account.
+func CreateAPIKey(db *sql.DB, k *model.APIKey) error {
+	var lastUsed int64
+	if !k.LastUsed.IsZero() {
+		lastUsed = millis(k.LastUsed)
+	}
+
+	_, err := db.Exec(apiKeySQLString[mysqlAPIKeyInsert], k.ID, k.AdminID, k.Name, k.Hash, strings.Join(k.Scopes, " "),
+		millis(k.CreatedAt), millis(k.ExpiresAt), lastUsed, k.Revoked)
+	return errs.FromDB(err, "api key")
+}
+
+// APIKey returns the key of id.
+func APIKey(db *sql.DB, id string) (*model.APIKey, error) {
+	k, err := scanAPIKey(db.QueryRow(apiKeySQLString[mysqlAPIKeyGet], id))
+	if err == sql.ErrNoRows {
+		return nil, model.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, errs.FromDB(err, "api key")
+	}
+
+	return k, nil
+}
+
+// APIKeys lists the keys of the admin not revoked, the latest created first.
+func APIKeys(db *sql.DB, adminID uint32) ([]*model.APIKey, error) {
+	rows, err := db.Query(apiKeySQLString[mysqlAPIKeyList], adminID)
+	if err != nil {
+		return nil, errs.FromDB(err, "api key")
+	}
+	defer rows.Close()
+
+	keys := []*model.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, errs.FromDB(err, "api key")
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, errs.FromDB(rows.Err(), "api key")
+}
+
+// TouchAPIKey records when the key is used.
+func TouchAPIKey(db *sql.DB, id string, at time.Time) error {
+	_, err := db.Exec(apiKeySQLString[mysqlAPIKeyTouch], millis(at), id)
+	return errs.FromDB(err, "api key")
+}
+
+// RevokeAPIKey revokes the key of the admin.
+func RevokeAPIKey(db *sql.DB, adminID uint32, id string) error {
+	result, err := db.Exec(apiKeySQLString[mysqlAPIKeyRevoke], adminID, id)
+	if err != nil {
+		return errs.FromDB(err, "api key")
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return model.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func scanAPIKey(row interface {
+	Scan(dest ...interface{}) error
+}) (*model.APIKey, error) {
+	var (
+		k                              model.APIKey
+		scopes                         string
+		createdAt, expiresAt, lastUsed int64
+	)
+
+	err := row.Scan(&k.ID, &k.AdminID, &k.Name, &k.Hash, &scopes, &createdAt, &expiresAt, &lastUsed, &k.Revoked)
+	if err != nil {
+		return nil, err
+	}
+	k.Scopes = strings.Fields(scopes)
+	k.CreatedAt = fromMillis(createdAt)
+	k.ExpiresAt = fromMillis(expiresAt)
+	if lastUsed != 0 {
+		k.LastUsed = fromMillis(lastUsed)
+	}
+
+	return &k, nil
+}
@@ -0,0 +1,56 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	mysqlIdentityCreateTable = iota
+	mysqlIdentityInsert
+	mysqlIdentityGetAdmin
+	mysqlIdentityDropTable
+)
+
+const IdentityTable = "identity"
+
+var (
+	identitySQLString = []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+			issuer     VARCHAR(255) NOT NULL,
+			subject    VARCHAR(255) NOT NULL,
+			admin_id   BIGINT UNSIGNED NOT NULL,
+			created_at BIGINT NOT NULL COMMENT 'unix milliseconds',
+			PRIMARY KEY (issuer, subject),
+			INDEX (admin_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`, DBName, IdentityTable),
+		fmt.Sprintf(`INSERT INTO %s.%s (issuer,subject,admin_id,created_at) VALUES (?,?,?,?)`, DBName, IdentityTable),
+		fmt.Sprintf(`SELECT admin_id FROM %s.%s WHERE issuer = ? AND subject = ?`, DBName, IdentityTable),
+		fmt.Sprintf(`DROP TABLE IF EXISTS %s.%s`, DBName, IdentityTable),
+	}
+)
+
+// LinkIdentity links the subject of the issuer to the admin.
+func LinkIdentity(db *sql.DB, issuer, subject string, id uint32) error {
+	_, err := db.Exec(identitySQLString[mysqlIdentityInsert], issuer, subject, id, millis(time.Now()))
+	return errs.FromDB(err, "identity")
+}
+
+// AdminByIdentity returns the admin linked to the subject of the issuer.
+func AdminByIdentity(db *sql.DB, issuer, subject string) (*model.Admin, error) {
+	var id uint32
+
+	err := db.QueryRow(identitySQLString[mysqlIdentityGetAdmin], issuer, subject).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, errs.FromDB(err, "identity")
+	}
+
+	return AdminByID(db, id)
+}
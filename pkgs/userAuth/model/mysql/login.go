@@ -0,0 +1,218 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	mysqlLoginAttemptCreateTable = iota
+	mysqlLockoutCreateTable
+	mysqlLoginAttemptInsert
+	mysqlLoginAttemptList
+	mysqlLoginAttemptListByName
+	mysqlLockoutGet
+	mysqlLockoutInsert
+	mysqlLockoutReserve
+	mysqlLockoutLock
+	mysqlLockoutRelease
+	mysqlLockoutDeleteBefore
+	mysqlLockoutDelete
+	mysqlLockoutList
+	mysqlLoginAttemptDropTable
+	mysqlLockoutDropTable
+)
+
+const (
+	LoginAttemptTable = "login_attempt"
+	LockoutTable      = "lockout"
+)
+
+var (
+	loginSQLString = []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+			id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+			name       VARCHAR(512) NOT NULL,
+			ip         VARCHAR(64) NOT NULL,
+			admin_id   BIGINT UNSIGNED NOT NULL DEFAULT 0,
+			success    BOOLEAN NOT NULL,
+			created_at BIGINT NOT NULL COMMENT 'unix milliseconds',
+			PRIMARY KEY (id),
+			INDEX (name)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`, DBName, LoginAttemptTable),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+			lock_key     VARCHAR(600) NOT NULL,
+			failures     INT NOT NULL,
+			last_failure BIGINT NOT NULL COMMENT 'unix milliseconds',
+			locked_until BIGINT NOT NULL COMMENT 'unix milliseconds',
+			PRIMARY KEY (lock_key),
+			INDEX (last_failure)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`, DBName, LockoutTable),
+		fmt.Sprintf(`INSERT INTO %s.%s (name,ip,admin_id,success,created_at) VALUES (?,?,?,?,?)`, DBName, LoginAttemptTable),
+		fmt.Sprintf(`SELECT id,name,ip,admin_id,success,created_at FROM %s.%s ORDER BY id DESC LIMIT ?`, DBName, LoginAttemptTable),
+		fmt.Sprintf(`SELECT id,name,ip,admin_id,success,created_at FROM %s.%s WHERE name = ? ORDER BY id DESC LIMIT ?`, DBName, LoginAttemptTable),
+		fmt.Sprintf(`SELECT lock_key,failures,last_failure,locked_until FROM %s.%s WHERE lock_key = ?`, DBName, LockoutTable),
+		fmt.Sprintf(`INSERT IGNORE INTO %s.%s (lock_key,failures,last_failure,locked_until) VALUES (?,1,?,0)`, DBName, LockoutTable),
+		fmt.Sprintf(`UPDATE %s.%s SET failures = failures + 1, last_failure = ? WHERE lock_key = ? AND failures = ? AND last_failure = ? LIMIT 1`, DBName, LockoutTable),
+		fmt.Sprintf(`UPDATE %s.%s SET locked_until = ? WHERE lock_key = ? AND failures >= ? LIMIT 1`, DBName, LockoutTable),
+		fmt.Sprintf(`UPDATE %s.%s SET failures = failures - 1 WHERE lock_key = ? AND failures > 0 LIMIT 1`, DBName, LockoutTable),
+		fmt.Sprintf(`DELETE FROM %s.%s WHERE last_failure < ?`, DBName, LockoutTable),
+		fmt.Sprintf(`DELETE FROM %s.%s WHERE lock_key = ? LIMIT 1`, DBName, LockoutTable),
+		fmt.Sprintf(`SELECT lock_key,failures,last_failure,locked_until FROM %s.%s ORDER BY lock_key`, DBName, LockoutTable),
+		fmt.Sprintf(`DROP TABLE IF EXISTS %s.%s`, DBName, LoginAttemptTable),
+		fmt.Sprintf(`DROP TABLE IF EXISTS %s.%s`, DBName, LockoutTable),
+	}
+)
+
+// AddLoginAttempt records a login in the history.
+func AddLoginAttempt(db *sql.DB, a *model.LoginAttempt) error {
+	_, err := db.Exec(loginSQLString[mysqlLoginAttemptInsert], a.Name, a.IP, a.AdminID, a.Success, millis(a.At))
+	return errs.FromDB(err, "login attempt")
+}
+
+// LoginAttempts lists the latest attempts of name, of every name if it's empty.
+func LoginAttempts(db *sql.DB, name string, limit int) ([]*model.LoginAttempt, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if name == "" {
+		rows, err = db.Query(loginSQLString[mysqlLoginAttemptList], limit)
+	} else {
+		rows, err = db.Query(loginSQLString[mysqlLoginAttemptListByName], name, limit)
+	}
+	if err != nil {
+		return nil, errs.FromDB(err, "login attempt")
+	}
+	defer rows.Close()
+
+	var attempts []*model.LoginAttempt
+	for rows.Next() {
+		var (
+			a  model.LoginAttempt
+			at int64
+		)
+		if err = rows.Scan(&a.ID, &a.Name, &a.IP, &a.AdminID, &a.Success, &at); err != nil {
+			return nil, errs.FromDB(err, "login attempt")
+		}
+		a.At = fromMillis(at)
+		attempts = append(attempts, &a)
+	}
+
+	return attempts, errs.FromDB(rows.Err(), "login attempt")
+}
+
+// Lockout returns nil if key has no failure.
+func Lockout(db *sql.DB, key string) (*model.Lockout, error) {
+	l, err := scanLockout(db.QueryRow(loginSQLString[mysqlLockoutGet], key))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errs.FromDB(err, "lockout")
+	}
+
+	return l, nil
+}
+
+// ReserveLockout counts a failure of key at, if its lockout is still l, nil
+// if it had none. It reports false if another attempt counted meanwhile, the
+// row is compared and updated in one statement. The lockouts whose last
+// failure is before forget are forgotten.
+func ReserveLockout(db *sql.DB, key string, l *model.Lockout, at, forget time.Time) (bool, error) {
+	if _, err := db.Exec(loginSQLString[mysqlLockoutDeleteBefore], millis(forget)); err != nil {
+		return false, errs.FromDB(err, "lockout")
+	}
+
+	var (
+		result sql.Result
+		err    error
+	)
+	if l == nil || l.LastFailure.Before(forget) {
+		result, err = db.Exec(loginSQLString[mysqlLockoutInsert], key, millis(at))
+	} else {
+		result, err = db.Exec(loginSQLString[mysqlLockoutReserve], millis(at), key, l.Failures, millis(l.LastFailure))
+	}
+	if err != nil {
+		return false, errs.FromDB(err, "lockout")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errs.FromDB(err, "lockout")
+	}
+	return rows == 1, nil
+}
+
+// LockLockout locks key until then if it has lockAfter failures at least.
+func LockLockout(db *sql.DB, key string, lockAfter int, until time.Time) error {
+	_, err := db.Exec(loginSQLString[mysqlLockoutLock], millis(until), key, lockAfter)
+	return errs.FromDB(err, "lockout")
+}
+
+// ReleaseLockout uncounts a failure of key reserved by an attempt succeeded.
+func ReleaseLockout(db *sql.DB, key string) error {
+	_, err := db.Exec(loginSQLString[mysqlLockoutRelease], key)
+	return errs.FromDB(err, "lockout")
+}
+
+// ClearLockout forgets the failures of key.
+func ClearLockout(db *sql.DB, key string) error {
+	_, err := db.Exec(loginSQLString[mysqlLockoutDelete], key)
+	return errs.FromDB(err, "lockout")
+}
+
+// Lockouts lists the lockouts by key.
+func Lockouts(db *sql.DB) ([]*model.Lockout, error) {
+	rows, err := db.Query(loginSQLString[mysqlLockoutList])
+	if err != nil {
+		return nil, errs.FromDB(err, "lockout")
+	}
+	defer rows.Close()
+
+	var lockouts []*model.Lockout
+	for rows.Next() {
+		l, err := scanLockout(rows)
+		if err != nil {
+			return nil, errs.FromDB(err, "lockout")
+		}
+		lockouts = append(lockouts, l)
+	}
+
+	return lockouts, errs.FromDB(rows.Err(), "lockout")
+}
+
+func scanLockout(row interface {
+	Scan(dest ...interface{}) error
+}) (*model.Lockout, error) {
+	var (
+		l                  model.Lockout
+		failure, lockedEnd int64
+	)
+
+	if err := row.Scan(&l.Key, &l.Failures, &failure, &lockedEnd); err != nil {
+		return nil, err
+	}
+	l.LastFailure = fromMillis(failure)
+	if lockedEnd > 0 {
+		l.LockedUntil = fromMillis(lockedEnd)
+	}
+
+	return &l, nil
+}
+
+// lockedUntil is 0 if l is never locked.
+func lockedUntil(l *model.Lockout) int64 {
+	if l.LockedUntil.IsZero() {
+		return 0
+	}
+	return millis(l.LockedUntil)
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
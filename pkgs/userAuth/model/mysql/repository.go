@@ -0,0 +1,284 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/migrate"
+	"github.com/abserari/shower/utils/salt"
+)
+
+// Repository stores the admins in MySQL.
+type Repository struct {
+	db     *sql.DB
+	hasher salt.Hasher
+}
+
+// NewRepository creates a Repository hashing the passwords by h.
+func NewRepository(db *sql.DB, h salt.Hasher) *Repository {
+	return &Repository{
+		db:     db,
+		hasher: h,
+	}
+}
+
+// Migrations returns the migrations of the admins.
+func (r *Repository) Migrations() []migrate.Migration {
+	return Migrations(r.hasher)
+}
+
+// CreateAdmin creates an admin of name and password.
+func (r *Repository) CreateAdmin(name, password *string) error {
+	return CreateAdmin(r.db, r.hasher, name, password)
+}
+
+// CreateServiceAccount creates a service account of name, which has no password.
+func (r *Repository) CreateServiceAccount(name string) error {
+	return CreateServiceAccount(r.db, name)
+}
+
+// Login returns the ID of the admin if the password is right.
+func (r *Repository) Login(name, password *string) (uint32, error) {
+	return Login(r.db, r.hasher, name, password)
+}
+
+// ModifyEmail modifies the email of the admin.
+func (r *Repository) ModifyEmail(id uint32, email *string) error {
+	return ModifyEmail(r.db, id, email)
+}
+
+// ModifyMobile modifies the mobile of the admin.
+func (r *Repository) ModifyMobile(id uint32, mobile *string) error {
+	return ModifyMobile(r.db, id, mobile)
+}
+
+// SetPendingEmail sets the email waiting for the confirmation.
+func (r *Repository) SetPendingEmail(id uint32, email *string) error {
+	return SetPendingEmail(r.db, id, email)
+}
+
+// ConfirmEmail replaces the email by the pending one if it's email.
+func (r *Repository) ConfirmEmail(id uint32, email string) error {
+	return ConfirmEmail(r.db, id, email)
+}
+
+// ModifyPassword revokes the tokens after modified.
+func (r *Repository) ModifyPassword(id uint32, password, newPassword *string) error {
+	if err := ModifyPassword(r.db, r.hasher, id, password, newPassword); err != nil {
+		return err
+	}
+	return RevokeTokens(r.db, id, time.Now())
+}
+
+// ResetPassword revokes the tokens after reset.
+func (r *Repository) ResetPassword(id uint32, password *string) error {
+	if err := ResetPassword(r.db, r.hasher, id, password); err != nil {
+		return err
+	}
+	return RevokeTokens(r.db, id, time.Now())
+}
+
+// SetMustChangePassword flags the admin to change the password or not.
+func (r *Repository) SetMustChangePassword(id uint32, must bool) error {
+	return SetMustChangePassword(r.db, id, must)
+}
+
+// ModifyTenant revokes the tokens after modified.
+func (r *Repository) ModifyTenant(id uint32, tenant string) error {
+	if err := ModifyTenant(r.db, id, tenant); err != nil {
+		return err
+	}
+	return RevokeTokens(r.db, id, time.Now())
+}
+
+// ModifyAdminActive revokes the tokens if deactivated.
+func (r *Repository) ModifyAdminActive(id uint32, active bool) error {
+	if err := ModifyAdminActive(r.db, id, active); err != nil {
+		return err
+	}
+	if active {
+		return nil
+	}
+	return RevokeTokens(r.db, id, time.Now())
+}
+
+// IsActive reports whether the admin is active.
+func (r *Repository) IsActive(id uint32) (bool, error) {
+	return IsActive(r.db, id)
+}
+
+// AdminByName returns the admin of name.
+func (r *Repository) AdminByName(name string) (*model.Admin, error) {
+	return AdminByName(r.db, name)
+}
+
+// AdminByMobile returns the admin of mobile.
+func (r *Repository) AdminByMobile(mobile string) (*model.Admin, error) {
+	return AdminByMobile(r.db, mobile)
+}
+
+// AdminByEmail returns the admin of email.
+func (r *Repository) AdminByEmail(email string) (*model.Admin, error) {
+	return AdminByEmail(r.db, email)
+}
+
+// AdminByID returns the admin of id.
+func (r *Repository) AdminByID(id uint32) (*model.Admin, error) {
+	return AdminByID(r.db, id)
+}
+
+// Admins returns all the admins.
+func (r *Repository) Admins() ([]*model.Admin, error) {
+	return Admins(r.db)
+}
+
+// SearchAdmins returns a page of the admins matched by q and the total.
+func (r *Repository) SearchAdmins(q *model.AdminQuery) ([]*model.Admin, int, error) {
+	return SearchAdmins(r.db, q)
+}
+
+// RevokeToken revokes the token of jti until it expires.
+func (r *Repository) RevokeToken(jti string, expire time.Time) error {
+	return RevokeToken(r.db, jti, expire)
+}
+
+// RevokeTokens revokes the tokens of the admin issued before at.
+func (r *Repository) RevokeTokens(id uint32, at time.Time) error {
+	return RevokeTokens(r.db, id, at)
+}
+
+// IsRevoked reports whether the token is revoked by its jti or the admin.
+func (r *Repository) IsRevoked(jti string, id uint32, issuedAt time.Time) (bool, error) {
+	return IsRevoked(r.db, jti, id, issuedAt)
+}
+
+// AddLoginAttempt records a login attempt.
+func (r *Repository) AddLoginAttempt(a *model.LoginAttempt) error {
+	return AddLoginAttempt(r.db, a)
+}
+
+// LoginAttempts returns the latest login attempts of name, of every name if it's empty.
+func (r *Repository) LoginAttempts(name string, limit int) ([]*model.LoginAttempt, error) {
+	return LoginAttempts(r.db, name, limit)
+}
+
+// Lockout returns the lockout of key.
+func (r *Repository) Lockout(key string) (*model.Lockout, error) {
+	return Lockout(r.db, key)
+}
+
+// ReserveLockout counts a failure of key at, if its lockout is still l.
+func (r *Repository) ReserveLockout(key string, l *model.Lockout, at, forget time.Time) (bool, error) {
+	return ReserveLockout(r.db, key, l, at, forget)
+}
+
+// LockLockout locks key until then if it has lockAfter failures at least.
+func (r *Repository) LockLockout(key string, lockAfter int, until time.Time) error {
+	return LockLockout(r.db, key, lockAfter, until)
+}
+
+// ReleaseLockout uncounts a failure of key reserved by an attempt succeeded.
+func (r *Repository) ReleaseLockout(key string) error {
+	return ReleaseLockout(r.db, key)
+}
+
+// ClearLockout removes the lockout of key.
+func (r *Repository) ClearLockout(key string) error {
+	return ClearLockout(r.db, key)
+}
+
+// Lockouts returns the lockouts.
+func (r *Repository) Lockouts() ([]*model.Lockout, error) {
+	return Lockouts(r.db)
+}
+
+// TOTP returns the TOTP of the admin.
+func (r *Repository) TOTP(id uint32) (*model.TOTP, error) {
+	return TOTP(r.db, id)
+}
+
+// SaveTOTP saves the TOTP of the admin.
+func (r *Repository) SaveTOTP(t *model.TOTP) error {
+	return SaveTOTP(r.db, t)
+}
+
+// UseTOTPStep records step as used, false if the step or a later one is used.
+func (r *Repository) UseTOTPStep(id uint32, step int64) (bool, error) {
+	return UseTOTPStep(r.db, id, step)
+}
+
+// UseRecoveryCode uses the recovery code of hash once.
+func (r *Repository) UseRecoveryCode(id uint32, hash string) (bool, error) {
+	return UseRecoveryCode(r.db, id, hash)
+}
+
+// DeleteTOTP removes the TOTP of the admin.
+func (r *Repository) DeleteTOTP(id uint32) error {
+	return DeleteTOTP(r.db, id)
+}
+
+// CreateSession records a session of a login.
+func (r *Repository) CreateSession(s *model.Session) error {
+	return CreateSession(r.db, s)
+}
+
+// TouchSession records the last use of the session.
+func (r *Repository) TouchSession(id, ip, userAgent string, at time.Time) error {
+	return TouchSession(r.db, id, ip, userAgent, at)
+}
+
+// Session returns the session of id.
+func (r *Repository) Session(id string) (*model.Session, error) {
+	return Session(r.db, id)
+}
+
+// Sessions returns the sessions of the admin neither revoked nor expired.
+func (r *Repository) Sessions(adminID uint32) ([]*model.Session, error) {
+	return Sessions(r.db, adminID)
+}
+
+// RevokeSession revokes a session of the admin.
+func (r *Repository) RevokeSession(adminID uint32, id string) error {
+	return RevokeSession(r.db, adminID, id)
+}
+
+// RevokeSessions revokes the sessions of the admin except one.
+func (r *Repository) RevokeSessions(adminID uint32, except string) error {
+	return RevokeSessions(r.db, adminID, except)
+}
+
+// LinkIdentity links the identity of the issuer to the admin.
+func (r *Repository) LinkIdentity(issuer, subject string, id uint32) error {
+	return LinkIdentity(r.db, issuer, subject, id)
+}
+
+// AdminByIdentity returns the admin linked to the identity.
+func (r *Repository) AdminByIdentity(issuer, subject string) (*model.Admin, error) {
+	return AdminByIdentity(r.db, issuer, subject)
+}
+
+// CreateAPIKey saves an API key.
+func (r *Repository) CreateAPIKey(k *model.APIKey) error {
+	return CreateAPIKey(r.db, k)
+}
+
+// APIKey returns the API key of id.
+func (r *Repository) APIKey(id string) (*model.APIKey, error) {
+	return APIKey(r.db, id)
+}
+
+// APIKeys returns the API keys of the admin not revoked.
+func (r *Repository) APIKeys(adminID uint32) ([]*model.APIKey, error) {
+	return APIKeys(r.db, adminID)
+}
+
+// TouchAPIKey records the last use of the key.
+func (r *Repository) TouchAPIKey(id string, at time.Time) error {
+	return TouchAPIKey(r.db, id, at)
+}
+
+// RevokeAPIKey revokes a key of the admin.
+func (r *Repository) RevokeAPIKey(adminID uint32, id string) error {
+	return RevokeAPIKey(r.db, adminID, id)
+}
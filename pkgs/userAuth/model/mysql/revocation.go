@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	mysqlRevokedTokenCreateTable = iota
+	mysqlRevokedAdminCreateTable
+	mysqlRevokedTokenInsert
+	mysqlRevokedTokenDeleteExpired
+	mysqlRevokedAdminUpsert
+	mysqlRevokedTokenExists
+	mysqlRevokedAdminBefore
+	mysqlRevokedTokenDropTable
+	mysqlRevokedAdminDropTable
+)
+
+const (
+	RevokedTokenTable = "revoked_token"
+	RevokedAdminTable = "revoked_admin"
+)
+
+var (
+	revocationSQLString = []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+			jti       VARCHAR(64) NOT NULL,
+			expire_at BIGINT NOT NULL,
+			PRIMARY KEY (jti),
+			INDEX (expire_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`, DBName, RevokedTokenTable),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+			admin_id       BIGINT UNSIGNED NOT NULL,
+			revoked_before BIGINT NOT NULL COMMENT 'unix milliseconds',
+			PRIMARY KEY (admin_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`, DBName, RevokedAdminTable),
+		fmt.Sprintf(`INSERT IGNORE INTO %s.%s (jti,expire_at) VALUES (?,?)`, DBName, RevokedTokenTable),
+		fmt.Sprintf(`DELETE FROM %s.%s WHERE expire_at < ?`, DBName, RevokedTokenTable),
+		fmt.Sprintf(`INSERT INTO %s.%s (admin_id,revoked_before) VALUES (?,?) ON DUPLICATE KEY UPDATE revoked_before = VALUES(revoked_before)`, DBName, RevokedAdminTable),
+		fmt.Sprintf(`SELECT COUNT(*) FROM %s.%s WHERE jti = ?`, DBName, RevokedTokenTable),
+		fmt.Sprintf(`SELECT revoked_before FROM %s.%s WHERE admin_id = ?`, DBName, RevokedAdminTable),
+		fmt.Sprintf(`DROP TABLE IF EXISTS %s.%s`, DBName, RevokedTokenTable),
+		fmt.Sprintf(`DROP TABLE IF EXISTS %s.%s`, DBName, RevokedAdminTable),
+	}
+)
+
+// RevokeToken revokes the token jti, and forgets the tokens already expired.
+func RevokeToken(db *sql.DB, jti string, expire time.Time) error {
+	if _, err := db.Exec(revocationSQLString[mysqlRevokedTokenDeleteExpired], time.Now().Unix()); err != nil {
+		return errs.FromDB(err, "token")
+	}
+
+	_, err := db.Exec(revocationSQLString[mysqlRevokedTokenInsert], jti, expire.Unix())
+	return errs.FromDB(err, "token")
+}
+
+// RevokeTokens revokes the tokens of the admin issued until at, in
+// milliseconds, and the sessions created until then.
+func RevokeTokens(db *sql.DB, id uint32, at time.Time) error {
+	_, err := db.Exec(revocationSQLString[mysqlRevokedAdminUpsert], id, millis(at))
+	if err != nil {
+		return errs.FromDB(err, "token")
+	}
+
+	return revokeSessionsBefore(db, id, at)
+}
+
+// IsRevoked reports whether the token jti of the admin issued at is revoked.
+func IsRevoked(db *sql.DB, jti string, id uint32, issuedAt time.Time) (bool, error) {
+	var before int64
+
+	err := db.QueryRow(revocationSQLString[mysqlRevokedAdminBefore], id).Scan(&before)
+	if err != nil && err != sql.ErrNoRows {
+		return false, errs.FromDB(err, "token")
+	}
+	if millis(issuedAt) <= before {
+		return true, nil
+	}
+
+	var count int
+	if err = db.QueryRow(revocationSQLString[mysqlRevokedTokenExists], jti).Scan(&count); err != nil {
+		return false, errs.FromDB(err, "token")
+	}
+
+	return count > 0, nil
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,148 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	mysqlSessionCreateTable = iota
+	mysqlSessionInsert
+	mysqlSessionDeleteExpired
+	mysqlSessionTouch
+	mysqlSessionGet
+	mysqlSessionList
+	mysqlSessionRevoke
+	mysqlSessionRevokeAll
+	mysqlSessionRevokeBefore
+	mysqlSessionDropTable
+)
+
+const SessionTable = "session"
+
+var (
+	sessionSQLString = []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+			id         VARCHAR(64) NOT NULL,
+			admin_id   BIGINT UNSIGNED NOT NULL,
+			device     VARCHAR(128) NOT NULL,
+			ip         VARCHAR(64) NOT NULL,
+			user_agent VARCHAR(512) NOT NULL,
+			created_at BIGINT NOT NULL COMMENT 'unix milliseconds',
+			last_seen  BIGINT NOT NULL COMMENT 'unix milliseconds',
+			expires_at BIGINT NOT NULL COMMENT 'unix milliseconds',
+			revoked    BOOLEAN NOT NULL DEFAULT FALSE,
+			PRIMARY KEY (id),
+			INDEX (admin_id),
+			INDEX (expires_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`, DBName, SessionTable),
+		fmt.Sprintf(`INSERT INTO %s.%s (id,admin_id,device,ip,user_agent,created_at,last_seen,expires_at,revoked) VALUES (?,?,?,?,?,?,?,?,?)`, DBName, SessionTable),
+		fmt.Sprintf(`DELETE FROM %s.%s WHERE expires_at < ?`, DBName, SessionTable),
+		fmt.Sprintf(`UPDATE %s.%s SET ip = ?, user_agent = ?, last_seen = ? WHERE id = ? LIMIT 1`, DBName, SessionTable),
+		fmt.Sprintf(`SELECT id,admin_id,device,ip,user_agent,created_at,last_seen,expires_at,revoked FROM %s.%s WHERE id = ?`, DBName, SessionTable),
+		fmt.Sprintf(`SELECT id,admin_id,device,ip,user_agent,created_at,last_seen,expires_at,revoked FROM %s.%s WHERE admin_id = ? AND revoked = FALSE AND expires_at >= ? ORDER BY last_seen DESC`, DBName, SessionTable),
+		fmt.Sprintf(`UPDATE %s.%s SET revoked = TRUE WHERE admin_id = ? AND id = ? AND revoked = FALSE LIMIT 1`, DBName, SessionTable),
+		fmt.Sprintf(`UPDATE %s.%s SET revoked = TRUE WHERE admin_id = ? AND id <> ?`, DBName, SessionTable),
+		fmt.Sprintf(`UPDATE %s.%s SET revoked = TRUE WHERE admin_id = ? AND created_at <= ?`, DBName, SessionTable),
+		fmt.Sprintf(`DROP TABLE IF EXISTS %s.%s`, DBName, SessionTable),
+	}
+)
+
+// CreateSession records a session, and forgets the sessions expired.
+func CreateSession(db *sql.DB, s *model.Session) error {
+	if _, err := db.Exec(sessionSQLString[mysqlSessionDeleteExpired], millis(time.Now())); err != nil {
+		return errs.FromDB(err, "session")
+	}
+
+	_, err := db.Exec(sessionSQLString[mysqlSessionInsert], s.ID, s.AdminID, s.Device, s.IP, s.UserAgent,
+		millis(s.CreatedAt), millis(s.LastSeen), millis(s.ExpiresAt), s.Revoked)
+	return errs.FromDB(err, "session")
+}
+
+// TouchSession updates the IP, user agent and last seen of the session.
+func TouchSession(db *sql.DB, id, ip, userAgent string, at time.Time) error {
+	_, err := db.Exec(sessionSQLString[mysqlSessionTouch], ip, userAgent, millis(at), id)
+	return errs.FromDB(err, "session")
+}
+
+// Session returns the session of id.
+func Session(db *sql.DB, id string) (*model.Session, error) {
+	s, err := scanSession(db.QueryRow(sessionSQLString[mysqlSessionGet], id))
+	if err == sql.ErrNoRows {
+		return nil, model.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, errs.FromDB(err, "session")
+	}
+
+	return s, nil
+}
+
+// Sessions lists the sessions of the admin neither revoked nor expired, the latest seen first.
+func Sessions(db *sql.DB, adminID uint32) ([]*model.Session, error) {
+	rows, err := db.Query(sessionSQLString[mysqlSessionList], adminID, millis(time.Now()))
+	if err != nil {
+		return nil, errs.FromDB(err, "session")
+	}
+	defer rows.Close()
+
+	sessions := []*model.Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, errs.FromDB(err, "session")
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, errs.FromDB(rows.Err(), "session")
+}
+
+// RevokeSession revokes the session of the admin.
+func RevokeSession(db *sql.DB, adminID uint32, id string) error {
+	result, err := db.Exec(sessionSQLString[mysqlSessionRevoke], adminID, id)
+	if err != nil {
+		return errs.FromDB(err, "session")
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return model.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeSessions revokes every session of the admin except the session except.
+func RevokeSessions(db *sql.DB, adminID uint32, except string) error {
+	_, err := db.Exec(sessionSQLString[mysqlSessionRevokeAll], adminID, except)
+	return errs.FromDB(err, "session")
+}
+
+// revokeSessionsBefore revokes the sessions of the admin created until at.
+func revokeSessionsBefore(db *sql.DB, adminID uint32, at time.Time) error {
+	_, err := db.Exec(sessionSQLString[mysqlSessionRevokeBefore], adminID, millis(at))
+	return errs.FromDB(err, "session")
+}
+
+func scanSession(row interface {
+	Scan(dest ...interface{}) error
+}) (*model.Session, error) {
+	var (
+		s                              model.Session
+		createdAt, lastSeen, expiresAt int64
+	)
+
+	err := row.Scan(&s.ID, &s.AdminID, &s.Device, &s.IP, &s.UserAgent, &createdAt, &lastSeen, &expiresAt, &s.Revoked)
+	if err != nil {
+		return nil, err
+	}
+	s.CreatedAt = fromMillis(createdAt)
+	s.LastSeen = fromMillis(lastSeen)
+	s.ExpiresAt = fromMillis(expiresAt)
+
+	return &s, nil
+}
@@ -0,0 +1,117 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/abserari/shower/pkgs/userAuth/model"
+	"github.com/abserari/shower/utils/errs"
+)
+
+const (
+	mysqlTOTPCreateTable = iota
+	mysqlTOTPGet
+	mysqlTOTPUpsert
+	mysqlTOTPUseStep
+	mysqlTOTPModifyRecoveryCodes
+	mysqlTOTPDelete
+	mysqlTOTPDropTable
+)
+
+const TOTPTable = "totp"
+
+var (
+	totpSQLString = []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+			admin_id       BIGINT UNSIGNED NOT NULL,
+			secret         VARCHAR(64) NOT NULL,
+			confirmed      BOOLEAN NOT NULL DEFAULT FALSE,
+			last_step      BIGINT NOT NULL DEFAULT 0,
+			recovery_codes TEXT NOT NULL COMMENT 'SHA-256 of the codes separated by comma',
+			PRIMARY KEY (admin_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`, DBName, TOTPTable),
+		fmt.Sprintf(`SELECT admin_id,secret,confirmed,last_step,recovery_codes FROM %s.%s WHERE admin_id = ?`, DBName, TOTPTable),
+		fmt.Sprintf(`INSERT INTO %s.%s (admin_id,secret,confirmed,last_step,recovery_codes) VALUES (?,?,?,?,?) ON DUPLICATE KEY UPDATE secret = VALUES(secret), confirmed = VALUES(confirmed), last_step = VALUES(last_step), recovery_codes = VALUES(recovery_codes)`, DBName, TOTPTable),
+		fmt.Sprintf(`UPDATE %s.%s SET last_step = ? WHERE admin_id = ? AND last_step < ? LIMIT 1`, DBName, TOTPTable),
+		fmt.Sprintf(`UPDATE %s.%s SET recovery_codes = ? WHERE admin_id = ? AND recovery_codes = ? LIMIT 1`, DBName, TOTPTable),
+		fmt.Sprintf(`DELETE FROM %s.%s WHERE admin_id = ? LIMIT 1`, DBName, TOTPTable),
+		fmt.Sprintf(`DROP TABLE IF EXISTS %s.%s`, DBName, TOTPTable),
+	}
+)
+
+// TOTP returns nil if the admin has not enrolled.
+func TOTP(db *sql.DB, id uint32) (*model.TOTP, error) {
+	var (
+		t     model.TOTP
+		codes string
+	)
+
+	err := db.QueryRow(totpSQLString[mysqlTOTPGet], id).Scan(&t.AdminID, &t.Secret, &t.Confirmed, &t.LastStep, &codes)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errs.FromDB(err, "totp")
+	}
+	t.RecoveryCodes = splitCodes(codes)
+
+	return &t, nil
+}
+
+// SaveTOTP creates or replaces the TOTP of the admin.
+func SaveTOTP(db *sql.DB, t *model.TOTP) error {
+	_, err := db.Exec(totpSQLString[mysqlTOTPUpsert], t.AdminID, t.Secret, t.Confirmed, t.LastStep, strings.Join(t.RecoveryCodes, ","))
+	return errs.FromDB(err, "totp")
+}
+
+// UseTOTPStep records step as used, it reports false if the step or a later one is used.
+func UseTOTPStep(db *sql.DB, id uint32, step int64) (bool, error) {
+	result, err := db.Exec(totpSQLString[mysqlTOTPUseStep], step, id, step)
+	if err != nil {
+		return false, errs.FromDB(err, "totp")
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// UseRecoveryCode removes the recovery code by hash, it reports false if there's no such code.
+// The codes are only updated if they're not changed since read.
+func UseRecoveryCode(db *sql.DB, id uint32, hash string) (bool, error) {
+	t, err := TOTP(db, id)
+	if err != nil || t == nil {
+		return false, err
+	}
+
+	left := make([]string, 0, len(t.RecoveryCodes))
+	for _, code := range t.RecoveryCodes {
+		if code != hash {
+			left = append(left, code)
+		}
+	}
+	if len(left) == len(t.RecoveryCodes) {
+		return false, nil
+	}
+
+	result, err := db.Exec(totpSQLString[mysqlTOTPModifyRecoveryCodes], strings.Join(left, ","), id, strings.Join(t.RecoveryCodes, ","))
+	if err != nil {
+		return false, errs.FromDB(err, "totp")
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// DeleteTOTP disables the two-factor authentication of the admin.
+func DeleteTOTP(db *sql.DB, id uint32) error {
+	_, err := db.Exec(totpSQLString[mysqlTOTPDelete], id)
+	return errs.FromDB(err, "totp")
+}
+
+func splitCodes(codes string) []string {
+	if codes == "" {
+		return nil
+	}
+	return strings.Split(codes, ",")
+}
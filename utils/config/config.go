@@ -0,0 +1,525 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	errNoModules       = errors.New("config: no module is enabled")
+	errModuleNameEmpty = errors.New("config: module name is empty")
+	errModuleDuplicate = func(name string) error {
+		return fmt.Errorf("config: module %s is declared twice", name)
+	}
+	errRequired = func(key string) error {
+		return fmt.Errorf("config: %s is required", key)
+	}
+	errNotPositive = func(key string) error {
+		return fmt.Errorf("config: %s should be positive", key)
+	}
+	errAlgorithm = func(key, alg string) error {
+		return fmt.Errorf("config: %s %s is not supported", key, alg)
+	}
+)
+
+// The algorithms signing the token.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// The algorithms hashing the passwords.
+const (
+	Bcrypt   = "bcrypt"
+	Argon2id = "argon2id"
+)
+
+// The drivers of the mail.
+const (
+	SMTPMail   = "smtp"
+	FileMail   = "file"
+	MemoryMail = "memory"
+)
+
+// MemoryDriver keeps everything in memory instead of a database, used in
+// development and tests.
+const MemoryDriver = "memory"
+
+// Config describes one deployment of the server.
+type Config struct {
+	Server      Server     `yaml:"server"`
+	Database    Database   `yaml:"database"`
+	FileServer  FileServer `yaml:"fileserver"`
+	JWT         JWT        `yaml:"jwt"`
+	Login       Login      `yaml:"login"`
+	Password    Password   `yaml:"password"`
+	Mail        Mail       `yaml:"mail"`
+	OIDC        OIDC       `yaml:"oidc"`
+	APIKey      APIKey     `yaml:"api_key"`
+	Bootstrap   Bootstrap  `yaml:"bootstrap"`
+	SMS         SMS        `yaml:"sms"`
+	Middlewares []string   `yaml:"middlewares"`
+	Modules     []Module   `yaml:"modules"`
+}
+
+// Server is the API server config.
+type Server struct {
+	Address string `yaml:"address"`
+	// Docs is where the API docs are served, empty disables them.
+	Docs string `yaml:"docs"`
+}
+
+// Database is the connection used by every module.
+type Database struct {
+	Driver string `yaml:"driver"`
+	DSN    string `yaml:"dsn"`
+}
+
+// FileServer serves the uploaded files.
+type FileServer struct {
+	Address string `yaml:"address"`
+	Path    string `yaml:"path"`
+}
+
+// JWT signs the token of userAuth.
+type JWT struct {
+	Realm string `yaml:"realm"`
+	// Algorithm is HS256 signed by Key, or RS256 and ES256 signed by PrivateKeyFile.
+	Algorithm string `yaml:"algorithm"`
+	Key       string `yaml:"key"`
+	// KeyID is the kid of the signing key, the thumbprint of the public key if empty.
+	KeyID          string `yaml:"kid"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+	// VerifyKeys are still accepted besides the signing key, keep the former
+	// key here until its tokens expire when rotating.
+	VerifyKeys []JWTKey      `yaml:"verify_keys"`
+	Timeout    time.Duration `yaml:"timeout"`
+	MaxRefresh time.Duration `yaml:"max_refresh"`
+}
+
+// JWTKey is a public key verifying the token.
+type JWTKey struct {
+	KeyID         string `yaml:"kid"`
+	Algorithm     string `yaml:"algorithm"`
+	PublicKeyFile string `yaml:"public_key_file"`
+}
+
+// Login throttles the failed logins of userAuth by the name and the IP.
+type Login struct {
+	// DelayAfter failures of a name, or IPDelayAfter of an IP, the next login
+	// waits Delay, which doubles by every failure up to MaxDelay.
+	DelayAfter   int           `yaml:"delay_after"`
+	IPDelayAfter int           `yaml:"ip_delay_after"`
+	Delay        time.Duration `yaml:"delay"`
+	MaxDelay     time.Duration `yaml:"max_delay"`
+	// LockAfter failures of a name, or IPLockAfter of an IP, lock it for
+	// LockDuration. The failures are forgotten LockDuration after the last one.
+	LockAfter    int           `yaml:"lock_after"`
+	IPLockAfter  int           `yaml:"ip_lock_after"`
+	LockDuration time.Duration `yaml:"lock_duration"`
+	// TOTPLockAfter failed two-factor codes of an admin, from any IP, lock
+	// its second factor for LockDuration.
+	TOTPLockAfter int `yaml:"totp_lock_after"`
+}
+
+// Password hashes and checks the passwords of userAuth.
+type Password struct {
+	// Algorithm is bcrypt or argon2id, the hashes of the other algorithm or
+	// parameters are replaced when the admins login.
+	Algorithm  string `yaml:"algorithm"`
+	BcryptCost int    `yaml:"bcrypt_cost"`
+	// Argon2Time is the iterations, Argon2Memory is in KiB.
+	Argon2Time    uint32 `yaml:"argon2_time"`
+	Argon2Memory  uint32 `yaml:"argon2_memory"`
+	Argon2Threads uint8  `yaml:"argon2_threads"`
+	// MinLength and Classes of lower, upper, digit and symbol a new password should have.
+	MinLength int `yaml:"min_length"`
+	Classes   int `yaml:"classes"`
+	// BreachedFile lists the passwords not allowed one per line, besides the most common ones.
+	BreachedFile string `yaml:"breached_file"`
+}
+
+// Mail sends the messages of userAuth, like confirming the email.
+type Mail struct {
+	// Driver is smtp, or file writing the messages under Dir, or memory keeping
+	// them, the latter two are for local use.
+	Driver   string `yaml:"driver"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+	Dir      string `yaml:"dir"`
+	// ConfirmURL is the link confirming the email, the token is added as the query token.
+	ConfirmURL string `yaml:"confirm_url"`
+	// Expire is how long the link is valid.
+	Expire time.Duration `yaml:"expire"`
+}
+
+// OIDC is the identity provider the admins login by, the single sign-on is
+// disabled if Issuer is empty.
+type OIDC struct {
+	Issuer       string `yaml:"issuer"`
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// RedirectURL is the callback of userAuth, like http://localhost:8000/api/v1/userAuth/oidc/callback.
+	RedirectURL string   `yaml:"redirect_url"`
+	Scopes      []string `yaml:"scopes"`
+	// MatchEmail logins the admin of the same email if the provider verified it.
+	MatchEmail bool `yaml:"match_email"`
+	// CreateAdmins creates the admin of an identity not matched at the first login.
+	CreateAdmins bool `yaml:"create_admins"`
+}
+
+// APIKey authenticates the service accounts of userAuth instead of the token.
+type APIKey struct {
+	// Header carries the key in the requests.
+	Header string `yaml:"header"`
+	// MaxExpire is the longest a key could be valid.
+	MaxExpire time.Duration `yaml:"max_expire"`
+}
+
+// Bootstrap is the admin of userAuth seeded at the first start, when there
+// is no admin yet.
+type Bootstrap struct {
+	// Name is required by userAuth.
+	Name string `yaml:"name"`
+	// Password is generated and printed once if it's empty, either the admin
+	// must change at the first login.
+	Password string `yaml:"password"`
+}
+
+// SMS is the short message service used by smservice.
+type SMS struct {
+	Host           string `yaml:"host"`
+	Appcode        string `yaml:"appcode"`
+	Digits         int    `yaml:"digits"`
+	ResendInterval int    `yaml:"resend_interval"`
+	// Expire is the seconds a code could be checked.
+	Expire int `yaml:"expire"`
+}
+
+// Module enables one package under pkgs.
+type Module struct {
+	Name     string `yaml:"name"`
+	Group    string `yaml:"group"`
+	Database string `yaml:"database"`
+	Table    string `yaml:"table"`
+	// Public modules are registered before any middleware is used.
+	Public  bool              `yaml:"public"`
+	Options map[string]string `yaml:"options"`
+}
+
+// Option returns the module option or def if it's not set.
+func (m *Module) Option(key, def string) string {
+	if v, ok := m.Options[key]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// Default returns the config used when nothing is set.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Address: ":8000",
+			Docs:    "/docs",
+		},
+		Database: Database{
+			Driver: "mysql",
+		},
+		JWT: JWT{
+			Realm:      "shower",
+			Algorithm:  HS256,
+			Timeout:    time.Hour,
+			MaxRefresh: 24 * time.Hour,
+		},
+		Login: Login{
+			DelayAfter:    3,
+			IPDelayAfter:  20,
+			Delay:         time.Second,
+			MaxDelay:      30 * time.Second,
+			LockAfter:     10,
+			IPLockAfter:   50,
+			LockDuration:  15 * time.Minute,
+			TOTPLockAfter: 20,
+		},
+		Password: Password{
+			Algorithm:     Bcrypt,
+			BcryptCost:    10,
+			Argon2Time:    1,
+			Argon2Memory:  64 * 1024,
+			Argon2Threads: 2,
+			MinLength:     8,
+			Classes:       2,
+		},
+		Mail: Mail{
+			Driver:     FileMail,
+			Port:       587,
+			From:       "shower@localhost",
+			Dir:        "mail",
+			ConfirmURL: "http://localhost:8000/api/v1/userAuth/email/confirm",
+			Expire:     24 * time.Hour,
+		},
+		OIDC: OIDC{
+			Scopes:     []string{"openid", "email", "profile"},
+			MatchEmail: true,
+		},
+		APIKey: APIKey{
+			Header:    "X-API-Key",
+			MaxExpire: 365 * 24 * time.Hour,
+		},
+		Bootstrap: Bootstrap{
+			Name: "Admin",
+		},
+		SMS: SMS{
+			Digits:         6,
+			ResendInterval: 60,
+			Expire:         300,
+		},
+	}
+}
+
+// Load read the YAML config file from path and validate it.
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Parse decode the YAML config over the defaults.
+func Parse(data []byte) (*Config, error) {
+	c := Default()
+
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Validate checks the config is ready to serve.
+func (c *Config) Validate() error {
+	if len(c.Modules) == 0 {
+		return errNoModules
+	}
+
+	seen := make(map[string]bool, len(c.Modules))
+	for _, m := range c.Modules {
+		if m.Name == "" {
+			return errModuleNameEmpty
+		}
+		if seen[m.Name] {
+			return errModuleDuplicate(m.Name)
+		}
+		seen[m.Name] = true
+	}
+
+	if c.Server.Address == "" {
+		return errRequired("server.address")
+	}
+
+	if c.Database.Driver != MemoryDriver && c.Database.DSN == "" {
+		return errRequired("database.dsn")
+	}
+
+	if seen["userAuth"] {
+		switch c.JWT.Algorithm {
+		case HS256:
+			if c.JWT.Key == "" {
+				return errRequired("jwt.key")
+			}
+		case RS256, ES256:
+			if c.JWT.PrivateKeyFile == "" {
+				return errRequired("jwt.private_key_file")
+			}
+		default:
+			return errAlgorithm("jwt.algorithm", c.JWT.Algorithm)
+		}
+		for _, k := range c.JWT.VerifyKeys {
+			if k.Algorithm != RS256 && k.Algorithm != ES256 {
+				return errAlgorithm("jwt.verify_keys.algorithm", k.Algorithm)
+			}
+			if k.PublicKeyFile == "" {
+				return errRequired("jwt.verify_keys.public_key_file")
+			}
+		}
+		if c.JWT.Timeout <= 0 {
+			return errNotPositive("jwt.timeout")
+		}
+		if c.JWT.MaxRefresh < 0 {
+			return errNotPositive("jwt.max_refresh")
+		}
+		if err := c.Login.validate(); err != nil {
+			return err
+		}
+		if err := c.Password.validate(); err != nil {
+			return err
+		}
+		if err := c.Mail.validate(); err != nil {
+			return err
+		}
+		if err := c.OIDC.validate(); err != nil {
+			return err
+		}
+		if c.APIKey.Header == "" {
+			return errRequired("api_key.header")
+		}
+		if c.APIKey.MaxExpire <= 0 {
+			return errNotPositive("api_key.max_expire")
+		}
+		if c.Bootstrap.Name == "" {
+			return errRequired("bootstrap.name")
+		}
+	}
+
+	if seen["upload"] && c.FileServer.Address == "" {
+		return errRequired("fileserver.address")
+	}
+
+	if seen["smservice"] {
+		if c.SMS.Host == "" {
+			return errRequired("sms.host")
+		}
+		if c.SMS.Appcode == "" {
+			return errRequired("sms.appcode")
+		}
+		if c.SMS.Digits <= 0 {
+			return errNotPositive("sms.digits")
+		}
+		if c.SMS.Expire <= 0 {
+			return errNotPositive("sms.expire")
+		}
+	}
+
+	return nil
+}
+
+func (l *Login) validate() error {
+	switch {
+	case l.DelayAfter <= 0:
+		return errNotPositive("login.delay_after")
+	case l.IPDelayAfter <= 0:
+		return errNotPositive("login.ip_delay_after")
+	case l.Delay <= 0:
+		return errNotPositive("login.delay")
+	case l.MaxDelay < l.Delay:
+		return fmt.Errorf("config: login.max_delay should be at least login.delay")
+	case l.LockAfter <= 0:
+		return errNotPositive("login.lock_after")
+	case l.IPLockAfter <= 0:
+		return errNotPositive("login.ip_lock_after")
+	case l.LockDuration <= 0:
+		return errNotPositive("login.lock_duration")
+	case l.TOTPLockAfter <= 0:
+		return errNotPositive("login.totp_lock_after")
+	}
+	return nil
+}
+
+func (p *Password) validate() error {
+	switch p.Algorithm {
+	case Bcrypt:
+		// the range of golang.org/x/crypto/bcrypt.
+		if p.BcryptCost < 4 || p.BcryptCost > 31 {
+			return fmt.Errorf("config: password.bcrypt_cost should be between 4 and 31")
+		}
+	case Argon2id:
+		switch {
+		case p.Argon2Time == 0:
+			return errNotPositive("password.argon2_time")
+		case p.Argon2Memory == 0:
+			return errNotPositive("password.argon2_memory")
+		case p.Argon2Memory > 4<<20:
+			// the bound of the hashes decoded by utils/salt.
+			return fmt.Errorf("config: password.argon2_memory should be at most 4194304")
+		case p.Argon2Threads == 0:
+			return errNotPositive("password.argon2_threads")
+		}
+	default:
+		return errAlgorithm("password.algorithm", p.Algorithm)
+	}
+
+	if p.MinLength <= 0 {
+		return errNotPositive("password.min_length")
+	}
+	if p.Classes < 1 || p.Classes > 4 {
+		return fmt.Errorf("config: password.classes should be between 1 and 4")
+	}
+	return nil
+}
+
+func (m *Mail) validate() error {
+	switch m.Driver {
+	case SMTPMail:
+		if m.Host == "" {
+			return errRequired("mail.host")
+		}
+		if m.Port <= 0 {
+			return errNotPositive("mail.port")
+		}
+	case FileMail:
+		if m.Dir == "" {
+			return errRequired("mail.dir")
+		}
+	case MemoryMail:
+	default:
+		return fmt.Errorf("config: mail.driver %s is not supported", m.Driver)
+	}
+
+	if m.From == "" {
+		return errRequired("mail.from")
+	}
+	if m.ConfirmURL == "" {
+		return errRequired("mail.confirm_url")
+	}
+	if m.Expire <= 0 {
+		return errNotPositive("mail.expire")
+	}
+	return nil
+}
+
+func (o *OIDC) validate() error {
+	if o.Issuer == "" {
+		return nil
+	}
+
+	switch {
+	case o.ClientID == "":
+		return errRequired("oidc.client_id")
+	case o.RedirectURL == "":
+		return errRequired("oidc.redirect_url")
+	}
+	for _, scope := range o.Scopes {
+		if scope == "openid" {
+			return nil
+		}
+	}
+	return fmt.Errorf("config: oidc.scopes should include openid")
+}
+
+// Module returns the module config by name.
+func (c *Config) Module(name string) (*Module, bool) {
+	for i := range c.Modules {
+		if c.Modules[i].Name == name {
+			return &c.Modules[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "******"
+
+// setting is one value could be overridden by environment variable or flag.
+type setting struct {
+	key    string
+	env    string
+	usage  string
+	secret bool
+	get    func(c *Config) string
+	set    func(c *Config, v string) error
+}
+
+func stringSetting(key, env, usage string, secret bool, field func(c *Config) *string) setting {
+	return setting{
+		key:    key,
+		env:    env,
+		usage:  usage,
+		secret: secret,
+		get:    func(c *Config) string { return *field(c) },
+		set: func(c *Config, v string) error {
+			*field(c) = v
+			return nil
+		},
+	}
+}
+
+func intSetting(key, env, usage string, field func(c *Config) *int) setting {
+	return setting{
+		key:   key,
+		env:   env,
+		usage: usage,
+		get:   func(c *Config) string { return strconv.Itoa(*field(c)) },
+		set: func(c *Config, v string) error {
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return err
+			}
+			*field(c) = i
+			return nil
+		},
+	}
+}
+
+func durationSetting(key, env, usage string, field func(c *Config) *time.Duration) setting {
+	return setting{
+		key:   key,
+		env:   env,
+		usage: usage,
+		get:   func(c *Config) string { return field(c).String() },
+		set: func(c *Config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return err
+			}
+			*field(c) = d
+			return nil
+		},
+	}
+}
+
+var settings = []setting{
+	stringSetting("server.address", "COMET_SERVER_ADDRESS", "address of the API server", false,
+		func(c *Config) *string { return &c.Server.Address }),
+	stringSetting("server.docs", "COMET_SERVER_DOCS", "path of the API docs, empty disables them", false,
+		func(c *Config) *string { return &c.Server.Docs }),
+	stringSetting("database.driver", "COMET_DATABASE_DRIVER", "database driver", false,
+		func(c *Config) *string { return &c.Database.Driver }),
+	stringSetting("database.dsn", "COMET_DATABASE_URL", "database DSN, like root:123456@tcp(127.0.0.1:3306)/test", true,
+		func(c *Config) *string { return &c.Database.DSN }),
+	stringSetting("fileserver.address", "COMET_FILESERVER_ADDRESS", "address of the file server", false,
+		func(c *Config) *string { return &c.FileServer.Address }),
+	stringSetting("fileserver.path", "COMET_FILESERVER_PATH", "directory served by the file server", false,
+		func(c *Config) *string { return &c.FileServer.Path }),
+	stringSetting("jwt.realm", "COMET_JWT_REALM", "realm of the token", false,
+		func(c *Config) *string { return &c.JWT.Realm }),
+	stringSetting("jwt.algorithm", "COMET_JWT_ALGORITHM", "HS256, RS256 or ES256", false,
+		func(c *Config) *string { return &c.JWT.Algorithm }),
+	stringSetting("jwt.key", "COMET_JWT_KEY", "key to sign the token of HS256", true,
+		func(c *Config) *string { return &c.JWT.Key }),
+	stringSetting("jwt.kid", "COMET_JWT_KID", "kid of the signing key", false,
+		func(c *Config) *string { return &c.JWT.KeyID }),
+	stringSetting("jwt.private_key_file", "COMET_JWT_PRIVATE_KEY_FILE", "PEM private key to sign the token of RS256 or ES256", false,
+		func(c *Config) *string { return &c.JWT.PrivateKeyFile }),
+	durationSetting("jwt.timeout", "COMET_JWT_TIMEOUT", "how long a token is valid",
+		func(c *Config) *time.Duration { return &c.JWT.Timeout }),
+	durationSetting("jwt.max_refresh", "COMET_JWT_MAX_REFRESH", "how long a token could be refreshed",
+		func(c *Config) *time.Duration { return &c.JWT.MaxRefresh }),
+	intSetting("login.lock_after", "COMET_LOGIN_LOCK_AFTER", "failed logins locking a name",
+		func(c *Config) *int { return &c.Login.LockAfter }),
+	intSetting("login.ip_lock_after", "COMET_LOGIN_IP_LOCK_AFTER", "failed logins locking an IP",
+		func(c *Config) *int { return &c.Login.IPLockAfter }),
+	durationSetting("login.lock_duration", "COMET_LOGIN_LOCK_DURATION", "how long a name or an IP is locked",
+		func(c *Config) *time.Duration { return &c.Login.LockDuration }),
+	intSetting("login.totp_lock_after", "COMET_LOGIN_TOTP_LOCK_AFTER", "failed two-factor codes locking an admin",
+		func(c *Config) *int { return &c.Login.TOTPLockAfter }),
+	stringSetting("password.algorithm", "COMET_PASSWORD_ALGORITHM", "bcrypt or argon2id hashing the passwords", false,
+		func(c *Config) *string { return &c.Password.Algorithm }),
+	intSetting("password.bcrypt_cost", "COMET_PASSWORD_BCRYPT_COST", "cost of bcrypt",
+		func(c *Config) *int { return &c.Password.BcryptCost }),
+	intSetting("password.min_length", "COMET_PASSWORD_MIN_LENGTH", "least characters of a new password",
+		func(c *Config) *int { return &c.Password.MinLength }),
+	stringSetting("password.breached_file", "COMET_PASSWORD_BREACHED_FILE", "passwords not allowed, one per line", false,
+		func(c *Config) *string { return &c.Password.BreachedFile }),
+	stringSetting("mail.driver", "COMET_MAIL_DRIVER", "smtp, or file and memory for local use", false,
+		func(c *Config) *string { return &c.Mail.Driver }),
+	stringSetting("mail.host", "COMET_MAIL_HOST", "SMTP server", false,
+		func(c *Config) *string { return &c.Mail.Host }),
+	intSetting("mail.port", "COMET_MAIL_PORT", "port of the SMTP server",
+		func(c *Config) *int { return &c.Mail.Port }),
+	stringSetting("mail.username", "COMET_MAIL_USERNAME", "username of the SMTP server", false,
+		func(c *Config) *string { return &c.Mail.Username }),
+	stringSetting("mail.password", "COMET_MAIL_PASSWORD", "password of the SMTP server", true,
+		func(c *Config) *string { return &c.Mail.Password }),
+	stringSetting("mail.from", "COMET_MAIL_FROM", "sender of the mails", false,
+		func(c *Config) *string { return &c.Mail.From }),
+	stringSetting("mail.confirm_url", "COMET_MAIL_CONFIRM_URL", "link confirming the email", false,
+		func(c *Config) *string { return &c.Mail.ConfirmURL }),
+	stringSetting("oidc.issuer", "COMET_OIDC_ISSUER", "OpenID provider the admins login by, empty disables it", false,
+		func(c *Config) *string { return &c.OIDC.Issuer }),
+	stringSetting("oidc.client_id", "COMET_OIDC_CLIENT_ID", "client ID at the OpenID provider", false,
+		func(c *Config) *string { return &c.OIDC.ClientID }),
+	stringSetting("oidc.client_secret", "COMET_OIDC_CLIENT_SECRET", "client secret at the OpenID provider", true,
+		func(c *Config) *string { return &c.OIDC.ClientSecret }),
+	stringSetting("oidc.redirect_url", "COMET_OIDC_REDIRECT_URL", "callback of the OpenID login", false,
+		func(c *Config) *string { return &c.OIDC.RedirectURL }),
+	stringSetting("api_key.header", "COMET_API_KEY_HEADER", "header carrying the API key of a service account", false,
+		func(c *Config) *string { return &c.APIKey.Header }),
+	durationSetting("api_key.max_expire", "COMET_API_KEY_MAX_EXPIRE", "longest an API key could be valid",
+		func(c *Config) *time.Duration { return &c.APIKey.MaxExpire }),
+	stringSetting("bootstrap.name", "COMET_BOOTSTRAP_NAME", "admin seeded at the first start", false,
+		func(c *Config) *string { return &c.Bootstrap.Name }),
+	stringSetting("bootstrap.password", "COMET_BOOTSTRAP_PASSWORD", "password of the admin seeded, generated and printed if empty", true,
+		func(c *Config) *string { return &c.Bootstrap.Password }),
+	stringSetting("sms.host", "COMET_SMS_HOST", "short message service API", false,
+		func(c *Config) *string { return &c.SMS.Host }),
+	stringSetting("sms.appcode", "COMET_SMS_APPCODE", "appcode of the short message service", true,
+		func(c *Config) *string { return &c.SMS.Appcode }),
+	intSetting("sms.digits", "COMET_SMS_DIGITS", "digits of the verification code",
+		func(c *Config) *int { return &c.SMS.Digits }),
+	intSetting("sms.resend_interval", "COMET_SMS_RESEND_INTERVAL", "seconds before sending the code again",
+		func(c *Config) *int { return &c.SMS.ResendInterval }),
+	intSetting("sms.expire", "COMET_SMS_EXPIRE", "seconds the code could be checked",
+		func(c *Config) *int { return &c.SMS.Expire }),
+}
+
+// Loader merges the config file, environment variables and command-line flags,
+// the latter overrides the former.
+type Loader struct {
+	fs     *flag.FlagSet
+	path   *string
+	flags  map[string]*string
+	lookup func(key string) (string, bool)
+}
+
+// NewLoader defines -config and a flag for every setting on fs.
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{
+		fs:     fs,
+		path:   fs.String("config", "config.yaml", "path of the YAML config file, could be empty"),
+		flags:  make(map[string]*string, len(settings)),
+		lookup: os.LookupEnv,
+	}
+
+	for _, s := range settings {
+		l.flags[s.key] = fs.String(s.key, "", fmt.Sprintf("%s (env %s)", s.usage, s.env))
+	}
+
+	return l
+}
+
+// Load should be called after fs is parsed.
+func (l *Loader) Load() (*Config, error) {
+	c := Default()
+
+	if *l.path != "" {
+		data, err := ioutil.ReadFile(*l.path)
+		if err != nil {
+			return nil, err
+		}
+
+		if c, err = Parse(data); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		v, ok := l.lookup(s.env)
+		if !ok {
+			continue
+		}
+		if err := s.set(c, v); err != nil {
+			return nil, fmt.Errorf("config: env %s: %w", s.env, err)
+		}
+	}
+
+	set := make(map[string]bool)
+	l.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, s := range settings {
+		if !set[s.key] {
+			continue
+		}
+		if err := s.set(c, *l.flags[s.key]); err != nil {
+			return nil, fmt.Errorf("config: flag -%s: %w", s.key, err)
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// String returns the config with secrets redacted, safe to log.
+func (c *Config) String() string {
+	var b strings.Builder
+
+	for _, s := range settings {
+		v := s.get(c)
+		if s.secret && v != "" {
+			v = redact(s.key, v)
+		}
+		fmt.Fprintf(&b, "%s=%s ", s.key, v)
+	}
+
+	fmt.Fprintf(&b, "middlewares=%s modules=", strings.Join(c.Middlewares, ","))
+	for i, m := range c.Modules {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, "%s:%s", m.Name, m.Group)
+	}
+
+	return b.String()
+}
+
+// redact hides the secret, only the password part of a DSN.
+func redact(key, v string) string {
+	if key != "database.dsn" {
+		return redacted
+	}
+
+	at := strings.LastIndex(v, "@")
+	if at < 0 {
+		return v
+	}
+
+	colon := strings.Index(v[:at], ":")
+	if colon < 0 {
+		return v
+	}
+
+	return v[:colon+1] + redacted + v[at:]
+}
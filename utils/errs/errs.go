@@ -0,0 +1,178 @@
+package errs
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// errDuplicateEntry is the MySQL error number of ER_DUP_ENTRY.
+const errDuplicateEntry = 1062
+
+// Kind is what kind of failure an error is, it decides the HTTP status.
+type Kind uint8
+
+// The kinds of error, an error of no kind is internal.
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindInactive
+	KindRateLimited
+	KindLocked
+)
+
+var kinds = []struct {
+	code   string
+	status int
+}{
+	KindInternal:     {"internal", http.StatusInternalServerError},
+	KindValidation:   {"validation", http.StatusBadRequest},
+	KindUnauthorized: {"unauthorized", http.StatusUnauthorized},
+	KindForbidden:    {"forbidden", http.StatusForbidden},
+	KindNotFound:     {"not_found", http.StatusNotFound},
+	KindConflict:     {"conflict", http.StatusConflict},
+	KindInactive:     {"inactive", http.StatusLocked},
+	KindRateLimited:  {"rate_limited", http.StatusTooManyRequests},
+	KindLocked:       {"locked", http.StatusLocked},
+}
+
+// String returns the stable code of the kind used in the response.
+func (k Kind) String() string {
+	if int(k) >= len(kinds) {
+		return kinds[KindInternal].code
+	}
+	return kinds[k].code
+}
+
+// Status returns the HTTP status of the kind.
+func (k Kind) Status() int {
+	if int(k) >= len(kinds) {
+		return kinds[KindInternal].status
+	}
+	return kinds[k].status
+}
+
+// Error is an error of a kind, the message is safe to show to the client.
+type Error struct {
+	Kind    Kind
+	Message string
+	// Details is shown to the client as is, like the invalid fields.
+	Details interface{}
+	// Err is the cause, it's only logged.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates an error of kind.
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap creates an error of kind caused by err.
+func Wrap(kind Kind, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+// Validation creates an error of KindValidation.
+func Validation(message string) *Error { return New(KindValidation, message) }
+
+// Unauthorized creates an error of KindUnauthorized.
+func Unauthorized(message string) *Error { return New(KindUnauthorized, message) }
+
+// Forbidden creates an error of KindForbidden.
+func Forbidden(message string) *Error { return New(KindForbidden, message) }
+
+// NotFound creates an error of KindNotFound.
+func NotFound(message string) *Error { return New(KindNotFound, message) }
+
+// Conflict creates an error of KindConflict.
+func Conflict(message string) *Error { return New(KindConflict, message) }
+
+// Inactive creates an error of KindInactive.
+func Inactive(message string) *Error { return New(KindInactive, message) }
+
+// RateLimited creates an error of KindRateLimited.
+func RateLimited(message string) *Error { return New(KindRateLimited, message) }
+
+// Locked creates an error of KindLocked.
+func Locked(message string) *Error { return New(KindLocked, message) }
+
+// Internal hides err from the client.
+func Internal(err error) *Error { return Wrap(KindInternal, "internal error", err) }
+
+// WithDetails returns a copy of e with the details.
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// As returns err as an *Error, an error of no kind is internal.
+func As(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Internal(err)
+}
+
+// KindOf returns the kind of err.
+func KindOf(err error) Kind {
+	return As(err).Kind
+}
+
+// Is reports whether err is of kind.
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
+
+// FromStatus creates an error of the kind having the HTTP status.
+func FromStatus(status int, message string) *Error {
+	for k, v := range kinds {
+		if v.status == status {
+			return New(Kind(k), message)
+		}
+	}
+	return Wrap(KindInternal, message, errors.New(message))
+}
+
+// FromDB converts the database error, what names the record in the message.
+// sql.ErrNoRows is not found and a duplicate entry is conflict.
+func FromDB(err error, what string) error {
+	if err == nil {
+		return nil
+	}
+
+	var typed *Error
+	if errors.As(err, &typed) {
+		return err
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return Wrap(KindNotFound, what+" not found", err)
+	}
+
+	var e *mysql.MySQLError
+	if errors.As(err, &e) && e.Number == errDuplicateEntry {
+		return Wrap(KindConflict, what+" already exists", err)
+	}
+
+	return Internal(err)
+}
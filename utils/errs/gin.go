@@ -0,0 +1,106 @@
+package errs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// RequestIDHeader is where the request ID is read from and written to.
+	RequestIDHeader = "X-Request-ID"
+
+	requestIDKey = "requestID"
+)
+
+// Body is the response of every failed request.
+type Body struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details"`
+	RequestID string      `json:"request_id"`
+}
+
+// FieldError is the detail of a field failed the binding rule.
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+// Handler tags the request with an ID, and if a handler adds an error by
+// c.Error without writing the response, it answers with the last error.
+// It should be the first middleware.
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		c.Next()
+
+		if c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+
+		e := As(c.Errors.Last().Err)
+		if e.Kind == KindInternal {
+			log.Println("[Error] :", id, e)
+		}
+		c.JSON(e.Kind.Status(), Body{
+			Code:      e.Kind.String(),
+			Message:   e.Message,
+			Details:   e.Details,
+			RequestID: id,
+		})
+	}
+}
+
+// NoRoute answers not found in the same body.
+func NoRoute(c *gin.Context) {
+	c.Error(NotFound("no such API"))
+}
+
+// RequestID returns the ID of the request.
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// Bind converts the binding error to a validation error listing the invalid fields.
+func Bind(err error) *Error {
+	var fields validator.ValidationErrors
+	if !errors.As(err, &fields) {
+		return Wrap(KindValidation, "invalid request", err)
+	}
+
+	details := make([]FieldError, 0, len(fields))
+	for _, f := range fields {
+		details = append(details, FieldError{
+			Field: f.Field(),
+			Rule:  f.Tag(),
+			Param: f.Param(),
+		})
+	}
+
+	return &Error{
+		Kind:    KindValidation,
+		Message: "invalid request",
+		Details: details,
+		Err:     err,
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -6,23 +6,24 @@ import (
 	"os"
 )
 
-// StartFileServer start file server with specified path to file.
-func StartFileServer(host, path string) {
-	// http.Handle("/images/", http.StripPrefix("/images/", http.FileServer(http.Dir(path+"images/"))))
-	var restart = 1
-restart:
+// New creates the file server with specified path to file, the working directory if path is empty.
+func New(host, path string) *http.Server {
 	if path == "" {
 		path, _ = os.Getwd()
 	}
-	log.Println("Starting FileServer in ", host, "with", path)
-	if err := http.ListenAndServe(host, http.FileServer(http.Dir(path))); err != nil {
-		log.Println(err)
-	}
-	restart++
-	if restart <= 10 {
-		goto restart
+
+	return &http.Server{
+		Addr:    host,
+		Handler: http.FileServer(http.Dir(path)),
 	}
-	log.Println("[File Server] : Starting 10 times... but fialed.")
+}
+
+// StartFileServer start file server with specified path to file.
+// It returns the error at once if the server failed.
+func StartFileServer(host, path string) error {
+	srv := New(host, path)
+	log.Println("Starting FileServer in ", host, "with", path)
+	return srv.ListenAndServe()
 }
 
 func PathExists(path string) (bool, error) {
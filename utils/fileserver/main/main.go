@@ -1,6 +1,7 @@
 package main
 
 import (
+	"log"
 	"os"
 
 	"github.com/abserari/shower/utils/fileserver"
@@ -8,5 +9,5 @@ import (
 
 func main() {
 	wdir, _ := os.Getwd()
-	fileserver.StartFileServer(":9573", wdir)
+	log.Fatal(fileserver.StartFileServer(":9573", wdir))
 }
@@ -0,0 +1,64 @@
+package mail
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File writes every message to a .eml file under Dir instead of sending,
+// used in development.
+type File struct {
+	Dir  string
+	From string
+}
+
+// Send writes m to a new file under Dir.
+func (f *File) Send(m *Message) error {
+	msg, err := encode(f.From, m)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(f.Dir, 0700); err != nil {
+		return err
+	}
+
+	to := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, m.To)
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), to)
+
+	return ioutil.WriteFile(filepath.Join(f.Dir, name), msg, 0600)
+}
+
+// Memory keeps the messages instead of sending, used in tests.
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Send keeps a copy of m.
+func (mem *Memory) Send(m *Message) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	mem.messages = append(mem.messages, *m)
+	return nil
+}
+
+// Messages returns the messages sent, the oldest first.
+func (mem *Memory) Messages() []Message {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	return append([]Message(nil), mem.messages...)
+}
@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/abserari/shower/utils/config"
+)
+
+var errHeader = errors.New("mail: line break in the header")
+
+// Message is a plain text mail.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends the messages.
+type Mailer interface {
+	Send(m *Message) error
+}
+
+// New returns the mailer of the driver in conf.
+func New(conf config.Mail) (Mailer, error) {
+	switch conf.Driver {
+	case config.SMTPMail:
+		return NewSMTP(conf.Host, conf.Port, conf.Username, conf.Password, conf.From), nil
+	case config.FileMail:
+		return &File{Dir: conf.Dir, From: conf.From}, nil
+	case config.MemoryMail:
+		return &Memory{}, nil
+	}
+	return nil, fmt.Errorf("mail: driver %s is not supported", conf.Driver)
+}
+
+// encode returns the message in RFC 5322 from the sender.
+func encode(from string, m *Message) ([]byte, error) {
+	for _, header := range []string{from, m.To, m.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, errHeader
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTP sends the messages by an SMTP server, with STARTTLS if the server supports it.
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTP returns the mailer of the server, it logins if username is not empty.
+func NewSMTP(host string, port int, username, password, from string) *SMTP {
+	s := &SMTP{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+	}
+	if username != "" {
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return s
+}
+
+// Send sends m by the SMTP server.
+func (s *SMTP) Send(m *Message) error {
+	msg, err := encode(s.from, m)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{m.To}, msg)
+}
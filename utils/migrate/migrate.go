@@ -0,0 +1,277 @@
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// errNoSuchTable is the MySQL error number of ER_NO_SUCH_TABLE.
+const errNoSuchTable = 1146
+
+const (
+	mysqlMigrationCreateTable = iota
+	mysqlMigrationInsert
+	mysqlMigrationDelete
+	mysqlMigrationApplied
+)
+
+var (
+	errVersionZero  = errors.New("migrate: version should be greater than 0")
+	errVersionOrder = func(module string, version uint32) error {
+		return fmt.Errorf("migrate: %s version %d is not in ascending order", module, version)
+	}
+	errUnknownVersion = func(module string, version uint32) error {
+		return fmt.Errorf("migrate: %s version %d is applied but unknown", module, version)
+	}
+
+	migrationSQLString = []string{
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			module		VARCHAR(128) NOT NULL,
+			version		INT UNSIGNED NOT NULL,
+			description	VARCHAR(512) NOT NULL DEFAULT ' ',
+			applied_at	DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (module,version)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`,
+		`INSERT INTO schema_migrations(module,version,description,applied_at) VALUES (?,?,?,?)`,
+		`DELETE FROM schema_migrations WHERE module = ? AND version = ? LIMIT 1`,
+		`SELECT version,applied_at FROM schema_migrations WHERE module = ? ORDER BY version`,
+	}
+)
+
+// Migration is one schema change of a module.
+type Migration struct {
+	Version     uint32
+	Description string
+	Up          []string
+	Down        []string
+	// UpFunc and DownFunc run after the statements, used to change data.
+	UpFunc   func(db *sql.DB) error
+	DownFunc func(db *sql.DB) error
+}
+
+// Status is a migration and when it's applied.
+type Status struct {
+	Version     uint32
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+// Migrator applies migrations and records them in schema_migrations.
+type Migrator struct {
+	db *sql.DB
+	// DryRun prints the statements to Out instead of executing.
+	DryRun bool
+	Out    io.Writer
+}
+
+// New creates a Migrator.
+func New(db *sql.DB) *Migrator {
+	return &Migrator{
+		db:  db,
+		Out: os.Stdout,
+	}
+}
+
+func (m *Migrator) createTable() error {
+	_, err := m.db.Exec(migrationSQLString[mysqlMigrationCreateTable])
+	return err
+}
+
+func (m *Migrator) applied(module string) (map[uint32]time.Time, error) {
+	var (
+		version   uint32
+		appliedAt time.Time
+		result    = make(map[uint32]time.Time)
+	)
+
+	// dry run changes nothing, even the schema_migrations table.
+	if !m.DryRun {
+		if err := m.createTable(); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := m.db.Query(migrationSQLString[mysqlMigrationApplied], module)
+	if err != nil {
+		if e, ok := err.(*mysql.MySQLError); ok && m.DryRun && e.Number == errNoSuchTable {
+			return result, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = appliedAt
+	}
+
+	return result, rows.Err()
+}
+
+func check(module string, migrations []Migration) error {
+	var last uint32
+	for _, mi := range migrations {
+		if mi.Version == 0 {
+			return errVersionZero
+		}
+		if mi.Version <= last {
+			return errVersionOrder(module, mi.Version)
+		}
+		last = mi.Version
+	}
+
+	return nil
+}
+
+// Status lists migrations of module and if they are applied.
+func (m *Migrator) Status(module string, migrations []Migration) ([]Status, error) {
+	if err := check(module, migrations); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(module)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(migrations))
+	for _, mi := range migrations {
+		at, ok := applied[mi.Version]
+		result = append(result, Status{
+			Version:     mi.Version,
+			Description: mi.Description,
+			Applied:     ok,
+			AppliedAt:   at,
+		})
+	}
+
+	return result, nil
+}
+
+// Up applies the pending migrations of module in ascending order.
+func (m *Migrator) Up(module string, migrations []Migration) error {
+	if err := check(module, migrations); err != nil {
+		return err
+	}
+
+	applied, err := m.applied(module)
+	if err != nil {
+		return err
+	}
+
+	for _, mi := range migrations {
+		if _, ok := applied[mi.Version]; ok {
+			continue
+		}
+
+		if err := m.run(module, "up", mi.Version, mi.Description, mi.Up, mi.UpFunc); err != nil {
+			return err
+		}
+
+		if m.DryRun {
+			continue
+		}
+
+		_, err := m.db.Exec(migrationSQLString[mysqlMigrationInsert], module, mi.Version, mi.Description, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Down rolls back the last steps applied migrations of module.
+func (m *Migrator) Down(module string, migrations []Migration, steps int) error {
+	if err := check(module, migrations); err != nil {
+		return err
+	}
+
+	applied, err := m.applied(module)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[uint32]bool, len(migrations))
+	for _, mi := range migrations {
+		known[mi.Version] = true
+	}
+	for version := range applied {
+		if !known[version] {
+			return errUnknownVersion(module, version)
+		}
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		mi := migrations[i]
+		if _, ok := applied[mi.Version]; !ok {
+			continue
+		}
+		steps--
+
+		if err := m.run(module, "down", mi.Version, mi.Description, mi.Down, mi.DownFunc); err != nil {
+			return err
+		}
+
+		if m.DryRun {
+			continue
+		}
+
+		_, err := m.db.Exec(migrationSQLString[mysqlMigrationDelete], module, mi.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) run(module, direction string, version uint32, description string, statements []string, fn func(db *sql.DB) error) error {
+	if m.DryRun {
+		fmt.Fprintf(m.Out, "-- %s %s %d: %s\n", module, direction, version, description)
+		for _, stmt := range statements {
+			fmt.Fprintf(m.Out, "%s\n", stmt)
+		}
+		if fn != nil {
+			fmt.Fprintf(m.Out, "-- and a data migration in Go\n")
+		}
+		return nil
+	}
+
+	for _, stmt := range statements {
+		if _, err := m.db.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate: %s %s %d: %w", module, direction, version, err)
+		}
+	}
+
+	if fn != nil {
+		if err := fn(m.db); err != nil {
+			return fmt.Errorf("migrate: %s %s %d: %w", module, direction, version, err)
+		}
+	}
+
+	return nil
+}
+
+// Source is anything knowing its migrations, like a repository stored in MySQL.
+type Source interface {
+	Migrations() []Migration
+}
+
+// Of returns the migrations of v if it's a Source, or nil, for example
+// when v is an in-memory repository.
+func Of(v interface{}) []Migration {
+	if s, ok := v.(Source); ok {
+		return s.Migrations()
+	}
+	return nil
+}
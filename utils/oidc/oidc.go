@@ -0,0 +1,315 @@
+// Package oidc logins by an OpenID Connect provider in the authorization
+// code flow with PKCE. The provider is discovered from the issuer, and the
+// ID token is verified by the keys it publishes.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/token"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// DiscoveryPath is where the metadata of the provider is under the issuer.
+const DiscoveryPath = "/.well-known/openid-configuration"
+
+var (
+	errIssuer    = errors.New("oidc: the issuer of the metadata is not configured")
+	errNoIDToken = errors.New("oidc: no id_token in the token response")
+	errIDToken   = errors.New("oidc: id_token is invalid")
+	errNonce     = errors.New("oidc: nonce of the id_token doesn't match")
+	errStatus    = func(what string, status int, body []byte) error {
+		return fmt.Errorf("oidc: %s answered %d: %s", what, status, body)
+	}
+)
+
+// Metadata is the part of the provider metadata used by the flow.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Claims are the claims of the ID token telling who logs in.
+type Claims struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// Provider is an OpenID provider, the metadata and keys are fetched once
+// used and cached.
+type Provider struct {
+	conf   config.OIDC
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *Metadata
+	keys     *token.KeySet
+}
+
+// New creates the Provider of conf.
+func New(conf config.OIDC) *Provider {
+	return &Provider{
+		conf:   conf,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthCodeURL returns the URL where the admin logins at the provider, who
+// is redirected back with a code and state then. The code challenge is of
+// verifier and the ID token will carry nonce.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(m.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.conf.ClientID)
+	query.Set("redirect_uri", p.conf.RedirectURL)
+	query.Set("scope", strings.Join(p.conf.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", Challenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange redeems the code by verifier, and returns the claims of the ID
+// token carrying nonce.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.conf.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	// a public client has no secret.
+	if p.conf.ClientSecret == "" {
+		form.Set("client_id", p.conf.ClientID)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.conf.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.conf.ClientID), url.QueryEscape(p.conf.ClientSecret))
+	}
+
+	var resp struct {
+		IDToken string `json:"id_token"`
+	}
+	if err = p.do(req, "token endpoint", &resp); err != nil {
+		return nil, err
+	}
+	if resp.IDToken == "" {
+		return nil, errNoIDToken
+	}
+
+	return p.verify(ctx, resp.IDToken, nonce)
+}
+
+// verify checks the signature, issuer, audience, expiry and nonce of the ID token.
+func (p *Provider) verify(ctx context.Context, raw, nonce string) (*Claims, error) {
+	keys, err := p.keySet(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := keys.Parse(raw)
+	if ve, ok := err.(*jwt.ValidationError); ok && ve.Inner != nil && ve.Errors&jwt.ValidationErrorUnverifiable != 0 {
+		// the keys may be rotated since fetched.
+		if keys, err = p.keySet(ctx, true); err != nil {
+			return nil, err
+		}
+		t, err = keys.Parse(raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", errIDToken, err)
+	}
+
+	claims, ok := t.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errIDToken
+	}
+	if !claims.VerifyIssuer(p.conf.Issuer, true) || !audience(claims, p.conf.ClientID) {
+		return nil, errIDToken
+	}
+	if _, ok = claims["exp"].(float64); !ok {
+		return nil, errIDToken
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, errNonce
+	}
+
+	c := &Claims{Issuer: p.conf.Issuer}
+	c.Subject, _ = claims["sub"].(string)
+	c.Email, _ = claims["email"].(string)
+	c.Name, _ = claims["name"].(string)
+	c.PreferredUsername, _ = claims["preferred_username"].(string)
+	// some providers answer it in string.
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		c.EmailVerified = v
+	case string:
+		c.EmailVerified = v == "true"
+	}
+
+	if c.Subject == "" {
+		return nil, errIDToken
+	}
+	return c, nil
+}
+
+// audience tells whether the aud claim, a string or an array, contains clientID.
+func audience(claims jwt.MapClaims, clientID string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// discover fetches the metadata of the issuer once.
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(p.conf.Issuer, "/")+DiscoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Metadata
+	if err = p.do(req.WithContext(ctx), "discovery", &m); err != nil {
+		return nil, err
+	}
+	if m.Issuer != p.conf.Issuer {
+		return nil, errIssuer
+	}
+
+	p.metadata = &m
+	return p.metadata, nil
+}
+
+// keySet returns the keys of the provider, they are fetched again if refresh.
+func (p *Provider) keySet(ctx context.Context, refresh bool) (*token.KeySet, error) {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil && !refresh {
+		return p.keys, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, m.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var set token.JWKSet
+	if err = p.do(req.WithContext(ctx), "jwks", &set); err != nil {
+		return nil, err
+	}
+
+	// the keys of the other algorithms or for encryption are skipped.
+	var keys []*token.Key
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if k, err := token.ParseJWK(jwk); err == nil {
+			keys = append(keys, k)
+		}
+	}
+
+	if p.keys, err = token.NewVerifyingKeySet(keys...); err != nil {
+		return nil, err
+	}
+	return p.keys, nil
+}
+
+// do sends req and decodes the JSON answered into v.
+func (p *Provider) do(req *http.Request, what string, v interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errStatus(what, resp.StatusCode, body)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// RandomString returns a random string of URL safe characters, used as the
+// state, nonce and code verifier.
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Challenge is the S256 code challenge of verifier.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
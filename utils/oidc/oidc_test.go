@@ -0,0 +1,125 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/abserari/shower/utils/oidc"
+	"github.com/abserari/shower/utils/oidc/oidctest"
+)
+
+const redirectURL = "http://shower.test/api/v1/userAuth/oidc/callback"
+
+var user = oidctest.User{
+	Subject:           "alice-subject",
+	Email:             "alice@example.com",
+	EmailVerified:     true,
+	Name:              "Alice",
+	PreferredUsername: "alice",
+}
+
+// authorize starts the login at idp and returns the code redirected back.
+func authorize(t *testing.T, idp *oidctest.Server, p *oidc.Provider, state, nonce, verifier string) string {
+	t.Helper()
+
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(back)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(back, redirectURL+"?") {
+		t.Fatalf("redirected back to %s", back)
+	}
+	if got := u.Query().Get("state"); got != state {
+		t.Fatalf("state %q, want %q", got, state)
+	}
+	return u.Query().Get("code")
+}
+
+func newProvider(t *testing.T) (*oidctest.Server, *oidc.Provider) {
+	t.Helper()
+
+	idp, err := oidctest.NewServer(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(idp.Close)
+
+	return idp, oidc.New(idp.Config(redirectURL))
+}
+
+func TestExchange(t *testing.T) {
+	idp, p := newProvider(t)
+	ctx := context.Background()
+
+	code := authorize(t, idp, p, "state", "nonce", "verifier")
+	claims, err := p.Exchange(ctx, code, "verifier", "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := oidc.Claims{
+		Issuer:            idp.URL,
+		Subject:           user.Subject,
+		Email:             user.Email,
+		EmailVerified:     user.EmailVerified,
+		Name:              user.Name,
+		PreferredUsername: user.PreferredUsername,
+	}
+	if *claims != want {
+		t.Errorf("claims %+v, want %+v", *claims, want)
+	}
+
+	// the code is exchanged once.
+	if _, err = p.Exchange(ctx, code, "verifier", "nonce"); err == nil {
+		t.Error("the code is exchanged twice")
+	}
+}
+
+func TestExchangeVerifier(t *testing.T) {
+	idp, p := newProvider(t)
+
+	code := authorize(t, idp, p, "state", "nonce", "verifier")
+	if _, err := p.Exchange(context.Background(), code, "another verifier", "nonce"); err == nil {
+		t.Error("the code is exchanged by another verifier")
+	}
+}
+
+func TestExchangeNonce(t *testing.T) {
+	idp, p := newProvider(t)
+
+	code := authorize(t, idp, p, "state", "nonce", "verifier")
+	_, err := p.Exchange(context.Background(), code, "verifier", "another nonce")
+	if err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Errorf("error %v, want the nonce mismatched", err)
+	}
+}
+
+func TestExchangeIssuer(t *testing.T) {
+	idp, _ := newProvider(t)
+
+	conf := idp.Config(redirectURL)
+	conf.Issuer += "/"
+	p := oidc.New(conf)
+	if _, err := p.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); err == nil {
+		t.Error("the metadata of another issuer is used")
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	// RFC 7636 Appendix B.
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	if got, want := oidc.Challenge(verifier), "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("challenge %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,277 @@
+// Package oidctest runs a local OpenID provider to test the single sign-on,
+// it approves every authorization as the current user without a login page.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/oidc"
+	"github.com/abserari/shower/utils/token"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// The credentials of the client registered at the Server.
+const (
+	ClientID     = "shower"
+	ClientSecret = "oidctest-secret"
+)
+
+// User is who is authorized by the Server.
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// grant is an authorization waiting for the code exchanged.
+type grant struct {
+	user        User
+	redirectURI string
+	challenge   string
+	nonce       string
+	expire      time.Time
+}
+
+// Server is the local OpenID provider, URL is the issuer.
+type Server struct {
+	*httptest.Server
+
+	keys *token.KeySet
+
+	mu     sync.Mutex
+	user   User
+	grants map[string]*grant
+}
+
+// NewServer starts a Server authorizing user.
+func NewServer(user User) (*Server, error) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+
+	key, err := token.ParsePrivateKey("", config.RS256, data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{user: user, grants: make(map[string]*grant)}
+	if s.keys, err = token.NewKeySet(key); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidc.DiscoveryPath, s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+	s.Server = httptest.NewServer(mux)
+
+	return s, nil
+}
+
+// SetUser authorizes user from now on.
+func (s *Server) SetUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.user = user
+}
+
+// Config is the config of userAuth to login by the Server, the admins are
+// redirected back to redirectURL.
+func (s *Server) Config(redirectURL string) config.OIDC {
+	return config.OIDC{
+		Issuer:       s.URL,
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+		MatchEmail:   true,
+	}
+}
+
+// Authorize follows the URL redirecting to the Server as a browser, and
+// returns the URL redirected back with the code.
+func (s *Server) Authorize(authURL string) (string, error) {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		return "", fmt.Errorf("oidctest: authorize answered %d", resp.StatusCode)
+	}
+	return resp.Header.Get("Location"), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, oidc.Metadata{
+		Issuer:                s.URL,
+		AuthorizationEndpoint: s.URL + "/authorize",
+		TokenEndpoint:         s.URL + "/token",
+		JWKSURI:               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.keys.JWKS())
+}
+
+// authorize approves the request at once and redirects back with the code.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Host == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("client_id") != ClientID || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	code, err := oidc.RandomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	s.grants[code] = &grant{
+		user:        s.user,
+		redirectURI: redirectURI.String(),
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+		expire:      time.Now().Add(time.Minute),
+	}
+	s.mu.Unlock()
+
+	back := redirectURI.Query()
+	back.Set("code", code)
+	back.Set("state", query.Get("state"))
+	redirectURI.RawQuery = back.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token exchanges the code once for the ID token.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := authenticate(r); err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+
+	s.mu.Lock()
+	g, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	if !ok || time.Now().After(g.expire) || r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("redirect_uri") != g.redirectURI ||
+		oidc.Challenge(r.PostForm.Get("code_verifier")) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            g.user.Subject,
+		"aud":            ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          g.user.Email,
+		"email_verified": g.user.EmailVerified,
+	}
+	if g.nonce != "" {
+		claims["nonce"] = g.nonce
+	}
+	if g.user.Name != "" {
+		claims["name"] = g.user.Name
+	}
+	if g.user.PreferredUsername != "" {
+		claims["preferred_username"] = g.user.PreferredUsername
+	}
+
+	idToken, err := s.keys.Sign(claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	accessToken, err := oidc.RandomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// authenticate checks the client by the basic auth, or the client_id of a public client.
+func authenticate(r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		if r.PostForm.Get("client_id") != ClientID {
+			return errors.New("unknown client")
+		}
+		return nil
+	}
+
+	// the credentials are form encoded in the basic auth.
+	if id, _ = url.QueryUnescape(id); id != ClientID {
+		return errors.New("unknown client")
+	}
+	if secret, _ = url.QueryUnescape(secret); secret != ClientSecret {
+		return errors.New("wrong secret")
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,206 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SpecFile is the name of the document under the docs path.
+const SpecFile = "openapi.json"
+
+// Serve registers the docs UI at path and the document at path/openapi.json.
+// doc could be filled after Serve, but before the server starts.
+func Serve(r gin.IRoutes, path string, doc *Document) {
+	path = "/" + strings.Trim(path, "/")
+	spec := JoinPath(path, SpecFile)
+
+	page := strings.Replace(uiPage, "{{SPEC}}", template.JSEscapeString(spec), 1)
+
+	r.GET(spec, func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	})
+	r.GET(path, func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	})
+}
+
+// uiPage renders the document without any resource from the Internet,
+// and sends the requests with the token filled in.
+const uiPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>API</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; color: #222; }
+header { background: #1f2933; color: #fff; padding: 12px 24px; display: flex; align-items: center; gap: 16px; }
+header h1 { font-size: 18px; margin: 0; flex: 1; }
+header input { width: 360px; padding: 4px; }
+main { padding: 12px 24px; }
+h2 { border-bottom: 1px solid #ddd; padding-bottom: 4px; }
+details { border: 1px solid #ddd; border-radius: 4px; margin: 6px 0; }
+summary { padding: 6px 8px; cursor: pointer; }
+.method { display: inline-block; width: 64px; font-weight: bold; text-transform: uppercase; }
+.get { color: #2f80ed; } .post { color: #27ae60; } .put { color: #f2994a; } .delete { color: #eb5757; }
+.lock { color: #888; font-size: 12px; }
+.body { padding: 8px 12px; border-top: 1px solid #eee; }
+table { border-collapse: collapse; margin: 4px 0 12px; }
+td, th { border: 1px solid #eee; padding: 3px 8px; text-align: left; font-size: 13px; vertical-align: top; }
+textarea { width: 100%; height: 120px; font-family: monospace; }
+pre { background: #f6f8fa; padding: 8px; overflow: auto; }
+</style>
+</head>
+<body>
+<header><h1 id="title">API</h1><input id="token" placeholder="Bearer token"></header>
+<main id="main">Loading...</main>
+<script>
+(function () {
+  var spec = "{{SPEC}}";
+  var token = document.getElementById("token");
+  token.value = localStorage.getItem("openapi.token") || "";
+  token.onchange = function () { localStorage.setItem("openapi.token", token.value); };
+
+  function el(tag, attrs, children) {
+    var e = document.createElement(tag);
+    for (var k in attrs || {}) { e.setAttribute(k, attrs[k]); }
+    (children || []).forEach(function (c) {
+      e.appendChild(typeof c === "string" ? document.createTextNode(c) : c);
+    });
+    return e;
+  }
+
+  function resolve(doc, s) {
+    if (s && s.$ref) { return doc.components.schemas[s.$ref.split("/").pop()]; }
+    return s || {};
+  }
+
+  function typeOf(s) {
+    if (s.type === "array") { return typeOf(s.items || {}) + "[]"; }
+    if (s.type === "object" && s.additionalProperties) { return "map of " + typeOf(s.additionalProperties); }
+    return (s.type || "any") + (s.format ? " (" + s.format + ")" : "");
+  }
+
+  function rules(s) {
+    var r = [];
+    if (s.minLength !== undefined) { r.push("minLength " + s.minLength); }
+    if (s.maxLength !== undefined) { r.push("maxLength " + s.maxLength); }
+    if (s.minimum !== undefined) { r.push((s.exclusiveMinimum ? "> " : ">= ") + s.minimum); }
+    if (s.maximum !== undefined) { r.push((s.exclusiveMaximum ? "< " : "<= ") + s.maximum); }
+    if (s.minItems !== undefined) { r.push("minItems " + s.minItems); }
+    if (s.maxItems !== undefined) { r.push("maxItems " + s.maxItems); }
+    if (s.pattern) { r.push("pattern " + s.pattern); }
+    if (s.enum) { r.push("one of " + s.enum.join(", ")); }
+    if (s["x-binding"]) { r.push("binding: " + s["x-binding"]); }
+    return r.join("; ");
+  }
+
+  function table(doc, s, prefix, rows) {
+    s = resolve(doc, s);
+    var required = s.required || [];
+    Object.keys(s.properties || {}).sort().forEach(function (name) {
+      var p = resolve(doc, s.properties[name]);
+      rows.push(el("tr", {}, [
+        el("td", {}, [prefix + name]), el("td", {}, [typeOf(p)]),
+        el("td", {}, [required.indexOf(name) >= 0 ? "required" : ""]), el("td", {}, [rules(p)])
+      ]));
+      var inner = p.type === "array" ? resolve(doc, p.items) : p;
+      if (inner.properties) { table(doc, inner, prefix + name + (p.type === "array" ? "[]." : "."), rows); }
+    });
+    return rows;
+  }
+
+  function schemaTable(doc, s) {
+    var rows = table(doc, s, "", []);
+    if (!rows.length) { return el("p", {}, [typeOf(resolve(doc, s))]); }
+    return el("table", {}, [el("tr", {}, [el("th", {}, ["field"]), el("th", {}, ["type"]), el("th", {}, [""]), el("th", {}, ["constraints"])])].concat(rows));
+  }
+
+  function example(doc, s) {
+    s = resolve(doc, s);
+    if (s.type === "object" && s.properties) {
+      var o = {};
+      Object.keys(s.properties).forEach(function (k) { o[k] = example(doc, s.properties[k]); });
+      return o;
+    }
+    if (s.type === "array") { return [example(doc, s.items)]; }
+    if (s.enum) { return s.enum[0]; }
+    return s.type in zero ? zero[s.type] : null;
+  }
+  var zero = { string: "", integer: 0, number: 0, boolean: false };
+
+  function operation(doc, path, method, op) {
+    var body = el("div", { "class": "body" }, []);
+    var params = op.parameters || [];
+    if (params.length) {
+      body.appendChild(el("h4", {}, ["Parameters"]));
+      body.appendChild(el("table", {}, params.map(function (p) {
+        return el("tr", {}, [el("td", {}, [p.name]), el("td", {}, [p.in]), el("td", {}, [p.required ? "required" : ""]), el("td", {}, [typeOf(p.schema) + " " + rules(p.schema)])]);
+      })));
+    }
+
+    var content = op.requestBody ? op.requestBody.content : {};
+    var type = Object.keys(content)[0];
+    var input = null;
+    if (type) {
+      body.appendChild(el("h4", {}, ["Request " + type]));
+      body.appendChild(schemaTable(doc, content[type].schema));
+      if (type === "application/json") {
+        input = el("textarea", {}, [JSON.stringify(example(doc, content[type].schema), null, 2)]);
+        body.appendChild(input);
+      }
+    }
+
+    var ok = op.responses["200"];
+    if (ok && ok.content) {
+      body.appendChild(el("h4", {}, ["Response"]));
+      body.appendChild(schemaTable(doc, ok.content["application/json"].schema));
+    }
+
+    if (!type || input) {
+      var out = el("pre", {}, []);
+      var send = el("button", {}, ["Send"]);
+      send.onclick = function () {
+        var url = path.replace(/{(\w+)}/g, function (m, name) { return encodeURIComponent(prompt(name) || ""); });
+        var init = { method: method.toUpperCase(), headers: {} };
+        if (token.value) { init.headers.Authorization = "Bearer " + token.value; }
+        if (input) { init.headers["Content-Type"] = "application/json"; init.body = input.value; }
+        fetch(url, init).then(function (res) {
+          return res.text().then(function (text) { out.textContent = res.status + "\n" + text; });
+        });
+      };
+      body.appendChild(send);
+      body.appendChild(out);
+    }
+
+    return el("details", {}, [
+      el("summary", {}, [el("span", { "class": "method " + method }, [method]), path + "  ", op.summary || "", op.security ? el("span", { "class": "lock" }, [" token"]) : ""]),
+      body
+    ]);
+  }
+
+  fetch(spec).then(function (res) { return res.json(); }).then(function (doc) {
+    document.title = doc.info.title;
+    document.getElementById("title").textContent = doc.info.title + " " + doc.info.version;
+
+    var main = document.getElementById("main");
+    main.textContent = "";
+    main.appendChild(el("p", {}, [el("a", { href: spec }, [spec])]));
+
+    (doc.tags || []).forEach(function (tag) {
+      main.appendChild(el("h2", {}, [tag.name]));
+      Object.keys(doc.paths).sort().forEach(function (path) {
+        Object.keys(doc.paths[path]).forEach(function (method) {
+          var op = doc.paths[path][method];
+          if ((op.tags || []).indexOf(tag.name) >= 0) { main.appendChild(operation(doc, path, method, op)); }
+        });
+      });
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,295 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abserari/shower/utils/errs"
+)
+
+// Version is the OpenAPI version of the document.
+const Version = "3.0.3"
+
+const (
+	errorSchema    = "Error"
+	bearerSecurity = "bearer"
+)
+
+// Operation describes one route of a module, Path is relative to the module group.
+type Operation struct {
+	Method  string
+	Path    string
+	Summary string
+	// Request is a value of the request type. It's the JSON body, the
+	// multipart form if Multipart, or the query of a GET request.
+	Request   interface{}
+	Multipart bool
+	// Response is a value of the body answered on success.
+	Response interface{}
+	// Token is required by the route even if it's public, the route checks it itself.
+	Token bool
+}
+
+// StatusBody is the body most API answer on success.
+type StatusBody struct {
+	Status int `json:"status"`
+}
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	operations map[string]bool
+}
+
+// Info is the metadata of the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Tag groups the operations of a module.
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem holds the operations of one path by lower case method.
+type PathItem map[string]*OperationObject
+
+// OperationObject is an operation in the document.
+type OperationObject struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path or query parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the body of the request.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is one response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the shared schemas and security schemes.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme is how a protected operation is authorized.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// New creates an empty document.
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: map[string]*Schema{
+				errorSchema: SchemaOf(reflect.TypeOf(errs.Body{})),
+			},
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerSecurity: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		operations: make(map[string]bool),
+	}
+}
+
+// Add adds a registered route of the module tag. op describes the route,
+// a route without op is added with no request and response types.
+// public routes are reachable without a token.
+func (d *Document) Add(tag, method, path, handler string, public bool, op *Operation) {
+	if op == nil {
+		op = &Operation{Summary: handler}
+	}
+
+	o := &OperationObject{
+		Tags:        []string{tag},
+		Summary:     op.Summary,
+		OperationID: d.operationID(tag, method, path),
+		Parameters:  pathParameters(path),
+		Responses: map[string]Response{
+			"200": okResponse(op.Response),
+			"default": {
+				Description: "error",
+				Content: map[string]MediaType{
+					"application/json": {Schema: &Schema{Ref: "#/components/schemas/" + errorSchema}},
+				},
+			},
+		},
+	}
+
+	if !public || op.Token {
+		o.Security = []map[string][]string{{bearerSecurity: {}}}
+	}
+
+	if op.Request != nil {
+		t := reflect.TypeOf(op.Request)
+		switch {
+		case method == http.MethodGet || method == http.MethodDelete:
+			o.Parameters = append(o.Parameters, queryParameters(t)...)
+		case op.Multipart:
+			o.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"multipart/form-data": {Schema: formSchemaOf(t)}},
+			}
+		default:
+			o.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: SchemaOf(t)}},
+			}
+		}
+	}
+
+	key := openAPIPath(path)
+	item, ok := d.Paths[key]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[key] = item
+	}
+	item[strings.ToLower(method)] = o
+
+	d.addTag(tag)
+}
+
+func (d *Document) addTag(name string) {
+	for _, t := range d.Tags {
+		if t.Name == name {
+			return
+		}
+	}
+	d.Tags = append(d.Tags, Tag{Name: name})
+	sort.Slice(d.Tags, func(i, j int) bool { return d.Tags[i].Name < d.Tags[j].Name })
+}
+
+// operationID is unique in the document, like userAuth_post_api_v1_userAuth_login.
+func (d *Document) operationID(tag, method, path string) string {
+	id := tag + "_" + strings.ToLower(method) + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, path)
+
+	for unique, i := id, 2; ; i++ {
+		if !d.operations[unique] {
+			d.operations[unique] = true
+			return unique
+		}
+		unique = id + "_" + strconv.Itoa(i)
+	}
+}
+
+func okResponse(v interface{}) Response {
+	if v == nil {
+		return Response{Description: "OK"}
+	}
+
+	return Response{
+		Description: "OK",
+		Content: map[string]MediaType{
+			"application/json": {Schema: SchemaOf(reflect.TypeOf(v))},
+		},
+	}
+}
+
+// openAPIPath converts the gin path /user/:id/*path to /user/{id}/{path}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParameters(path string) []Parameter {
+	var params []Parameter
+	for _, s := range strings.Split(path, "/") {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			params = append(params, Parameter{
+				Name:     s[1:],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+	return params
+}
+
+func queryParameters(t reflect.Type) []Parameter {
+	s := formSchemaOf(t)
+
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]Parameter, 0, len(names))
+	for _, name := range names {
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: required[name],
+			Schema:   s.Properties[name],
+		})
+	}
+	return params
+}
+
+// JoinPath joins the group and the relative path the way gin does.
+func JoinPath(group, path string) string {
+	if path == "" {
+		return group
+	}
+
+	joined := strings.TrimSuffix(group, "/") + "/" + strings.TrimPrefix(path, "/")
+	if strings.HasSuffix(path, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
+	}
+	return joined
+}
@@ -0,0 +1,311 @@
+package openapi
+
+import (
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	fileType = reflect.TypeOf(multipart.FileHeader{})
+
+	// patterns of the binding rules checking the characters.
+	patterns = map[string]string{
+		"alpha":       `^[a-zA-Z]+$`,
+		"alphanum":    `^[a-zA-Z0-9]+$`,
+		"numeric":     `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+		"number":      `^[0-9]+$`,
+		"hexadecimal": `^(0[xX])?[0-9a-fA-F]+$`,
+		"printascii":  `^[\x20-\x7E]*$`,
+		"ascii":       `^[\x00-\x7F]*$`,
+		"lowercase":   `^[^A-Z]*$`,
+		"uppercase":   `^[^a-z]*$`,
+	}
+
+	// formats of the binding rules checking the string format.
+	formats = map[string]string{
+		"email":    "email",
+		"url":      "uri",
+		"uri":      "uri",
+		"uuid":     "uuid",
+		"uuid4":    "uuid",
+		"ip":       "ip",
+		"ipv4":     "ipv4",
+		"ipv6":     "ipv6",
+		"hostname": "hostname",
+		"datetime": "date-time",
+	}
+)
+
+// Schema is the JSON schema of a type.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *uint64            `json:"minLength,omitempty"`
+	MaxLength            *uint64            `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinItems             *uint64            `json:"minItems,omitempty"`
+	MaxItems             *uint64            `json:"maxItems,omitempty"`
+	// Binding is the binding tag of the field as it is.
+	Binding string `json:"x-binding,omitempty"`
+}
+
+// SchemaOf returns the schema of t encoded in JSON, the binding tags of the
+// fields become the constraints.
+func SchemaOf(t reflect.Type) *Schema {
+	return schemaOf(t, "json", make(map[reflect.Type]bool))
+}
+
+// formSchemaOf is SchemaOf but names the fields by the form tag, the way gin binds a form or query.
+func formSchemaOf(t reflect.Type) *Schema {
+	return schemaOf(t, "form", make(map[reflect.Type]bool))
+}
+
+func schemaOf(t reflect.Type, nameTag string, seen map[reflect.Type]bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case fileType:
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := schemaOf(t.Elem(), nameTag, seen)
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Minimum: float(0)}
+	case reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Minimum: float(0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), nameTag, seen)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), nameTag, seen)}
+	case reflect.Struct:
+		// a type refers to itself is not expanded again.
+		if seen[t] {
+			return &Schema{Type: "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		addFields(s, t, nameTag, seen)
+		return s
+	}
+
+	// interface and the others could be anything.
+	return &Schema{}
+}
+
+func addFields(s *Schema, t reflect.Type, nameTag string, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		name, opts := fieldName(f, nameTag)
+		if name == "-" {
+			continue
+		}
+
+		// the fields of an embedded struct are promoted, like encoding/json does.
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(s, ft, nameTag, seen)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := schemaOf(f.Type, nameTag, seen)
+		if strings.Contains(opts, "string") && fs.Type != "object" && fs.Type != "array" {
+			fs = &Schema{Type: "string"}
+		}
+
+		if tag, ok := f.Tag.Lookup("binding"); ok && tag != "" {
+			if bind(fs, tag) {
+				s.Required = append(s.Required, name)
+			}
+		}
+
+		s.Properties[name] = fs
+	}
+}
+
+func fieldName(f reflect.StructField, nameTag string) (string, string) {
+	tag := f.Tag.Get(nameTag)
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+// bind applies the binding rules on s and reports whether the field is required.
+// The rules after dive apply to the elements.
+func bind(s *Schema, tag string) bool {
+	s.Binding = tag
+
+	target := s
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		if rule == "dive" {
+			if target.Items == nil {
+				break
+			}
+			target = target.Items
+			continue
+		}
+
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		if name == "required" && target == s {
+			required = true
+			continue
+		}
+
+		applyRule(target, name, param)
+	}
+
+	return required
+}
+
+func applyRule(s *Schema, name, param string) {
+	if p, ok := patterns[name]; ok {
+		if s.Pattern == "" {
+			s.Pattern = p
+		}
+		return
+	}
+
+	if f, ok := formats[name]; ok {
+		s.Format = f
+		return
+	}
+
+	switch name {
+	case "len":
+		setBound(s, "min", param, false)
+		setBound(s, "max", param, false)
+	case "min", "gte":
+		setBound(s, "min", param, false)
+	case "max", "lte":
+		setBound(s, "max", param, false)
+	case "gt":
+		setBound(s, "min", param, true)
+	case "lt":
+		setBound(s, "max", param, true)
+	case "oneof":
+		for _, v := range strings.Fields(param) {
+			s.Enum = append(s.Enum, enumValue(s, v))
+		}
+	}
+}
+
+// setBound sets the length of a string, the size of an array, or the range of a number.
+func setBound(s *Schema, side, param string, exclusive bool) {
+	switch s.Type {
+	case "string", "array":
+		n, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return
+		}
+		if exclusive && side == "min" {
+			n++
+		}
+		if exclusive && side == "max" && n > 0 {
+			n--
+		}
+
+		switch {
+		case s.Type == "string" && side == "min":
+			s.MinLength = &n
+		case s.Type == "string":
+			s.MaxLength = &n
+		case side == "min":
+			s.MinItems = &n
+		default:
+			s.MaxItems = &n
+		}
+	case "integer", "number":
+		v, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+
+		if side == "min" {
+			s.Minimum = &v
+			s.ExclusiveMinimum = exclusive
+		} else {
+			s.Maximum = &v
+			s.ExclusiveMaximum = exclusive
+		}
+	}
+}
+
+func enumValue(s *Schema, v string) interface{} {
+	switch s.Type {
+	case "integer":
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+func float(v float64) *float64 {
+	return &v
+}
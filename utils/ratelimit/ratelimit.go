@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows limit hits of a key in every window. The hits are kept in
+// the memory of the process, each instance of the server limits on its own.
+type Limiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string]*bucket
+	swept  time.Time
+}
+
+type bucket struct {
+	start time.Time
+	count int
+}
+
+// New creates a Limiter allowing limit hits of a key in window.
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string]*bucket),
+		swept:  time.Now(),
+	}
+}
+
+// Allow counts a hit of key and reports whether it's within the limit.
+func (l *Limiter) Allow(key string) bool {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.hits[key]
+	if !ok || now.Sub(b.start) >= l.window {
+		b = &bucket{start: now}
+		l.hits[key] = b
+	}
+	b.count++
+
+	return b.count <= l.limit
+}
+
+// Reset forgets the hits of key.
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.hits, key)
+}
+
+// sweep drops the past windows once a window.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < l.window {
+		return
+	}
+	l.swept = now
+
+	for key, b := range l.hits {
+		if now.Sub(b.start) >= l.window {
+			delete(l.hits, key)
+		}
+	}
+}
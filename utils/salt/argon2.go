@@ -0,0 +1,107 @@
+package salt
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2idPrefix = "$argon2id$"
+
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+	// argon2MaxMemory in KiB bounds the memory of a hash decoded, so a
+	// malformed hash couldn't allocate without limit.
+	argon2MaxMemory = 4 << 20
+)
+
+// Argon2id hashes by argon2id in the PHC string format, like
+// $argon2id$v=19$m=65536,t=1,p=2$salt$key.
+type Argon2id struct {
+	// Time is the iterations, Memory is in KiB.
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// argon2Hash is a decoded argon2id hash.
+type argon2Hash struct {
+	params Argon2id
+	salt   []byte
+	key    []byte
+}
+
+// Hash hashes password by a random salt.
+func (a Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.Time, a.Memory, a.Threads, argon2KeyLen)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		a.Memory, a.Time, a.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify reports rehash unless hash is argon2id of the same parameters.
+func (a Argon2id) Verify(hash, password string) (bool, bool) {
+	return verify(a, hash, password)
+}
+
+func (a Argon2id) outdated(hash string) bool {
+	h, err := decodeArgon2id(hash)
+	return err != nil || h.params != a || len(h.key) != argon2KeyLen
+}
+
+func compareArgon2id(hash, password string) bool {
+	h, err := decodeArgon2id(hash)
+	if err != nil {
+		return false
+	}
+
+	p := h.params
+	key := argon2.IDKey([]byte(password), h.salt, p.Time, p.Memory, p.Threads, uint32(len(h.key)))
+	return subtle.ConstantTimeCompare(key, h.key) == 1
+}
+
+func decodeArgon2id(hash string) (*argon2Hash, error) {
+	// "", "argon2id", "v=19", "m=65536,t=1,p=2", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, fmt.Errorf("salt: invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, err
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("salt: argon2 version %d is not supported", version)
+	}
+
+	var h argon2Hash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.params.Memory, &h.params.Time, &h.params.Threads); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, err
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, err
+	}
+	if len(h.key) == 0 || h.params.Threads == 0 || h.params.Time == 0 || h.params.Memory > argon2MaxMemory {
+		return nil, fmt.Errorf("salt: invalid argon2id hash")
+	}
+
+	return &h, nil
+}
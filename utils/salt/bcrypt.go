@@ -0,0 +1,42 @@
+package salt
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptPrefix starts the hashes of every bcrypt version, like $2a$10$.
+const bcryptPrefix = "$2"
+
+// Bcrypt hashes by bcrypt of Cost.
+type Bcrypt struct {
+	Cost int
+}
+
+// Hash hashes password by a random salt.
+func (b Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify reports rehash unless hash is bcrypt of the same cost.
+func (b Bcrypt) Verify(hash, password string) (bool, bool) {
+	return verify(b, hash, password)
+}
+
+func (b Bcrypt) outdated(hash string) bool {
+	if !strings.HasPrefix(hash, bcryptPrefix) {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != b.Cost
+}
+
+func compareBcrypt(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
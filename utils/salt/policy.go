@@ -0,0 +1,125 @@
+package salt
+
+import (
+	"bufio"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
+)
+
+var (
+	errBreached = errs.Validation("the password is too common, choose another one")
+
+	// common are always breached, whether there's a breached file or not.
+	common = []string{
+		"111111", "123123", "123456", "1234567", "12345678", "123456789", "1234567890",
+		"000000", "654321", "666666", "888888", "abc123", "admin123", "iloveyou",
+		"password", "password1", "qwerty", "qwerty123", "qwertyuiop", "1q2w3e4r",
+	}
+)
+
+// alphabet of the passwords generated, without the characters alike.
+const alphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789!@#$%^&*-_=+"
+
+// Policy checks a new password by its length, the classes of its characters,
+// and a list of breached passwords.
+type Policy struct {
+	MinLength int
+	// Classes is how many of lower, upper, digit and symbol should be used.
+	Classes int
+	// breached passwords in lower case.
+	breached map[string]bool
+}
+
+// NewPolicy returns the policy of conf, the breached file is read once.
+func NewPolicy(conf config.Password) (*Policy, error) {
+	p := &Policy{
+		MinLength: conf.MinLength,
+		Classes:   conf.Classes,
+		breached:  make(map[string]bool, len(common)),
+	}
+	for _, password := range common {
+		p.breached[password] = true
+	}
+
+	if conf.BreachedFile == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(conf.BreachedFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if password := strings.TrimSpace(scanner.Text()); password != "" {
+			p.breached[strings.ToLower(password)] = true
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("salt: read %s: %v", conf.BreachedFile, err)
+	}
+
+	return p, nil
+}
+
+// Check returns a validation error if password is not allowed.
+func (p *Policy) Check(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return errs.Validation(fmt.Sprintf("the password should have at least %d characters", p.MinLength))
+	}
+
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	if lower+upper+digit+symbol < p.Classes {
+		return errs.Validation(fmt.Sprintf("the password should have at least %d of lower, upper, digit and symbol", p.Classes))
+	}
+
+	if p.breached[strings.ToLower(password)] {
+		return errBreached
+	}
+	return nil
+}
+
+// Generate returns a random password allowed by the policy, of at least 16 characters.
+func (p *Policy) Generate() (string, error) {
+	length := p.MinLength
+	if length < 16 {
+		length = 16
+	}
+
+	max := big.NewInt(int64(len(alphabet)))
+	b := make([]byte, length)
+	for {
+		for i := range b {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", err
+			}
+			b[i] = alphabet[n.Int64()]
+		}
+
+		if password := string(b); p.Check(password) == nil {
+			return password, nil
+		}
+	}
+}
@@ -1,24 +1,56 @@
 package salt
 
 import (
-	"golang.org/x/crypto/bcrypt"
+	"fmt"
+	"strings"
+
+	"github.com/abserari/shower/utils/config"
 )
 
-// Generate a salt hash.
-func Generate(password *string) (string, error) {
-	hex := []byte(*password)
-	hashedPassword, err := bcrypt.GenerateFromPassword(hex, 10)
-	if err != nil {
-		return "", err
+// Hasher hashes the passwords, the algorithm and its parameters are encoded
+// in the hash, so a hash of any supported algorithm could be verified.
+type Hasher interface {
+	// Hash returns the encoded hash of password.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash, and whether hash should be
+	// replaced by Hash because it's of another algorithm or parameters.
+	Verify(hash, password string) (ok, rehash bool)
+}
+
+// New returns the hasher of the algorithm in conf.
+func New(conf config.Password) (Hasher, error) {
+	switch conf.Algorithm {
+	case config.Bcrypt:
+		return Bcrypt{Cost: conf.BcryptCost}, nil
+	case config.Argon2id:
+		return Argon2id{Time: conf.Argon2Time, Memory: conf.Argon2Memory, Threads: conf.Argon2Threads}, nil
 	}
-	return string(hashedPassword), nil
+	return nil, fmt.Errorf("salt: algorithm %s is not supported", conf.Algorithm)
+}
+
+// Default is the hasher of the default config.
+func Default() Hasher {
+	h, _ := New(config.Default().Password)
+	return h
+}
+
+// outdater is a Hasher knowing whether a hash has other parameters.
+type outdater interface {
+	outdated(hash string) bool
 }
 
-// Compare a salt hash.
-func Compare(digest []byte, password *string) bool {
-	hex := []byte(*password)
-	if err := bcrypt.CompareHashAndPassword(digest, hex); err == nil {
-		return true
+// verify checks password by the algorithm of hash, and reports whether it's
+// outdated for preferred.
+func verify(preferred outdater, hash, password string) (bool, bool) {
+	var ok bool
+	switch {
+	case strings.HasPrefix(hash, argon2idPrefix):
+		ok = compareArgon2id(hash, password)
+	case strings.HasPrefix(hash, bcryptPrefix):
+		ok = compareBcrypt(hash, password)
+	}
+	if !ok {
+		return false, false
 	}
-	return false
+	return true, preferred.outdated(hash)
 }
@@ -0,0 +1,217 @@
+package salt
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/abserari/shower/utils/config"
+	"github.com/abserari/shower/utils/errs"
+)
+
+// the cheapest parameters, so the tests run fast.
+var (
+	testArgon2id = Argon2id{Time: 1, Memory: 64, Threads: 1}
+	testBcrypt   = Bcrypt{Cost: 4}
+)
+
+func TestArgon2idEncode(t *testing.T) {
+	hash, err := testArgon2id.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("hash %s", hash)
+	}
+
+	h, err := decodeArgon2id(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.params != testArgon2id || len(h.salt) != argon2SaltLen || len(h.key) != argon2KeyLen {
+		t.Errorf("decoded %+v, salt %d and key %d bytes", h.params, len(h.salt), len(h.key))
+	}
+
+	other, err := testArgon2id.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == hash {
+		t.Error("the hashes of random salts are the same")
+	}
+}
+
+func TestArgon2idMalformed(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	key := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+	for name, hash := range map[string]string{
+		"empty":         "",
+		"prefix only":   "$argon2id$",
+		"argon2i":       "$argon2i$v=19$m=64,t=1,p=1$" + salt + "$" + key,
+		"fewer parts":   "$argon2id$v=19$m=64,t=1,p=1$" + salt,
+		"more parts":    "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$" + key + "$",
+		"version":       "$argon2id$v=16$m=64,t=1,p=1$" + salt + "$" + key,
+		"no version":    "$argon2id$19$m=64,t=1,p=1$" + salt + "$" + key,
+		"params":        "$argon2id$v=19$t=1,m=64,p=1$" + salt + "$" + key,
+		"negative":      "$argon2id$v=19$m=-64,t=1,p=1$" + salt + "$" + key,
+		"threads range": "$argon2id$v=19$m=64,t=1,p=256$" + salt + "$" + key,
+		"zero threads":  "$argon2id$v=19$m=64,t=1,p=0$" + salt + "$" + key,
+		"zero time":     "$argon2id$v=19$m=64,t=0,p=1$" + salt + "$" + key,
+		"huge memory":   "$argon2id$v=19$m=4294967295,t=1,p=1$" + salt + "$" + key,
+		"salt":          "$argon2id$v=19$m=64,t=1,p=1$!!$" + key,
+		"key":           "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$!!",
+		"empty key":     "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$",
+	} {
+		if _, err := decodeArgon2id(hash); err == nil {
+			t.Errorf("%s: hash %q is decoded", name, hash)
+		}
+		if ok, rehash := testArgon2id.Verify(hash, "secret"); ok || rehash {
+			t.Errorf("%s: verified %t, rehash %t", name, ok, rehash)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	argon2idHash, err := testArgon2id.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash, err := testBcrypt.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strongerHash, err := Argon2id{Time: 2, Memory: 64, Threads: 1}.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	costlierHash, err := Bcrypt{Cost: 5}.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name     string
+		hasher   Hasher
+		hash     string
+		password string
+		ok       bool
+		rehash   bool
+	}{
+		{"argon2id by argon2id", testArgon2id, argon2idHash, "secret", true, false},
+		{"bcrypt by bcrypt", testBcrypt, bcryptHash, "secret", true, false},
+		{"bcrypt by argon2id", testArgon2id, bcryptHash, "secret", true, true},
+		{"argon2id by bcrypt", testBcrypt, argon2idHash, "secret", true, true},
+		{"argon2id of other params", testArgon2id, strongerHash, "secret", true, true},
+		{"bcrypt of other cost", testBcrypt, costlierHash, "secret", true, true},
+		{"wrong argon2id password", testArgon2id, argon2idHash, "wrong", false, false},
+		{"wrong bcrypt password", testArgon2id, bcryptHash, "wrong", false, false},
+		{"wrong outdated password", testBcrypt, argon2idHash, "wrong", false, false},
+		{"unknown algorithm", testBcrypt, "$1$salt$hash", "secret", false, false},
+		{"plain text", testBcrypt, "secret", "secret", false, false},
+	} {
+		ok, rehash := c.hasher.Verify(c.hash, c.password)
+		if ok != c.ok || rehash != c.rehash {
+			t.Errorf("%s: verified %t, rehash %t, want %t, %t", c.name, ok, rehash, c.ok, c.rehash)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	conf := config.Default().Password
+
+	conf.Algorithm = config.Argon2id
+	if h, err := New(conf); err != nil || h != (Argon2id{Time: conf.Argon2Time, Memory: conf.Argon2Memory, Threads: conf.Argon2Threads}) {
+		t.Errorf("hasher %#v, %v", h, err)
+	}
+
+	conf.Algorithm = config.Bcrypt
+	if h, err := New(conf); err != nil || h != (Bcrypt{Cost: conf.BcryptCost}) {
+		t.Errorf("hasher %#v, %v", h, err)
+	}
+
+	conf.Algorithm = "md5"
+	if _, err := New(conf); err == nil {
+		t.Error("md5 is supported")
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	p, err := NewPolicy(config.Password{MinLength: 8, Classes: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for password, allowed := range map[string]bool{
+		"":           false,
+		"aB3$":       false, // too short
+		"abcdefgh":   false, // 1 class
+		"abcdEFGH":   false, // 2 classes
+		"abcdEFG1":   true,
+		"abcdefg1!":  true,
+		"密码密码密码密码1A": true,  // counted by characters
+		"Password1":  false, // breached in any case
+		"Qwerty123":  false,
+		"QWERTY123":  false,
+	} {
+		err := p.Check(password)
+		if (err == nil) != allowed {
+			t.Errorf("password %q: error %v, allowed %t", password, err, allowed)
+		}
+		if err != nil && !errs.Is(err, errs.KindValidation) {
+			t.Errorf("password %q: error %v is not a validation error", password, err)
+		}
+	}
+}
+
+func TestPolicyGenerate(t *testing.T) {
+	for _, p := range []*Policy{
+		{MinLength: 8, Classes: 4},
+		{MinLength: 32, Classes: 2},
+	} {
+		password, err := p.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n := len(password); n < 16 || n < p.MinLength {
+			t.Errorf("generated %d characters, policy %+v", n, p)
+		}
+		if err = p.Check(password); err != nil {
+			t.Errorf("generated %q: %v", password, err)
+		}
+		for _, r := range password {
+			if !strings.ContainsRune(alphabet, r) {
+				t.Errorf("generated %q out of the alphabet", password)
+			}
+		}
+	}
+}
+
+func TestPolicyBreachedFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "breached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString("Tr0ub4dor&3\n\n  correcthorse9  \n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	p, err := NewPolicy(config.Password{MinLength: 8, Classes: 2, BreachedFile: f.Name()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, password := range []string{"tr0ub4dor&3", "CorrectHorse9", "password1"} {
+		if err = p.Check(password); err != errBreached {
+			t.Errorf("password %q: error %v, want %v", password, err, errBreached)
+		}
+	}
+
+	if _, err = NewPolicy(config.Password{BreachedFile: f.Name() + ".missing"}); err == nil {
+		t.Error("the missing breached file is read")
+	}
+}
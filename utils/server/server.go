@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DefaultTimeout is how long the shutdown waits by default.
+const DefaultTimeout = 15 * time.Second
+
+type namedServer struct {
+	name string
+	srv  *http.Server
+}
+
+type closer struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Runtime serves the http servers, and on a signal or a server failure,
+// drains them and runs the shutdown funcs in order.
+type Runtime struct {
+	Timeout time.Duration
+
+	servers []namedServer
+	closers []closer
+}
+
+// New creates a Runtime.
+func New() *Runtime {
+	return &Runtime{
+		Timeout: DefaultTimeout,
+	}
+}
+
+// Serve adds srv to serve on srv.Addr when Run.
+func (rt *Runtime) Serve(name string, srv *http.Server) {
+	rt.servers = append(rt.servers, namedServer{name: name, srv: srv})
+}
+
+// OnShutdown adds fn to run after the servers are drained, in the order they are added.
+func (rt *Runtime) OnShutdown(name string, fn func(ctx context.Context) error) {
+	rt.closers = append(rt.closers, closer{name: name, fn: fn})
+}
+
+// Run listens on every server first, so it returns at once if an address
+// is not available. Then it serves until ctx is done, SIGINT or SIGTERM
+// is received, or a server fails. It returns the failure if there is one.
+func (rt *Runtime) Run(ctx context.Context) error {
+	listeners := make([]net.Listener, 0, len(rt.servers))
+	for _, s := range rt.servers {
+		ln, err := net.Listen("tcp", s.srv.Addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			rt.shutdown()
+			return fmt.Errorf("[%s] : listen %s: %w", s.name, s.srv.Addr, err)
+		}
+		listeners = append(listeners, ln)
+	}
+
+	failed := make(chan error, len(rt.servers))
+	for i, s := range rt.servers {
+		go func(s namedServer, ln net.Listener) {
+			log.Println("[" + s.name + "] : serving on " + ln.Addr().String())
+			if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				failed <- fmt.Errorf("[%s] : %w", s.name, err)
+			}
+		}(s, listeners[i])
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case sig := <-signals:
+		log.Println("[Runtime] : received", sig)
+	case err = <-failed:
+		log.Println(err)
+	}
+
+	if e := rt.shutdown(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// shutdown drains the servers, then runs the closers.
+func (rt *Runtime) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), rt.Timeout)
+	defer cancel()
+
+	var first error
+	record := func(name string, err error) {
+		if err == nil {
+			return
+		}
+		log.Println("[Runtime] : shutdown", name, err)
+		if first == nil {
+			first = err
+		}
+	}
+
+	for _, s := range rt.servers {
+		record(s.name, s.srv.Shutdown(ctx))
+	}
+
+	for _, c := range rt.closers {
+		record(c.name, c.fn(ctx))
+	}
+
+	return first
+}
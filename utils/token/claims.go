@@ -0,0 +1,93 @@
+package token
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ClaimsKey is where the typed claims are kept in the context.
+	ClaimsKey = "JWT_CLAIMS"
+
+	// the claims granted to the identity.
+	rolesKey   = "roles"
+	tenantKey  = "tenant"
+	versionKey = "pv"
+)
+
+// Claims are the typed claims of the token, or the API key, of a request.
+type Claims struct {
+	AdminID uint32
+	// Roles are the IDs of the roles of the admin when the token is issued.
+	Roles []uint32
+	// Tenant is the organization of the admin, it's empty if there's none.
+	Tenant string
+	// PermissionsVersion is the version of the permissions when the roles are
+	// granted, it's 0 if they aren't.
+	PermissionsVersion uint64
+}
+
+// Grant sets the roles, tenant and permissions version of c to claims
+// before signing, the identity is already there.
+func (c *Claims) Grant(claims jwt.MapClaims) {
+	roles := make([]uint32, len(c.Roles))
+	copy(roles, c.Roles)
+
+	claims[rolesKey] = roles
+	claims[tenantKey] = c.Tenant
+	claims[versionKey] = c.PermissionsVersion
+}
+
+// typed returns the typed claims, the identity should be a number. The
+// version is 0 if the roles are missing or malformed.
+func (mw *Middleware) typed(claims jwt.MapClaims) (*Claims, error) {
+	c := &Claims{}
+
+	switch id := claims[mw.IdentityKey].(type) {
+	case float64:
+		c.AdminID = uint32(id)
+	case uint32:
+		// the claims returned by the API key aren't encoded.
+		c.AdminID = id
+	default:
+		return nil, errInvalidToken
+	}
+
+	c.Tenant, _ = claims[tenantKey].(string)
+	switch v := claims[versionKey].(type) {
+	case float64:
+		c.PermissionsVersion = uint64(v)
+	case uint64:
+		c.PermissionsVersion = v
+	}
+
+	switch roles := claims[rolesKey].(type) {
+	case []interface{}:
+		for _, r := range roles {
+			id, ok := r.(float64)
+			if !ok {
+				// the roles are not trusted without the version.
+				c.Roles, c.PermissionsVersion = nil, 0
+				break
+			}
+			c.Roles = append(c.Roles, uint32(id))
+		}
+	case []uint32:
+		c.Roles = roles
+	default:
+		c.PermissionsVersion = 0
+	}
+
+	return c, nil
+}
+
+// ClaimsOf returns the typed claims of the request checked by the middleware.
+func ClaimsOf(c *gin.Context) (*Claims, bool) {
+	v, ok := c.Get(ClaimsKey)
+	if !ok {
+		return nil, false
+	}
+
+	claims, ok := v.(*Claims)
+	return claims, ok
+}
@@ -0,0 +1,98 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/abserari/shower/utils/config"
+)
+
+var errKeyType = func(kty string) error {
+	return fmt.Errorf("token: key type %s is not supported", kty)
+}
+
+// ParseJWK parses a public key of RS256 or ES256 in JWK, the algorithm is
+// told by the key type if alg is empty.
+func ParseJWK(jwk JWK) (*Key, error) {
+	k := &Key{ID: jwk.Kid, Algorithm: jwk.Alg}
+
+	switch jwk.Kty {
+	case "RSA":
+		if k.Algorithm == "" {
+			k.Algorithm = config.RS256
+		}
+		if k.Algorithm != config.RS256 {
+			return nil, errAlgorithm(k.Algorithm)
+		}
+
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		k.verify = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		if k.Algorithm == "" {
+			k.Algorithm = config.ES256
+		}
+		if k.Algorithm != config.ES256 || jwk.Crv != elliptic.P256().Params().Name {
+			return nil, errNotP256
+		}
+
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("token: the point is not on P-256")
+		}
+		k.verify = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	default:
+		return nil, errKeyType(jwk.Kty)
+	}
+
+	if k.ID == "" {
+		k.ID = k.thumbprint()
+	}
+	return k, nil
+}
+
+// NewVerifyingKeySet creates a KeySet verifying by keys without signing,
+// like the keys of another issuer. A token without kid is verified by the
+// only key.
+func NewVerifyingKeySet(keys ...*Key) (*KeySet, error) {
+	s := &KeySet{}
+	for _, k := range keys {
+		if _, ok := methods[k.Algorithm]; !ok {
+			return nil, errAlgorithm(k.Algorithm)
+		}
+		for _, added := range s.keys {
+			if added.ID == k.ID {
+				return nil, errDuplicateKey(k.ID)
+			}
+		}
+		s.keys = append(s.keys, k)
+	}
+
+	return s, nil
+}
+
+func decode(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,315 @@
+// Package token signs and verifies the JWT of the admins, by a HMAC secret
+// or a RSA or ECDSA key pair. Several keys verify the token by kid, so the
+// signing key could be rotated without invalidating the tokens signed before.
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/abserari/shower/utils/config"
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	errNoSigningKey = errors.New("token: no signing key")
+	errNotP256      = errors.New("token: ES256 needs a P-256 key")
+	errAlgorithm    = func(alg string) error {
+		return fmt.Errorf("token: algorithm %s is not supported", alg)
+	}
+	errDuplicateKey = func(id string) error {
+		return fmt.Errorf("token: kid %s is used twice", id)
+	}
+)
+
+// methods are the supported algorithms.
+var methods = map[string]jwt.SigningMethod{
+	config.HS256: jwt.SigningMethodHS256,
+	config.RS256: jwt.SigningMethodRS256,
+	config.ES256: jwt.SigningMethodES256,
+}
+
+// Key signs or verifies the token of an algorithm.
+type Key struct {
+	ID        string
+	Algorithm string
+
+	// sign is nil if the key only verifies.
+	sign   interface{}
+	verify interface{}
+}
+
+// NewHMACKey creates a HS256 key of secret.
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{
+		ID:        id,
+		Algorithm: config.HS256,
+		sign:      secret,
+		verify:    secret,
+	}
+}
+
+// ParsePrivateKey parses a PEM private key of RS256 or ES256, the ID is
+// the thumbprint of the public key if it's empty.
+func ParsePrivateKey(id, alg string, data []byte) (*Key, error) {
+	k := &Key{ID: id, Algorithm: alg}
+
+	switch alg {
+	case config.RS256:
+		priv, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		k.sign, k.verify = priv, &priv.PublicKey
+	case config.ES256:
+		priv, err := jwt.ParseECPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		if priv.Curve != elliptic.P256() {
+			return nil, errNotP256
+		}
+		k.sign, k.verify = priv, &priv.PublicKey
+	default:
+		return nil, errAlgorithm(alg)
+	}
+
+	if k.ID == "" {
+		k.ID = k.thumbprint()
+	}
+	return k, nil
+}
+
+// ParsePublicKey parses a PEM public key or certificate of RS256 or ES256,
+// the ID is the thumbprint of the key if it's empty.
+func ParsePublicKey(id, alg string, data []byte) (*Key, error) {
+	k := &Key{ID: id, Algorithm: alg}
+
+	switch alg {
+	case config.RS256:
+		pub, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		k.verify = pub
+	case config.ES256:
+		pub, err := jwt.ParseECPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		if pub.Curve != elliptic.P256() {
+			return nil, errNotP256
+		}
+		k.verify = pub
+	default:
+		return nil, errAlgorithm(alg)
+	}
+
+	if k.ID == "" {
+		k.ID = k.thumbprint()
+	}
+	return k, nil
+}
+
+// JWK returns the public key in JWK, false if it's a secret.
+func (k *Key) JWK() (JWK, bool) {
+	jwk := JWK{Use: "sig", Alg: k.Algorithm, Kid: k.ID}
+
+	switch pub := k.verify.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encode(pub.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = encode(pad(pub.X.Bytes(), size))
+		jwk.Y = encode(pad(pub.Y.Bytes(), size))
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// thumbprint is the JWK thumbprint of RFC 7638.
+func (k *Key) thumbprint() string {
+	jwk, ok := k.JWK()
+	if !ok {
+		return ""
+	}
+
+	// the required members in lexicographic order.
+	var members interface{}
+	if jwk.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return encode(sum[:])
+}
+
+// KeySet signs by one key and verifies by any key.
+type KeySet struct {
+	signing *Key
+	keys    []*Key
+}
+
+// NewKeySet creates a KeySet signing by signing, which verifies too.
+func NewKeySet(signing *Key, verify ...*Key) (*KeySet, error) {
+	if signing == nil || signing.sign == nil {
+		return nil, errNoSigningKey
+	}
+
+	s := &KeySet{signing: signing}
+	for _, k := range append([]*Key{signing}, verify...) {
+		if _, ok := methods[k.Algorithm]; !ok {
+			return nil, errAlgorithm(k.Algorithm)
+		}
+		for _, added := range s.keys {
+			if added.ID == k.ID {
+				return nil, errDuplicateKey(k.ID)
+			}
+		}
+		s.keys = append(s.keys, k)
+	}
+
+	return s, nil
+}
+
+// Load reads the keys in conf.
+func Load(conf config.JWT) (*KeySet, error) {
+	var signing *Key
+
+	if conf.Algorithm == config.HS256 {
+		signing = NewHMACKey(conf.KeyID, []byte(conf.Key))
+	} else {
+		data, err := ioutil.ReadFile(conf.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if signing, err = ParsePrivateKey(conf.KeyID, conf.Algorithm, data); err != nil {
+			return nil, err
+		}
+	}
+
+	verify := make([]*Key, len(conf.VerifyKeys))
+	for i, vk := range conf.VerifyKeys {
+		data, err := ioutil.ReadFile(vk.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if verify[i], err = ParsePublicKey(vk.KeyID, vk.Algorithm, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewKeySet(signing, verify...)
+}
+
+// Sign signs claims by the signing key, the kid header is set unless it's empty.
+func (s *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	if s.signing == nil {
+		return "", errNoSigningKey
+	}
+
+	t := jwt.NewWithClaims(methods[s.signing.Algorithm], claims)
+	if s.signing.ID != "" {
+		t.Header["kid"] = s.signing.ID
+	}
+	return t.SignedString(s.signing.sign)
+}
+
+// Parse parses and verifies token by the key of its kid. A token without
+// kid is verified by the signing key, or the only key of a verifying KeySet.
+func (s *KeySet) Parse(token string) (*jwt.Token, error) {
+	return jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		k := s.key(kid)
+		if k == nil {
+			return nil, errUnknownKey
+		}
+		if t.Method != methods[k.Algorithm] {
+			return nil, errInvalidAlgorithm
+		}
+		return k.verify, nil
+	})
+}
+
+func (s *KeySet) key(id string) *Key {
+	if id == "" && s.signing == nil && len(s.keys) == 1 {
+		return s.keys[0]
+	}
+	if id == "" {
+		return s.signing
+	}
+
+	for _, k := range s.keys {
+		if k.ID == id {
+			return k
+		}
+	}
+	return nil
+}
+
+// JWKS returns the public keys, the secret is never included.
+func (s *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range s.keys {
+		if jwk, ok := k.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// JWKSet is the JSON Web Key Set of RFC 7517.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is a public key of RSA or EC.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func pad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
@@ -0,0 +1,309 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/abserari/shower/utils/config"
+	"github.com/dgrijalva/jwt-go"
+)
+
+func rsaPEM(t *testing.T) (private, public []byte) {
+	t.Helper()
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})
+}
+
+func ecPEM(t *testing.T, curve elliptic.Curve) (private, public []byte) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})
+}
+
+func privateKey(t *testing.T, id, alg string, data []byte) *Key {
+	t.Helper()
+
+	k, err := ParsePrivateKey(id, alg, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func publicKey(t *testing.T, id, alg string, data []byte) *Key {
+	t.Helper()
+
+	k, err := ParsePublicKey(id, alg, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"userID": 1, "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+// innerOf returns the error returned by the key func of jwt.Parse.
+func innerOf(err error) error {
+	if ve, ok := err.(*jwt.ValidationError); ok && ve.Inner != nil {
+		return ve.Inner
+	}
+	return err
+}
+
+func TestSignKid(t *testing.T) {
+	priv, _ := ecPEM(t, elliptic.P256())
+	k := privateKey(t, "current", config.ES256, priv)
+
+	s, err := NewKeySet(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := s.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := s.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := parsed.Header["kid"]; kid != "current" {
+		t.Errorf("kid %v, want current", kid)
+	}
+	if parsed.Method != jwt.SigningMethodES256 {
+		t.Errorf("method %v, want ES256", parsed.Method.Alg())
+	}
+}
+
+func TestSignWithoutKid(t *testing.T) {
+	s, err := NewKeySet(NewHMACKey("", []byte("secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := s.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := s.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := parsed.Header["kid"]; ok {
+		t.Errorf("kid %v, want none", parsed.Header["kid"])
+	}
+}
+
+func TestRotation(t *testing.T) {
+	oldPriv, oldPub := rsaPEM(t)
+	newPriv, _ := ecPEM(t, elliptic.P256())
+
+	before, err := NewKeySet(privateKey(t, "old", config.RS256, oldPriv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := before.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := NewKeySet(
+		privateKey(t, "new", config.ES256, newPriv),
+		publicKey(t, "old", config.RS256, oldPub),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = after.Parse(signed); err != nil {
+		t.Fatalf("the token of the old key is rejected: %v", err)
+	}
+
+	signed, err = after.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := after.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := parsed.Header["kid"]; kid != "new" {
+		t.Errorf("kid %v, want new", kid)
+	}
+
+	// the old server doesn't know the new key.
+	if _, err = before.Parse(signed); innerOf(err) != errUnknownKey {
+		t.Errorf("error %v, want %v", err, errUnknownKey)
+	}
+}
+
+func TestDuplicateKid(t *testing.T) {
+	_, pub := rsaPEM(t)
+
+	_, err := NewKeySet(NewHMACKey("same", []byte("secret")), publicKey(t, "same", config.RS256, pub))
+	if err == nil {
+		t.Fatal("a kid used twice is accepted")
+	}
+}
+
+func TestRejectKid(t *testing.T) {
+	s, err := NewKeySet(NewHMACKey("current", []byte("secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	tok.Header["kid"] = "unknown"
+	signed, err := tok.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = s.Parse(signed); innerOf(err) != errUnknownKey {
+		t.Errorf("error %v, want %v", err, errUnknownKey)
+	}
+}
+
+func TestRejectAlgorithm(t *testing.T) {
+	priv, pub := rsaPEM(t)
+	k := privateKey(t, "rsa", config.RS256, priv)
+
+	s, err := NewKeySet(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the public key is known to anyone, it mustn't be taken as a secret.
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	tok.Header["kid"] = "rsa"
+	signed, err := tok.SignedString(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Parse(signed); innerOf(err) != errInvalidAlgorithm {
+		t.Errorf("error %v, want %v", err, errInvalidAlgorithm)
+	}
+
+	tok = jwt.NewWithClaims(jwt.SigningMethodNone, claims())
+	tok.Header["kid"] = "rsa"
+	signed, err = tok.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Parse(signed); innerOf(err) != errInvalidAlgorithm {
+		t.Errorf("error %v, want %v", err, errInvalidAlgorithm)
+	}
+}
+
+func TestUnsupportedKeys(t *testing.T) {
+	rsaPriv, rsaPub := rsaPEM(t)
+	p384, _ := ecPEM(t, elliptic.P384())
+
+	if _, err := ParsePrivateKey("", "PS256", rsaPriv); err == nil {
+		t.Error("PS256 is accepted")
+	}
+	if _, err := ParsePrivateKey("", config.ES256, p384); err != errNotP256 {
+		t.Errorf("error %v, want %v", err, errNotP256)
+	}
+	if _, err := NewKeySet(&Key{ID: "none", Algorithm: "none", sign: []byte("x")}); err == nil {
+		t.Error("the algorithm none is accepted")
+	}
+	if _, err := NewKeySet(publicKey(t, "", config.RS256, rsaPub)); err != errNoSigningKey {
+		t.Errorf("error %v, want %v", err, errNoSigningKey)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	rsaPriv, _ := rsaPEM(t)
+	ecPriv, _ := ecPEM(t, elliptic.P256())
+
+	rsaKey := privateKey(t, "", config.RS256, rsaPriv)
+	ecKey := privateKey(t, "ec", config.ES256, ecPriv)
+
+	s, err := NewKeySet(NewHMACKey("secret", []byte("secret")), rsaKey, ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := s.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("%d keys, want the 2 public keys: %+v", len(set.Keys), set.Keys)
+	}
+
+	rsaJWK, ecJWK := set.Keys[0], set.Keys[1]
+	if rsaJWK.Kty != "RSA" || rsaJWK.Alg != config.RS256 || rsaJWK.Use != "sig" || rsaJWK.Kid != rsaKey.ID {
+		t.Errorf("RSA JWK %+v", rsaJWK)
+	}
+	if ecJWK.Kty != "EC" || ecJWK.Crv != "P-256" || ecJWK.Kid != "ec" || len(ecJWK.X) != 43 || len(ecJWK.Y) != 43 {
+		t.Errorf("EC JWK %+v", ecJWK)
+	}
+
+	// the kid of a key without ID is the thumbprint, it's the same after a round trip.
+	parsed, err := ParseJWK(JWK{Kty: rsaJWK.Kty, N: rsaJWK.N, E: rsaJWK.E})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.ID != rsaKey.ID {
+		t.Errorf("thumbprint %s, want %s", parsed.ID, rsaKey.ID)
+	}
+
+	// a token signed by the server is verified by its JWKS.
+	signing, err := NewKeySet(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := signing.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make([]*Key, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		k, err := ParseJWK(jwk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, k)
+	}
+	verifying, err := NewVerifyingKeySet(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = verifying.Parse(signed); err != nil {
+		t.Errorf("the token isn't verified by the JWKS: %v", err)
+	}
+}
@@ -0,0 +1,347 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/abserari/shower/utils/errs"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+// PayloadKey is where the claims are kept in the context.
+const PayloadKey = "JWT_PAYLOAD"
+
+var (
+	errEmptyToken       = errs.Unauthorized("token is empty")
+	errInvalidToken     = errs.Unauthorized("token is invalid")
+	errExpiredToken     = errs.Unauthorized("token is expired")
+	errMaxRefresh       = errs.Unauthorized("token is too old to refresh")
+	errUnknownKey       = errs.Unauthorized("token is signed by an unknown key")
+	errInvalidAlgorithm = errs.Unauthorized("invalid signing algorithm")
+	errAuthentication   = errs.Unauthorized("incorrect username or password")
+)
+
+// Middleware issues the token on login and checks it on every request,
+// it answers the same as gin-jwt does. The errors are added to the context
+// for errs.Handler.
+type Middleware struct {
+	Realm      string
+	Keys       *KeySet
+	Timeout    time.Duration
+	MaxRefresh time.Duration
+	// IdentityKey is the claim of the identity, which is set in the context too.
+	IdentityKey string
+	// Authenticator returns the identity of the login request.
+	Authenticator func(c *gin.Context) (interface{}, error)
+	// Validate rejects a verified token, like a revoked one. It's optional.
+	Validate func(claims jwt.MapClaims) error
+	// Logout revokes the token of the logout request. It's optional.
+	Logout func(claims jwt.MapClaims) error
+	// Challenge answers instead of the token if the identity has to pass
+	// another factor, it reports whether it answered. It's optional.
+	Challenge func(c *gin.Context, identity interface{}) (bool, error)
+	// Issue is called with the claims of the token answered by the login or
+	// refresh before signing, like recording the session. It's optional.
+	Issue func(c *gin.Context, claims jwt.MapClaims) error
+	// APIKey returns the claims of the key in the APIKeyHeader, which
+	// authenticates the request instead of the token. It's optional.
+	APIKey       func(c *gin.Context, key string) (jwt.MapClaims, error)
+	APIKeyHeader string
+	TimeFunc     func() time.Time
+}
+
+// MiddlewareFunc rejects the request without a valid token, or API key if
+// the request has one. The typed claims are read by ClaimsOf then.
+func (mw *Middleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			claims jwt.MapClaims
+			err    error
+		)
+		if key := mw.apiKey(c); key != "" {
+			claims, err = mw.APIKey(c, key)
+		} else {
+			claims, err = mw.claims(c, false)
+		}
+		if err != nil {
+			mw.unauthorized(c, err)
+			return
+		}
+
+		typed, err := mw.typed(claims)
+		if err != nil {
+			mw.unauthorized(c, err)
+			return
+		}
+
+		c.Set(PayloadKey, claims)
+		c.Set(ClaimsKey, typed)
+		c.Set(mw.IdentityKey, claims[mw.IdentityKey])
+	}
+}
+
+// LoginHandler answers the token of the identity returned by Authenticator.
+func (mw *Middleware) LoginHandler(c *gin.Context) {
+	mw.LoginBy(mw.Authenticator)(c)
+}
+
+// LoginBy is LoginHandler authenticating by authenticator, for another way to
+// login answering the same token.
+func (mw *Middleware) LoginBy(authenticator func(c *gin.Context) (interface{}, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, err := authenticator(c)
+		if err != nil {
+			var e *errs.Error
+			if !errors.As(err, &e) {
+				e = errs.Wrap(errs.KindUnauthorized, errAuthentication.Message, err)
+			}
+			mw.unauthorized(c, e)
+			return
+		}
+
+		if mw.Challenge != nil {
+			challenged, err := mw.Challenge(c, identity)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			if challenged {
+				return
+			}
+		}
+
+		claims, expire, err := mw.NewClaims(identity, mw.Timeout)
+		if err != nil {
+			c.Error(errs.Internal(err))
+			return
+		}
+
+		if mw.Issue != nil {
+			if err = mw.Issue(c, claims); err != nil {
+				c.Error(err)
+				return
+			}
+		}
+
+		token, err := mw.Keys.Sign(claims)
+		if err != nil {
+			c.Error(errs.Internal(err))
+			return
+		}
+
+		LoginResponse(c, token, expire)
+	}
+}
+
+// RefreshHandler answers a new token if the token was issued within MaxRefresh,
+// even if it's expired.
+func (mw *Middleware) RefreshHandler(c *gin.Context) {
+	claims, err := mw.claims(c, true)
+	if err != nil {
+		mw.unauthorized(c, err)
+		return
+	}
+
+	origIat, ok := claims["orig_iat"].(float64)
+	if !ok || int64(origIat) < mw.now().Add(-mw.MaxRefresh).Unix() {
+		mw.unauthorized(c, errMaxRefresh)
+		return
+	}
+
+	expire := mw.now().Add(mw.Timeout)
+	refreshed := jwt.MapClaims{}
+	for k, v := range claims {
+		refreshed[k] = v
+	}
+	refreshed["exp"] = expire.Unix()
+	if refreshed["jti"], err = newID(); err != nil {
+		c.Error(errs.Internal(err))
+		return
+	}
+
+	if mw.Issue != nil {
+		if err = mw.Issue(c, refreshed); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	token, err := mw.Keys.Sign(refreshed)
+	if err != nil {
+		c.Error(errs.Internal(err))
+		return
+	}
+
+	LoginResponse(c, token, expire)
+}
+
+// LogoutHandler revokes the token of the request by Logout.
+func (mw *Middleware) LogoutHandler(c *gin.Context) {
+	claims, err := mw.claims(c, false)
+	if err != nil {
+		mw.unauthorized(c, err)
+		return
+	}
+
+	if mw.Logout != nil {
+		if err = mw.Logout(claims); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK})
+}
+
+// TokenGenerator signs a token of identity, every token has an unique jti.
+// orig_iat is when the identity logged in, it's kept by refresh.
+func (mw *Middleware) TokenGenerator(identity interface{}) (string, time.Time, error) {
+	claims, expire, err := mw.NewClaims(identity, mw.Timeout)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	token, err := mw.Keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expire, nil
+}
+
+// NewClaims returns the claims of a token of identity expiring after timeout,
+// more claims could be added before signing by Keys.
+func (mw *Middleware) NewClaims(identity interface{}, timeout time.Duration) (jwt.MapClaims, time.Time, error) {
+	now := mw.now()
+	expire := now.Add(timeout)
+
+	jti, err := newID()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return jwt.MapClaims{
+		mw.IdentityKey: identity,
+		"jti":          jti,
+		"exp":          expire.Unix(),
+		// in milliseconds, to tell the tokens issued in the same second apart.
+		"orig_iat": float64(now.UnixNano()/int64(time.Millisecond)) / 1000,
+	}, expire, nil
+}
+
+// LoginResponse answers token like gin-jwt does.
+func LoginResponse(c *gin.Context, token string, expire time.Time) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":   http.StatusOK,
+		"token":  token,
+		"expire": expire.Format(time.RFC3339),
+	})
+}
+
+// ExtractClaims returns the claims of the request checked by the middleware.
+func ExtractClaims(c *gin.Context) jwt.MapClaims {
+	claims, ok := c.Get(PayloadKey)
+	if !ok {
+		return jwt.MapClaims{}
+	}
+	return claims.(jwt.MapClaims)
+}
+
+// claims verifies the token of the request, an expired one is accepted if expired is true.
+func (mw *Middleware) claims(c *gin.Context, expired bool) (jwt.MapClaims, error) {
+	raw := lookup(c)
+	if raw == "" {
+		return nil, errEmptyToken
+	}
+
+	token, err := mw.Keys.Parse(raw)
+	if err != nil {
+		ve, ok := err.(*jwt.ValidationError)
+		if !ok {
+			return nil, errInvalidToken
+		}
+
+		// the errors of the key function.
+		if e, ok := ve.Inner.(*errs.Error); ok {
+			return nil, e
+		}
+		if ve.Errors != jwt.ValidationErrorExpired {
+			return nil, errInvalidToken
+		}
+		if !expired {
+			return nil, errExpiredToken
+		}
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errInvalidToken
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errInvalidToken
+	}
+	if !expired && int64(exp) < mw.now().Unix() {
+		return nil, errExpiredToken
+	}
+
+	if mw.Validate != nil {
+		if err = mw.Validate(claims); err != nil {
+			return nil, err
+		}
+	}
+
+	return claims, nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// lookup reads the token in the Authorization header, the token query or the JWT cookie.
+func lookup(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		parts := strings.SplitN(auth, " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			return parts[1]
+		}
+		return ""
+	}
+
+	if token := c.Query("token"); token != "" {
+		return token
+	}
+
+	token, _ := c.Cookie("JWT")
+	return token
+}
+
+// apiKey reads the key in the APIKeyHeader, it's empty if APIKey is not set.
+func (mw *Middleware) apiKey(c *gin.Context) string {
+	if mw.APIKey == nil || mw.APIKeyHeader == "" {
+		return ""
+	}
+	return c.GetHeader(mw.APIKeyHeader)
+}
+
+func (mw *Middleware) unauthorized(c *gin.Context, err error) {
+	c.Header("WWW-Authenticate", "JWT realm="+mw.Realm)
+	c.Error(err)
+	c.Abort()
+}
+
+func (mw *Middleware) now() time.Time {
+	if mw.TimeFunc == nil {
+		return time.Now()
+	}
+	return mw.TimeFunc()
+}
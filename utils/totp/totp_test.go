@@ -0,0 +1,106 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of RFC 6238 Appendix B, "12345678901234567890" in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The codes of RFC 6238 Appendix B are of 8 digits, a code of 6 digits is
+// the last 6 of them.
+var vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "94287082"},
+	{1111111109, "07081804"},
+	{1111111111, "14050471"},
+	{1234567890, "89005924"},
+	{2000000000, "69279037"},
+	{20000000000, "65353130"},
+}
+
+func TestCode(t *testing.T) {
+	for _, v := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := v.code[len(v.code)-Digits:]; code != want {
+			t.Errorf("code at %d: %s, want %s", v.unix, code, want)
+		}
+	}
+}
+
+func TestCodeSecret(t *testing.T) {
+	lower, err := Code(strings.ToLower(rfcSecret), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upper, err := Code(rfcSecret, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lower != upper {
+		t.Errorf("code of the lower case secret %s, want %s", lower, upper)
+	}
+
+	if _, err = Code("not base32!", 1); err == nil {
+		t.Error("the invalid secret is accepted")
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+
+	for offset := int64(-Skew - 1); offset <= Skew+1; offset++ {
+		code, err := Code(rfcSecret, step+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, ok := Validate(rfcSecret, code, now)
+		accepted := offset >= -Skew && offset <= Skew
+		if ok != accepted {
+			t.Errorf("the code of step %+d: accepted %t, want %t", offset, ok, accepted)
+		}
+		if ok && got != step+offset {
+			t.Errorf("the code of step %+d: step %d, want %d", offset, got, step+offset)
+		}
+	}
+}
+
+func TestValidateMalformed(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	for _, code := range []string{"", "28708", "2870820", "94287082", "abcdef"} {
+		if _, ok := Validate(rfcSecret, code, now); ok {
+			t.Errorf("code %q is accepted", code)
+		}
+	}
+	if _, ok := Validate("not base32!", "287082", now); ok {
+		t.Error("the code of an invalid secret is accepted")
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a == b {
+		t.Error("the secrets are the same")
+	}
+	if key, err := encoding.DecodeString(a); err != nil || len(key) != secretSize {
+		t.Errorf("secret %s of %d bytes: %v", a, len(key), err)
+	}
+}